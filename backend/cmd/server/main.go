@@ -1,58 +1,496 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
 	"github.com/robfig/cron/v3"
 	"log"
+	"log/slog"
+	nethttp "net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	_ "github.com/lib/pq"
 	"github.com/swaggo/files"
 	"github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	_ "modernc.org/sqlite"
 	_ "todo/docs"
+	"todo/internal/config"
 	"todo/internal/delivery/http"
 	"todo/internal/delivery/http/middleware"
+	"todo/internal/domain/model"
+	domainrepo "todo/internal/domain/repository"
+	domainusecase "todo/internal/domain/usecase"
+	"todo/internal/logging"
+	"todo/internal/metrics"
+	"todo/internal/migrate"
 	"todo/internal/repository"
+	"todo/internal/tracing"
 	"todo/internal/usecase"
 )
 
 func main() {
-	dsn := "host=localhost user=bogdantarchenko dbname=todo sslmode=disable"
+	configPath := flag.String("config", os.Getenv("TODO_CONFIG_FILE"), "path to a YAML or TOML config file")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets masked and exit")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [migrate up|down|status]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
 
-	db, err := sql.Open("postgres", dsn)
+	cfg, err := config.Load(*configPath)
 	if err != nil {
-		log.Fatalf("failed to connect to db: %v", err)
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if *printConfig {
+		fmt.Print(cfg.Masked())
+		return
 	}
-	defer db.Close()
 
-	if err := db.Ping(); err != nil {
-		log.Fatalf("failed to ping db: %v", err)
+	logger := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+	// Route the standard log package, used by some libraries, through slog too.
+	slog.SetDefault(logger)
+
+	if flag.Arg(0) == "migrate" {
+		runMigrate(logger, cfg.DB, flag.Args()[1:])
+		return
+	}
+	if cfg.Log.Level != "debug" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		logger.Warn("opentelemetry error", slog.Any("error", err))
+	}))
+	tracerProvider, err := tracing.New(context.Background(), cfg.Tracing, buildCommit())
+	if err != nil {
+		fatal(logger, "failed to set up tracing", err)
+	}
+	if tracerProvider != nil {
+		otel.SetTracerProvider(tracerProvider)
+		otel.SetTextMapPropagator(tracing.Propagator())
+	}
+
+	var (
+		db              *sql.DB
+		migrator        *migrate.Migrator
+		taskRepo        domainrepo.TaskRepository
+		taskEventRepo   domainrepo.TaskEventRepository
+		unitOfWork      domainrepo.UnitOfWork
+		idempotencyRepo domainrepo.IdempotencyRepository
+		dbSystem        string
+	)
+	switch cfg.DB.Driver {
+	case "memory":
+		logger.Warn("using in-memory task storage, data is lost on restart")
+		memoryRepo := repository.NewTaskMemoryRepository()
+		memoryEventRepo := repository.NewTaskEventMemoryRepository()
+		taskRepo = memoryRepo
+		taskEventRepo = memoryEventRepo
+		unitOfWork = repository.NewMemoryUnitOfWork(memoryRepo, memoryEventRepo)
+		idempotencyRepo = repository.NewIdempotencyMemoryRepository()
+	case "sqlite":
+		db = openDB(logger, cfg.DB)
+		migrator = prepareSchema(logger, db, cfg.DB)
+		taskRepo = repository.NewTaskSQLiteRepository(db)
+		taskEventRepo = repository.NewTaskEventSQLiteRepository(db)
+		unitOfWork = repository.NewSQLiteUnitOfWork(db)
+		idempotencyRepo = repository.NewIdempotencySQLiteRepository(db)
+		dbSystem = "sqlite"
+	default:
+		db = openDB(logger, cfg.DB)
+		migrator = prepareSchema(logger, db, cfg.DB)
+		taskRepo = repository.NewTaskPgRepository(db)
+		taskEventRepo = repository.NewTaskEventPgRepository(db)
+		unitOfWork = repository.NewPgUnitOfWork(db, repository.TxOptions{
+			Isolation:  repository.IsolationLevels[cfg.DB.IsolationLevel],
+			MaxRetries: cfg.DB.TxMaxRetries,
+		})
+		idempotencyRepo = repository.NewIdempotencyPgRepository(db)
+		dbSystem = "postgresql"
+	}
+	if tracerProvider != nil {
+		taskRepo = repository.NewTracedTaskRepository(taskRepo, tracerProvider, dbSystem)
+		taskEventRepo = repository.NewTracedTaskEventRepository(taskEventRepo, tracerProvider, dbSystem)
+		unitOfWork = repository.NewTracedUnitOfWork(unitOfWork, tracerProvider, dbSystem)
+	}
+
+	var (
+		appMetrics    *metrics.Metrics
+		queryObserver repository.QueryObserver
+	)
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New(logger)
+		queryObserver = appMetrics
+	}
+	taskRepo = repository.NewInstrumentedTaskRepository(taskRepo, queryObserver, logger)
+	taskEventRepo = repository.NewInstrumentedTaskEventRepository(taskEventRepo, queryObserver, logger)
+	unitOfWork = repository.NewInstrumentedUnitOfWork(unitOfWork, queryObserver, logger)
+	if appMetrics != nil {
+		appMetrics.RegisterTaskCounts(taskRepo.CountByStatusAndPriority)
+		if db != nil {
+			appMetrics.RegisterDB(db, cfg.DB.Driver)
+		}
 	}
 
-	taskRepo := repository.NewTaskPgRepository(db)
-	taskUsecase := usecase.NewTaskUsecase(taskRepo)
-	taskHandler := http.NewTaskHandler(taskUsecase)
+	var taskUsecase domainusecase.TaskUsecase = usecase.NewTaskUsecase(taskRepo, taskEventRepo, unitOfWork, usecase.TaskSettings{
+		UndoWindow:      time.Duration(cfg.Undo.Window),
+		MaxSubtaskDepth: cfg.Subtasks.MaxDepth,
+		CompletionRule:  model.CompletionRule(cfg.Subtasks.CompletionRule),
+	}, logger)
+	if tracerProvider != nil {
+		taskUsecase = usecase.NewTracedTaskUsecase(taskUsecase, tracerProvider)
+	}
+	taskHandler := http.NewTaskHandler(taskUsecase, logger)
 
+	// jobsCtx is cancelled only when a job outlives the shutdown timeout.
+	jobsCtx, cancelJobs := context.WithCancel(context.Background())
+	defer cancelJobs()
+	overdueTimeout := time.Duration(cfg.Scheduler.OverdueTimeout)
 	c := cron.New()
-	_, err = c.AddFunc("@every 1m", func() {
-		log.Println("[CRON] Running UpdateOverdueTasks")
-		if err := taskUsecase.UpdateOverdueTasks(); err != nil {
-			log.Printf("[CRON] Failed to update overdue tasks: %v", err)
-		} else {
-			log.Println("[CRON] UpdateOverdueTasks completed successfully")
+	scheduler := &schedulerHeartbeat{}
+	jobLogger := logger.With(slog.String("job", "update_overdue_tasks"))
+	err = scheduler.add(c, "update_overdue_tasks", cfg.Scheduler.OverdueSpec, func() {
+		jobLogger.Debug("job started")
+		ctx, cancel := context.WithTimeout(jobsCtx, overdueTimeout)
+		defer cancel()
+		start := time.Now()
+		transitioned, err := taskUsecase.UpdateOverdueTasks(ctx)
+		if appMetrics != nil {
+			appMetrics.ObserveOverdueRun(transitioned, err)
+		}
+		if err != nil {
+			jobLogger.Error("job failed", slog.Int("transitioned", transitioned), slog.Any("error", err))
+			return
+		}
+		jobLogger.Info("job finished", slog.Int("transitioned", transitioned), slog.Duration("duration", time.Since(start)))
+	})
+	if err != nil {
+		fatal(logger, "failed to schedule cron job", err)
+	}
+	purgeLogger := logger.With(slog.String("job", "purge_idempotency_keys"))
+	err = scheduler.add(c, "purge_idempotency_keys", cfg.Scheduler.IdempotencyPurgeSpec, func() {
+		// A single indexed DELETE; the bound only guards against a stuck database.
+		ctx, cancel := context.WithTimeout(jobsCtx, time.Minute)
+		defer cancel()
+		deleted, err := idempotencyRepo.DeleteExpired(ctx, time.Now().UTC())
+		if err != nil {
+			purgeLogger.Error("job failed", slog.Any("error", err))
+			return
 		}
+		purgeLogger.Debug("job finished", slog.Int64("deleted", deleted))
 	})
 	if err != nil {
-		log.Fatalf("failed to schedule cron job: %v", err)
+		fatal(logger, "failed to schedule cron job", err)
+	}
+	trashLogger := logger.With(slog.String("job", "purge_deleted_tasks"))
+	trashRetention := time.Duration(cfg.Trash.Retention)
+	err = scheduler.add(c, "purge_deleted_tasks", cfg.Scheduler.TrashPurgeSpec, func() {
+		ctx, cancel := context.WithTimeout(jobsCtx, time.Minute)
+		defer cancel()
+		purged, err := taskUsecase.PurgeDeletedTasks(ctx, trashRetention)
+		if err != nil {
+			trashLogger.Error("job failed", slog.Any("error", err))
+			return
+		}
+		trashLogger.Debug("job finished", slog.Int64("purged", purged))
+	})
+	if err != nil {
+		fatal(logger, "failed to schedule cron job", err)
+	}
+	scheduler.start(c)
+
+	r := gin.New()
+	r.Use(middleware.RequestID())
+	if tracerProvider != nil {
+		r.Use(middleware.Tracing(tracing.ServiceName, tracerProvider, tracing.Propagator()))
+	}
+	r.Use(middleware.AccessLog(logger), middleware.Recovery(logger))
+	if appMetrics != nil {
+		r.Use(middleware.Metrics(appMetrics))
+	}
+	r.Use(middleware.RequestTimeout(time.Duration(cfg.HTTP.RequestTimeout)))
+	r.Use(middleware.ErrorHandler(logger))
+	taskHandler.RegisterRoutes(r, middleware.Idempotency(idempotencyRepo, time.Duration(cfg.Idempotency.TTL), time.Duration(cfg.HTTP.RequestTimeout), logger))
+	healthHandler := newHealthHandler(logger, db, migrator, scheduler)
+	healthHandler.RegisterRoutes(r)
+	if cfg.Swagger.Enabled {
+		r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	}
+	if appMetrics != nil {
+		r.GET("/metrics", gin.WrapH(appMetrics.Handler()))
+	}
+
+	srv := &nethttp.Server{
+		Addr:         cfg.HTTP.Addr,
+		Handler:      r,
+		ReadTimeout:  time.Duration(cfg.HTTP.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.HTTP.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.HTTP.IdleTimeout),
+		ErrorLog:     slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}
+
+	stopCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", slog.String("addr", cfg.HTTP.Addr))
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var runErr error
+	select {
+	case <-stopCtx.Done():
+		logger.Info("shutdown signal received, draining requests")
+	case runErr = <-serveErr:
+		logger.Error("server run error", slog.Any("error", runErr))
+	}
+	stop()
+
+	scheduler.running.Store(false)
+	shutdown(logger, srv, c, cancelJobs, db, tracerProvider, time.Duration(cfg.HTTP.ShutdownTimeout))
+	if runErr != nil {
+		os.Exit(1)
+	}
+}
+
+// shutdown stops accepting requests and waits for in-flight ones, then stops
+// the scheduler and waits for a running job before closing the database. A
+// job still running when the timeout expires has its context cancelled so it
+// can return before the connection pool goes away. Buffered spans are flushed
+// last, once nothing can start new ones.
+func shutdown(logger *slog.Logger, srv *nethttp.Server, c *cron.Cron, cancelJobs context.CancelFunc, db *sql.DB, tp *sdktrace.TracerProvider, timeout time.Duration) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := srv.Shutdown(ctx); err != nil {
+		logger.Error("failed to drain HTTP requests", slog.Any("error", err))
+	}
+
+	jobsDone := c.Stop().Done()
+	select {
+	case <-jobsDone:
+	case <-ctx.Done():
+		logger.Warn("scheduler job still running at shutdown timeout, cancelling it")
+		cancelJobs()
+		<-jobsDone
+	}
+
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logger.Error("failed to close db", slog.Any("error", err))
+		}
+	}
+	if tp != nil {
+		if err := tp.Shutdown(ctx); err != nil {
+			logger.Error("failed to flush traces", slog.Any("error", err))
+		}
+	}
+	logger.Info("shutdown complete")
+}
+
+func openDB(logger *slog.Logger, cfg config.DBConfig) *sql.DB {
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		fatal(logger, "failed to connect to db", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime))
+	if cfg.Driver == "sqlite" {
+		// SQLite allows a single writer; one connection avoids SQLITE_BUSY.
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.Ping(); err != nil {
+		fatal(logger, "failed to ping db", err)
+	}
+	return db
+}
+
+// prepareSchema applies pending migrations, or only verifies the schema when
+// auto-migration is off. Either way the server refuses to run against a schema
+// newer than the migrations embedded in this binary.
+func prepareSchema(logger *slog.Logger, db *sql.DB, cfg config.DBConfig) *migrate.Migrator {
+	migrator, err := migrate.New(db, cfg.Driver, logger)
+	if err != nil {
+		fatal(logger, "failed to load migrations", err)
+	}
+	ctx := context.Background()
+	if cfg.AutoMigrate {
+		if err := migrator.Up(ctx); err != nil {
+			fatal(logger, "failed to apply migrations", err)
+		}
+		return migrator
+	}
+	pending, err := migrator.Check(ctx)
+	if err != nil {
+		fatal(logger, "failed to check database schema", err)
+	}
+	if pending {
+		logger.Warn("database schema has pending migrations, run \"migrate up\" to apply them")
+	}
+	return migrator
+}
+
+// commit is the VCS revision of the build, set with
+// -ldflags "-X main.commit=$(git rev-parse --short HEAD)". When it is empty the
+// revision stamped by the go tool is used instead.
+var commit string
+
+// schedulerGrace is how late a cron job may start before the scheduler is
+// reported as not ready.
+const schedulerGrace = time.Minute
+
+// schedulerHeartbeat records when every cron job last started, so readiness
+// tells a stalled scheduler from one that is idle between runs.
+type schedulerHeartbeat struct {
+	running atomic.Bool
+	jobs    []*jobHeartbeat
+}
+
+type jobHeartbeat struct {
+	name     string
+	schedule cron.Schedule
+	// last is the start of the last run in Unix nanoseconds, or the start of
+	// the scheduler before the first run.
+	last atomic.Int64
+}
+
+// add schedules run on c under spec and records every start.
+func (h *schedulerHeartbeat) add(c *cron.Cron, name, spec string, run func()) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return err
+	}
+	job := &jobHeartbeat{name: name, schedule: schedule}
+	h.jobs = append(h.jobs, job)
+	c.Schedule(schedule, cron.FuncJob(func() {
+		job.last.Store(time.Now().UnixNano())
+		run()
+	}))
+	return nil
+}
+
+func (h *schedulerHeartbeat) start(c *cron.Cron) {
+	now := time.Now().UnixNano()
+	for _, job := range h.jobs {
+		job.last.Store(now)
 	}
 	c.Start()
+	h.running.Store(true)
+}
 
-	r := gin.Default()
-	r.Use(middleware.ErrorHandler())
-	taskHandler.RegisterRoutes(r)
-	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+// check fails when the scheduler is stopped or a job is more than
+// schedulerGrace past the run that should have followed its last one.
+func (h *schedulerHeartbeat) check(ctx context.Context) error {
+	if !h.running.Load() {
+		return errors.New("scheduler is not running")
+	}
+	now := time.Now()
+	for _, job := range h.jobs {
+		due := job.schedule.Next(time.Unix(0, job.last.Load()))
+		if now.After(due.Add(schedulerGrace)) {
+			return fmt.Errorf("job %s missed its run due at %s", job.name, due.UTC().Format(time.RFC3339))
+		}
+	}
+	return nil
+}
+
+// newHealthHandler wires the probes to the dependencies that exist for the
+// configured storage: the in-memory repository has no database or schema.
+func newHealthHandler(logger *slog.Logger, db *sql.DB, migrator *migrate.Migrator, scheduler *schedulerHeartbeat) *http.HealthHandler {
+	build := http.BuildInfo{Commit: buildCommit(), GoVersion: runtime.Version()}
+	checks := []http.ReadinessCheck{{Name: "scheduler", Check: scheduler.check}}
+
+	if db != nil {
+		checks = append(checks, http.ReadinessCheck{Name: "database", Check: db.PingContext})
+	}
+	if migrator != nil {
+		build.SchemaVersion = func(ctx context.Context) (int64, error) {
+			current, _, err := migrator.Versions(ctx)
+			return current, err
+		}
+		checks = append(checks, http.ReadinessCheck{
+			Name: "migrations",
+			Check: func(ctx context.Context) error {
+				pending, err := migrator.Check(ctx)
+				if err != nil {
+					return err
+				}
+				if pending {
+					return errors.New("database schema has pending migrations")
+				}
+				return nil
+			},
+		})
+	}
+	return http.NewHealthHandler(logger, build, checks...)
+}
 
-	if err := r.Run(":8080"); err != nil {
-		log.Fatalf("server run error: %v", err)
+func buildCommit() string {
+	if commit != "" {
+		return commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
+
+// runMigrate implements the "migrate up|down|status" subcommand.
+func runMigrate(logger *slog.Logger, cfg config.DBConfig, args []string) {
+	if len(args) != 1 {
+		fatal(logger, "usage: migrate up|down|status", nil)
+	}
+	if cfg.Driver == "memory" {
+		fatal(logger, "migrations need a postgres or sqlite database", nil)
+	}
+	db := openDB(logger, cfg)
+	defer db.Close()
+
+	migrator, err := migrate.New(db, cfg.Driver, logger)
+	if err != nil {
+		fatal(logger, "failed to load migrations", err)
+	}
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		err = migrator.Up(ctx)
+	case "down":
+		err = migrator.Down(ctx)
+	case "status":
+		err = migrator.Status(ctx, os.Stdout)
+	default:
+		fatal(logger, fmt.Sprintf("unknown migrate command %q, use up, down or status", args[0]), nil)
+	}
+	if err != nil {
+		fatal(logger, "migrate "+args[0]+" failed", err)
+	}
+}
+
+// fatal logs msg with err, when there is one, and exits with status 1.
+func fatal(logger *slog.Logger, msg string, err error) {
+	if err != nil {
+		logger.Error(msg, slog.Any("error", err))
+	} else {
+		logger.Error(msg)
 	}
+	os.Exit(1)
 }
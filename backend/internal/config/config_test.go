@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// TestLoad_Defaults checks that an empty environment yields a valid local setup
+func TestLoad_Defaults(t *testing.T) {
+	// Act
+	cfg, err := Load("")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", cfg.DB.Driver)
+	assert.Equal(t, defaultDSNs["postgres"], cfg.DB.DSN)
+	assert.Equal(t, ":8080", cfg.HTTP.Addr)
+	assert.Equal(t, Duration(30*time.Second), cfg.HTTP.ShutdownTimeout)
+	assert.Equal(t, "@every 1m", cfg.Scheduler.OverdueSpec)
+	assert.Equal(t, Duration(24*time.Hour), cfg.Idempotency.TTL)
+	assert.Equal(t, Duration(720*time.Hour), cfg.Trash.Retention)
+	assert.Equal(t, Duration(5*time.Minute), cfg.Undo.Window)
+	assert.Equal(t, 3, cfg.Subtasks.MaxDepth)
+	assert.Equal(t, "independent", cfg.Subtasks.CompletionRule)
+	assert.True(t, cfg.Swagger.Enabled)
+}
+
+// TestLoad_YAMLFile checks that a YAML file overrides defaults and keeps unset values
+func TestLoad_YAMLFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.yaml", `
+db:
+  driver: sqlite
+http:
+  addr: ":9090"
+  request_timeout: 3s
+swagger:
+  enabled: false
+`)
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.DB.Driver)
+	assert.Equal(t, defaultDSNs["sqlite"], cfg.DB.DSN)
+	assert.Equal(t, ":9090", cfg.HTTP.Addr)
+	assert.Equal(t, Duration(3*time.Second), cfg.HTTP.RequestTimeout)
+	assert.Equal(t, Duration(15*time.Second), cfg.HTTP.ReadTimeout)
+	assert.False(t, cfg.Swagger.Enabled)
+}
+
+// TestLoad_TOMLFile checks that TOML files are read as well
+func TestLoad_TOMLFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.toml", `
+[db]
+driver = "memory"
+max_open_conns = 3
+
+[scheduler]
+overdue_spec = "*/5 * * * *"
+overdue_timeout = "20s"
+`)
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "memory", cfg.DB.Driver)
+	assert.Equal(t, 3, cfg.DB.MaxOpenConns)
+	assert.Equal(t, "*/5 * * * *", cfg.Scheduler.OverdueSpec)
+	assert.Equal(t, Duration(20*time.Second), cfg.Scheduler.OverdueTimeout)
+}
+
+// TestLoad_EnvOverridesFile checks that environment variables win over the file
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.yaml", "http:\n  addr: \":9090\"\n")
+	t.Setenv("TODO_HTTP_ADDR", ":7070")
+	t.Setenv("TODO_DB_MAX_IDLE_CONNS", "7")
+	t.Setenv("TODO_DB_ISOLATION_LEVEL", "serializable")
+	t.Setenv("TODO_HTTP_WRITE_TIMEOUT", "1m")
+	t.Setenv("TODO_SWAGGER_ENABLED", "false")
+	t.Setenv("TODO_TRACING_SAMPLE_RATIO", "0.25")
+	t.Setenv("TODO_SUBTASKS_MAX_DEPTH", "5")
+	t.Setenv("TODO_SUBTASKS_COMPLETION_RULE", "require_subtasks")
+
+	// Act
+	cfg, err := Load(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":7070", cfg.HTTP.Addr)
+	assert.Equal(t, 7, cfg.DB.MaxIdleConns)
+	assert.Equal(t, "serializable", cfg.DB.IsolationLevel)
+	assert.Equal(t, Duration(time.Minute), cfg.HTTP.WriteTimeout)
+	assert.False(t, cfg.Swagger.Enabled)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+	assert.Equal(t, 5, cfg.Subtasks.MaxDepth)
+	assert.Equal(t, "require_subtasks", cfg.Subtasks.CompletionRule)
+}
+
+// TestLoad_InvalidEnvValue checks that malformed numbers name the variable
+func TestLoad_InvalidEnvValue(t *testing.T) {
+	// Arrange
+	t.Setenv("TODO_HTTP_READ_TIMEOUT", "soon")
+
+	// Act
+	_, err := Load("")
+
+	// Assert
+	assert.ErrorContains(t, err, "TODO_HTTP_READ_TIMEOUT")
+}
+
+// TestLoad_UnsupportedExtension checks that unknown file formats are rejected
+func TestLoad_UnsupportedExtension(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "config.json", "{}")
+
+	// Act
+	_, err := Load(path)
+
+	// Assert
+	assert.ErrorContains(t, err, "unsupported config file extension")
+}
+
+// TestValidate_ReportsAllErrors checks that every invalid setting is listed
+func TestValidate_ReportsAllErrors(t *testing.T) {
+	// Arrange
+	cfg := Default()
+	cfg.DB.Driver = "mysql"
+	cfg.DB.IsolationLevel = "snapshot"
+	cfg.DB.TxMaxRetries = -1
+	cfg.HTTP.Addr = ""
+	cfg.HTTP.IdleTimeout = 0
+	cfg.Scheduler.OverdueSpec = "every minute"
+	cfg.Scheduler.IdempotencyPurgeSpec = ""
+	cfg.Scheduler.TrashPurgeSpec = "hourly"
+	cfg.Idempotency.TTL = 0
+	cfg.Trash.Retention = 0
+	cfg.Undo.Window = Duration(-time.Second)
+	cfg.Log.Level = "verbose"
+	cfg.Log.Format = "xml"
+	cfg.Tracing.Exporter = "jaeger"
+	cfg.Tracing.SampleRatio = 2
+	cfg.Subtasks.MaxDepth = 0
+	cfg.Subtasks.CompletionRule = "strict"
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "db.driver")
+	assert.ErrorContains(t, err, "db.isolation_level")
+	assert.ErrorContains(t, err, "db.tx_max_retries")
+	assert.ErrorContains(t, err, "http.addr")
+	assert.ErrorContains(t, err, "http.idle_timeout")
+	assert.ErrorContains(t, err, "scheduler.overdue_spec")
+	assert.ErrorContains(t, err, "scheduler.idempotency_purge_spec")
+	assert.ErrorContains(t, err, "scheduler.trash_purge_spec")
+	assert.ErrorContains(t, err, "idempotency.ttl")
+	assert.ErrorContains(t, err, "trash.retention")
+	assert.ErrorContains(t, err, "undo.window")
+	assert.ErrorContains(t, err, "log.level")
+	assert.ErrorContains(t, err, "log.format")
+	assert.ErrorContains(t, err, "tracing.exporter")
+	assert.ErrorContains(t, err, "tracing.sample_ratio")
+	assert.ErrorContains(t, err, "subtasks.max_depth")
+	assert.ErrorContains(t, err, "subtasks.completion_rule")
+}
+
+// TestValidate_FileExporterNeedsPath checks that the file exporter requires an output file
+func TestValidate_FileExporterNeedsPath(t *testing.T) {
+	// Arrange
+	cfg := Default()
+	cfg.Tracing.Exporter = "file"
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	assert.ErrorContains(t, err, "tracing.file")
+}
+
+// TestMaskDSN checks that passwords are hidden in both DSN styles
+func TestMaskDSN(t *testing.T) {
+	cases := map[string]string{
+		"postgres://todo:secret@db:5432/todo?sslmode=disable": "postgres://todo:xxxxx@db:5432/todo?sslmode=disable",
+		"host=db user=todo password=secret dbname=todo":       "host=db user=todo password=xxxxx dbname=todo",
+		"host=db password='s e c r e t' dbname=todo":          "host=db password=xxxxx dbname=todo",
+		`host=db password='it\'s secret' dbname=todo`:         "host=db password=xxxxx dbname=todo",
+		"postgres://db/todo?password=secret&sslmode=disable":  "postgres://db/todo?password=xxxxx&sslmode=disable",
+		"postgres://todo:secret@db/todo?password=secret":      "postgres://todo:xxxxx@db/todo?password=xxxxx",
+		"postgres://todo:se%cret@db/todo":                     "postgres://todo:xxxxx@db/todo",
+		"postgres://todo@db/todo":                             "postgres://todo@db/todo",
+		"host=localhost user=todo dbname=todo":                "host=localhost user=todo dbname=todo",
+		"file:todo.db?_pragma=busy_timeout(5000)":             "file:todo.db?_pragma=busy_timeout(5000)",
+	}
+	for dsn, want := range cases {
+		assert.Equal(t, want, MaskDSN(dsn), dsn)
+	}
+}
+
+// TestMasked_LeavesOriginalUntouched checks that printing does not alter the live config
+func TestMasked_LeavesOriginalUntouched(t *testing.T) {
+	// Arrange
+	cfg := Default()
+	cfg.DB.DSN = "host=db password=secret"
+
+	// Act
+	printed := cfg.Masked().String()
+
+	// Assert
+	assert.Contains(t, printed, "password=xxxxx")
+	assert.NotContains(t, printed, "secret")
+	assert.Equal(t, "host=db password=secret", cfg.DB.DSN)
+}
@@ -74,9 +74,9 @@ func (s *APITestSuite) TestTaskCRUD() {
 	resp.Body.Close()
 
 	updateReq := dto.UpdateTaskRequest{
-		Title:       utils.Ptr("Updated task"),
-		Description: utils.Ptr("Updated description"),
-		Priority:    utils.Ptr("MEDIUM"),
+		Title:       dto.PatchValue("Updated task"),
+		Description: dto.PatchValue("Updated description"),
+		Priority:    dto.PatchValue("MEDIUM"),
 	}
 	updateBody, _ := json.Marshal(updateReq)
 
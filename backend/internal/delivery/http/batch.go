@@ -0,0 +1,192 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
+	"todo/internal/domain/model"
+	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
+	"todo/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+)
+
+// BatchTasks godoc
+// @Summary     Apply many task operations at once
+// @Description Runs up to 100 create, update, set_completion and delete operations. Each task may appear in
+// @Description one operation only. In atomic mode, the default, they run in one transaction: the response is
+// @Description 200 when all succeed, otherwise it has the status of the first failed operation and the others
+// @Description report 424. In best_effort mode every operation stands alone and the response is 207.
+// @Tags        tasks
+// @Accept      json
+// @Produce     json
+// @Param       batch  body      dto.BatchRequest  true  "Operations"
+// @Success     200   {object}  dto.BatchResponse
+// @Success     207   {object}  dto.BatchResponse
+// @Failure     400   {object}  dto.BatchResponse     // Invalid request, or an invalid operation in atomic mode
+// @Failure     404   {object}  dto.BatchResponse     // A task of an atomic batch was not found
+// @Failure     409   {object}  dto.BatchResponse     // A task of an atomic batch exists or changed
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/batch [post]
+func (h *TaskHandler) BatchTasks(c *gin.Context) {
+	var req dto.BatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.Mode == "" {
+		req.Mode = batchModeAtomic
+	}
+	atomic := req.Mode == batchModeAtomic
+
+	errs := make([]error, len(req.Operations))
+	tasks := make([]*model.Task, len(req.Operations))
+	ops := make([]model.TaskOperation, 0, len(req.Operations))
+	positions := make([]int, 0, len(req.Operations))
+	for i, item := range req.Operations {
+		op, err := taskOperation(item)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		ops = append(ops, op)
+		positions = append(positions, i)
+	}
+
+	// An atomic batch with an unreadable operation is not worth a trip to
+	// the database.
+	if atomic && len(ops) < len(req.Operations) {
+		for _, i := range positions {
+			errs[i] = usecase.ErrBatchAborted
+		}
+	} else if len(ops) > 0 {
+		results, err := h.usecase.ApplyBatch(c.Request.Context(), ops, atomic)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		for j, i := range positions {
+			errs[i], tasks[i] = results[j].Err, results[j].Task
+		}
+	}
+
+	problems, err := middleware.OperationProblems(c, errs)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	// Failed operations are reported in the body rather than through
+	// c.Error, so ErrorHandler never logs them.
+	if failed := countFailedOperations(errs); failed > 0 {
+		h.logger.InfoContext(c.Request.Context(), "batch operations failed",
+			slog.String("mode", req.Mode), slog.Int("operations", len(errs)), slog.Int("failed", failed))
+	}
+
+	status := http.StatusOK
+	if !atomic {
+		status = http.StatusMultiStatus
+	}
+	resp := dto.BatchResponse{Mode: req.Mode, Results: make([]dto.BatchResult, len(req.Operations))}
+	for i, item := range req.Operations {
+		result := dto.BatchResult{Op: item.Op, ID: item.ID, Status: batchSuccessStatus(item.Op)}
+		if problems[i] != nil {
+			result.Status = problems[i].Status
+			result.Error = problems[i]
+			if atomic && status == http.StatusOK && !errors.Is(errs[i], usecase.ErrBatchAborted) {
+				status = result.Status
+			}
+		}
+		if tasks[i] != nil {
+			task := toTaskResponse(tasks[i])
+			result.ID = task.ID
+			result.Task = &task
+		}
+		resp.Results[i] = result
+	}
+	c.JSON(status, resp)
+}
+
+// taskOperation checks that an operation carries what its kind needs and
+// decodes its task.
+func taskOperation(item dto.BatchOperation) (model.TaskOperation, error) {
+	op := model.TaskOperation{
+		Kind:    model.TaskOperationKind(item.Op),
+		ID:      item.ID,
+		Version: item.Version,
+	}
+	if op.Kind != model.OperationCreate && item.ID == "" {
+		return op, validation.NewFieldError("id", validation.CodeRequired, i18n.FieldRequired, "id")
+	}
+	if (op.Kind == model.OperationCreate || op.Kind == model.OperationUpdate) && len(item.Task) == 0 {
+		return op, validation.NewFieldError("task", validation.CodeRequired, i18n.FieldRequired, "task")
+	}
+
+	switch op.Kind {
+	case model.OperationCreate:
+		var req dto.CreateTaskRequest
+		if err := json.Unmarshal(item.Task, &req); err != nil {
+			return op, err
+		}
+		if err := binding.Validator.ValidateStruct(&req); err != nil {
+			return op, err
+		}
+		if item.ID != "" && req.ID != "" && item.ID != req.ID {
+			return op, validation.NewFieldError("id", validation.CodeInvalid, i18n.FieldMismatch, "id")
+		}
+		if req.ID == "" {
+			req.ID = item.ID
+		}
+		op.ID = ""
+		op.Task = &model.Task{
+			ID:          req.ID,
+			Title:       req.Title,
+			Description: req.Description,
+			Deadline:    req.Deadline,
+			Priority:    model.TaskPriority(req.Priority),
+		}
+	case model.OperationUpdate:
+		req, err := decodeTaskMergePatch(item.Task)
+		if err != nil {
+			return op, err
+		}
+		op.Apply = func(task *model.Task) { applyTaskMergePatch(task, req) }
+	case model.OperationSetCompletion:
+		if item.IsCompleted == nil {
+			return op, validation.NewFieldError("is_completed", validation.CodeRequired, i18n.FieldRequired, "is_completed")
+		}
+		op.IsCompleted = *item.IsCompleted
+	}
+	return op, nil
+}
+
+// batchSuccessStatus is what the single-task endpoint answers on success.
+func batchSuccessStatus(op string) int {
+	switch model.TaskOperationKind(op) {
+	case model.OperationCreate:
+		return http.StatusCreated
+	case model.OperationDelete:
+		return http.StatusNoContent
+	default:
+		return http.StatusOK
+	}
+}
+
+func countFailedOperations(errs []error) int {
+	failed := 0
+	for _, err := range errs {
+		if err != nil {
+			failed++
+		}
+	}
+	return failed
+}
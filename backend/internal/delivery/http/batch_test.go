@@ -0,0 +1,185 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/logging"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postBatch(t *testing.T, uc *mockTaskUsecase, body string) (*httptest.ResponseRecorder, dto.BatchResponse) {
+	t.Helper()
+	router := setupRouter(NewTaskHandler(uc, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/tasks/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	var resp dto.BatchResponse
+	if w.Header().Get("Content-Type") != middleware.ProblemContentType {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+// TestTaskHandler_BatchTasks_BestEffort checks that operations are decoded per kind and every
+// outcome is reported with the status of its single-task endpoint
+func TestTaskHandler_BatchTasks_BestEffort(t *testing.T) {
+	// Arrange
+	var gotOps []model.TaskOperation
+	var gotAtomic bool
+	mockUC := &mockTaskUsecase{
+		ApplyBatchFunc: func(ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+			gotOps, gotAtomic = ops, atomic
+			created := newTestTask()
+			created.ID = "new"
+			updated := newTestTask()
+			ops[1].Apply(updated)
+			return []model.TaskOperationResult{
+				{Task: created},
+				{Task: updated},
+				{Err: repository.ErrVersionConflict},
+				{Err: repository.ErrTaskNotFound},
+			}, nil
+		},
+	}
+
+	// Act
+	w, resp := postBatch(t, mockUC, `{"mode":"best_effort","operations":[
+		{"op":"create","task":{"title":"New task"}},
+		{"op":"update","id":"1","task":{"priority":"HIGH","description":null}},
+		{"op":"set_completion","id":"2","version":3,"is_completed":true},
+		{"op":"delete","id":"3"}
+	]}`)
+
+	// Assert
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	assert.False(t, gotAtomic)
+	require.Len(t, gotOps, 4)
+	assert.Equal(t, "New task", gotOps[0].Task.Title)
+	assert.Equal(t, model.TaskOperation{Kind: model.OperationSetCompletion, ID: "2", Version: 3, IsCompleted: true}, gotOps[2])
+	assert.Equal(t, model.OperationDelete, gotOps[3].Kind)
+
+	require.Len(t, resp.Results, 4)
+	assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+	assert.Equal(t, "new", resp.Results[0].ID)
+	assert.Equal(t, http.StatusOK, resp.Results[1].Status)
+	assert.Equal(t, "HIGH", resp.Results[1].Task.Priority)
+	assert.Equal(t, http.StatusConflict, resp.Results[2].Status)
+	assert.Equal(t, middleware.ProblemTypeConflict, resp.Results[2].Error.Type)
+	assert.Equal(t, http.StatusNotFound, resp.Results[3].Status)
+	assert.Equal(t, "3", resp.Results[3].ID)
+}
+
+// TestTaskHandler_BatchTasks_AtomicInvalidOperation checks that an atomic batch with an invalid
+// operation is refused without reaching the usecase
+func TestTaskHandler_BatchTasks_AtomicInvalidOperation(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		ApplyBatchFunc: func(ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+			t.Fatal("ApplyBatch must not be called")
+			return nil, nil
+		},
+	}
+
+	// Act
+	w, resp := postBatch(t, mockUC, `{"operations":[
+		{"op":"delete","id":"1"},
+		{"op":"set_completion","id":"2"},
+		{"op":"create","task":{"title":"bad"}}
+	]}`)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "atomic", resp.Mode)
+	require.Len(t, resp.Results, 3)
+	assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+	assert.Equal(t, middleware.ProblemTypeBatchAborted, resp.Results[0].Error.Type)
+	assert.Equal(t, "is_completed", resp.Results[1].Error.Errors[0].Field)
+	assert.Equal(t, "title", resp.Results[2].Error.Errors[0].Field)
+}
+
+// TestTaskHandler_BatchTasks_AtomicConflict checks that a rolled back batch answers with the status
+// of the operation that failed
+func TestTaskHandler_BatchTasks_AtomicConflict(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		ApplyBatchFunc: func(ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+			assert.True(t, atomic)
+			return []model.TaskOperationResult{
+				{Err: usecase.ErrBatchAborted},
+				{Err: repository.ErrTaskExists},
+			}, nil
+		},
+	}
+
+	// Act
+	w, resp := postBatch(t, mockUC, `{"mode":"atomic","operations":[
+		{"op":"set_completion","id":"1","is_completed":false},
+		{"op":"create","id":"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60","task":{"title":"Offline task"}}
+	]}`)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, http.StatusFailedDependency, resp.Results[0].Status)
+	assert.Equal(t, middleware.ProblemTypeAlreadyExists, resp.Results[1].Error.Type)
+}
+
+// TestTaskHandler_BatchTasks_InvalidRequest checks that a batch without operations or with an unknown
+// mode is refused as a whole
+func TestTaskHandler_BatchTasks_InvalidRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"operations":[]}`,
+		`{"mode":"eventually","operations":[{"op":"delete","id":"1"}]}`,
+		`{"operations":[{"op":"archive","id":"1"}]}`,
+	} {
+		// Act
+		w, _ := postBatch(t, &mockTaskUsecase{}, body)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Equal(t, middleware.ProblemContentType, w.Header().Get("Content-Type"), body)
+	}
+}
+
+// TestTaskHandler_BatchTasks_LogsFailedOperations checks that the failures reported in a batch
+// response are logged, since they never reach the error handler
+func TestTaskHandler_BatchTasks_LogsFailedOperations(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	mockUC := &mockTaskUsecase{
+		ApplyBatchFunc: func(ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+			return []model.TaskOperationResult{{Task: newTestTask()}, {Err: repository.ErrTaskNotFound}}, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, logging.New(&out, "info", "json")))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/tasks/batch", strings.NewReader(`{"mode":"best_effort","operations":[
+		{"op":"delete","id":"1"},
+		{"op":"delete","id":"2"}
+	]}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusMultiStatus, w.Code)
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &line))
+	assert.Equal(t, "batch operations failed", line["msg"])
+	assert.Equal(t, "best_effort", line["mode"])
+	assert.Equal(t, float64(2), line["operations"])
+	assert.Equal(t, float64(1), line["failed"])
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "encoding/json"
+
+// BatchRequest is the body of POST /api/tasks/batch. In atomic mode, the
+// default, either every operation takes effect or none does. In best_effort
+// mode each operation succeeds or fails on its own.
+type BatchRequest struct {
+	Mode       string           `json:"mode" binding:"omitempty,oneof=atomic best_effort" example:"atomic"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// BatchOperation is one step of a batch. Task is the new task for create and
+// a JSON Merge Patch for update. ID names the task that update,
+// set_completion and delete apply to; for create it may carry the client's
+// choice of ID instead of task.id. A non-zero Version must match the task's
+// current version, like If-Match on the single-task endpoints.
+type BatchOperation struct {
+	Op          string          `json:"op" binding:"required,oneof=create update set_completion delete" example:"set_completion"`
+	ID          string          `json:"id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Version     int64           `json:"version,omitempty" binding:"min=0" example:"3"`
+	Task        json.RawMessage `json:"task,omitempty" swaggertype:"object"`
+	IsCompleted *bool           `json:"is_completed,omitempty" example:"true"`
+}
+
+// BatchResponse holds the outcome of every operation in request order.
+type BatchResponse struct {
+	Mode    string        `json:"mode" example:"atomic"`
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of one operation. Status is the HTTP status the
+// operation would have had on its own endpoint; 424 marks an operation of a
+// failed atomic batch that was not applied.
+type BatchResult struct {
+	Op     string           `json:"op" example:"set_completion"`
+	ID     string           `json:"id,omitempty" example:"123e4567-e89b-12d3-a456-426614174000"`
+	Status int              `json:"status" example:"200"`
+	Task   *TaskResponse    `json:"task,omitempty"`
+	Error  *ProblemResponse `json:"error,omitempty"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+type HealthResponse struct {
+	Status string `json:"status" example:"ok"`
+}
+
+type ReadinessResponse struct {
+	Status string `json:"status" example:"ok"`
+	// Checks maps each dependency to "ok" or the reason it is not ready.
+	Checks map[string]string `json:"checks"`
+}
+
+type VersionResponse struct {
+	Commit        string `json:"commit" example:"3f2c1a9"`
+	GoVersion     string `json:"go_version" example:"go1.24.2"`
+	SchemaVersion *int64 `json:"schema_version,omitempty" example:"20250510"`
+}
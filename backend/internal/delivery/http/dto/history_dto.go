@@ -0,0 +1,32 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TaskHistoryResponse is the body of GET /api/tasks/{id}/history.
+type TaskHistoryResponse struct {
+	Items []TaskEventResponse `json:"items"`
+}
+
+// TaskEventResponse is one change of a task. Type is CREATED, UPDATED,
+// STATUS_CHANGED, DELETED or RESTORED and Origin is API, CRON or MACRO.
+// Version is the task version the change produced.
+type TaskEventResponse struct {
+	ID        int64                 `json:"id" example:"42"`
+	Type      string                `json:"type" example:"STATUS_CHANGED"`
+	Origin    string                `json:"origin" example:"CRON"`
+	Version   int64                 `json:"version" example:"3"`
+	Changes   []TaskFieldChangeItem `json:"changes"`
+	RequestID string                `json:"request_id,omitempty" example:"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"`
+	CreatedAt time.Time             `json:"created_at" example:"2025-06-01T18:00:00Z"`
+}
+
+// TaskFieldChangeItem holds the old and new value of a field as they appear
+// in TaskResponse; null stands for a missing value.
+type TaskFieldChangeItem struct {
+	Field string          `json:"field" example:"status"`
+	Old   json.RawMessage `json:"old" swaggertype:"object"`
+	New   json.RawMessage `json:"new" swaggertype:"object"`
+}
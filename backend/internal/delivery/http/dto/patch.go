@@ -0,0 +1,43 @@
+package dto
+
+import "encoding/json"
+
+// PatchField is one member of a JSON Merge Patch (RFC 7396) document. It tells
+// apart a member that was left out (Set is false), one sent as null (Null is
+// true) and one carrying a value.
+type PatchField[T any] struct {
+	Value T
+	Set   bool
+	Null  bool
+}
+
+// PatchValue returns a member that sets the field to v.
+func PatchValue[T any](v T) PatchField[T] {
+	return PatchField[T]{Value: v, Set: true}
+}
+
+// PatchNull returns a member that clears the field.
+func PatchNull[T any]() PatchField[T] {
+	return PatchField[T]{Set: true, Null: true}
+}
+
+func (f *PatchField[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if string(data) == "null" {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+func (f PatchField[T]) MarshalJSON() ([]byte, error) {
+	if f.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Value)
+}
+
+// IsZero makes absent members disappear under the omitzero option.
+func (f PatchField[T]) IsZero() bool {
+	return !f.Set
+}
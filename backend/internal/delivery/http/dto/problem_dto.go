@@ -0,0 +1,21 @@
+package dto
+
+// ProblemResponse is an RFC 7807 problem details body, served as
+// application/problem+json. Clients branch on Type; Title and Detail are for
+// people and may change.
+type ProblemResponse struct {
+	Type      string               `json:"type" example:"urn:todo:problem:validation"`
+	Title     string               `json:"title" example:"Validation failed"`
+	Status    int                  `json:"status" example:"400"`
+	Detail    string               `json:"detail,omitempty" example:"title must be at least 4 characters"`
+	Instance  string               `json:"instance,omitempty" example:"/api/tasks"`
+	RequestID string               `json:"request_id,omitempty" example:"3f2b8c1e-7d4a-4e55-9a0b-2c6f1d9e8a71"`
+	Errors    []FieldErrorResponse `json:"errors,omitempty"`
+}
+
+// FieldErrorResponse points at one rejected request field.
+type FieldErrorResponse struct {
+	Field   string `json:"field" example:"title"`
+	Code    string `json:"code" example:"min"`
+	Message string `json:"message" example:"title must be at least 4 characters"`
+}
@@ -2,18 +2,24 @@ package dto
 
 import "time"
 
+// CreateTaskRequest is the body of POST /api/tasks and PUT /api/tasks/{id}.
+// ID is optional: a client that creates tasks offline may choose it, as a
+// UUID v4 or v7.
 type CreateTaskRequest struct {
+	ID          string     `json:"id,omitempty" example:"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"`
 	Title       string     `json:"title" binding:"required,min=4" example:"Купить продукты"`
 	Description *string    `json:"description" example:"Купить хлеб, молоко и яйца"`
 	Deadline    *time.Time `json:"deadline" example:"2025-06-01T18:00:00Z"`
 	Priority    string     `json:"priority" example:"MEDIUM"`
 }
 
+// UpdateTaskRequest is a JSON Merge Patch for a task: absent members keep the
+// current value and null clears description or deadline.
 type UpdateTaskRequest struct {
-	Title       *string    `json:"title" example:"Обновлённая задача"`
-	Description *string    `json:"description" example:"Новое описание"`
-	Deadline    *time.Time `json:"deadline,omitempty" example:"2025-06-02T18:00:00Z"`
-	Priority    *string    `json:"priority" example:"HIGH"`
+	Title       PatchField[string]    `json:"title,omitzero" swaggertype:"string" example:"Обновлённая задача"`
+	Description PatchField[string]    `json:"description,omitzero" swaggertype:"string" example:"Новое описание" extensions:"x-nullable"`
+	Deadline    PatchField[time.Time] `json:"deadline,omitzero" swaggertype:"string" format:"date-time" example:"2025-06-02T18:00:00Z" extensions:"x-nullable"`
+	Priority    PatchField[string]    `json:"priority,omitzero" swaggertype:"string" example:"HIGH"`
 }
 
 type TaskResponse struct {
@@ -26,6 +32,24 @@ type TaskResponse struct {
 	CreatedAt   time.Time  `json:"created_at" example:"2025-05-04T21:00:00Z"`
 	UpdatedAt   *time.Time `json:"updated_at" example:"2025-05-04T21:30:00Z"`
 	IsCompleted bool       `json:"is_completed" example:"true"`
+	Version     int64      `json:"version" example:"3"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty" example:"2025-05-31T12:00:00Z"`
+	// ParentID is set for subtasks.
+	ParentID *string `json:"parent_id,omitempty" example:"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"`
+	// Progress is set for tasks that have subtasks.
+	Progress *TaskProgress `json:"progress,omitempty"`
+}
+
+// TaskProgress counts the subtasks of a task.
+type TaskProgress struct {
+	Done  int `json:"done" example:"3"`
+	Total int `json:"total" example:"10"`
+}
+
+// SubtasksResponse is the body of GET /api/tasks/{id}/subtasks.
+type SubtasksResponse struct {
+	Items    []TaskResponse `json:"items"`
+	Progress TaskProgress   `json:"progress"`
 }
 
 type UpdateTaskStatusRequest struct {
@@ -39,6 +63,13 @@ type ListTasksQuery struct {
 	SortOrder string `form:"sort_order"`
 	Page      int    `form:"page"`
 	PageSize  int    `form:"page_size"`
+	Cursor    string `form:"cursor"`
+}
+
+// ListTrashQuery is the query of GET /api/tasks/trash.
+type ListTrashQuery struct {
+	Page     int `form:"page"`
+	PageSize int `form:"page_size"`
 }
 
 type PaginationMeta struct {
@@ -49,6 +80,7 @@ type PaginationMeta struct {
 }
 
 type PaginatedTasksResponse struct {
-	Items []TaskResponse `json:"items"`
-	Meta  PaginationMeta `json:"meta"`
+	Items      []TaskResponse  `json:"items"`
+	Meta       *PaginationMeta `json:"meta,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty" example:"eyJpZCI6IjEyMyJ9"`
 }
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+)
+
+// taskETag is the strong entity tag of a task at the given version. The tag
+// is always scoped to the task's URL, so the version alone identifies it.
+func taskETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ifMatchHolds reports whether an If-Match header value allows a write to a
+// task at the given version. An empty header places no condition and "*"
+// matches any existing task. If-Match uses strong comparison, so weak tags
+// (W/"...") never match.
+func ifMatchHolds(header string, version int64) bool {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return true
+	}
+	current := taskETag(version)
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == current {
+			return true
+		}
+	}
+	return false
+}
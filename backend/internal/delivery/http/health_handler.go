@@ -0,0 +1,99 @@
+package http
+
+import (
+	"context"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"net/http"
+	"todo/internal/delivery/http/dto"
+)
+
+// ReadinessCheck reports why a dependency cannot serve traffic, or nil when it can.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// BuildInfo describes the running binary.
+type BuildInfo struct {
+	Commit    string
+	GoVersion string
+	// SchemaVersion returns the migration version of the database. It is nil
+	// when the storage has no schema, e.g. the in-memory repository.
+	SchemaVersion func(ctx context.Context) (int64, error)
+}
+
+type HealthHandler struct {
+	build  BuildInfo
+	checks []ReadinessCheck
+	logger *slog.Logger
+}
+
+func NewHealthHandler(logger *slog.Logger, build BuildInfo, checks ...ReadinessCheck) *HealthHandler {
+	return &HealthHandler{build: build, checks: checks, logger: logger}
+}
+
+func (h *HealthHandler) RegisterRoutes(r *gin.Engine) {
+	r.GET("/healthz", h.Healthz)
+	r.GET("/readyz", h.Readyz)
+	r.GET("/version", h.Version)
+}
+
+// Healthz godoc
+// @Summary     Liveness probe
+// @Description Reports that the process is up. It does not touch any dependency.
+// @Tags        health
+// @Produce     json
+// @Success     200  {object}  dto.HealthResponse
+// @Router      /healthz [get]
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.HealthResponse{Status: "ok"})
+}
+
+// Readyz godoc
+// @Summary     Readiness probe
+// @Description Runs every readiness check (database, scheduler, migrations) and reports each result.
+// @Tags        health
+// @Produce     json
+// @Success     200  {object}  dto.ReadinessResponse
+// @Failure     503  {object}  dto.ReadinessResponse  // At least one check failed
+// @Router      /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	resp := dto.ReadinessResponse{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	code := http.StatusOK
+	for _, check := range h.checks {
+		if err := check.Check(c.Request.Context()); err != nil {
+			h.logger.WarnContext(c.Request.Context(), "readiness check failed", slog.String("check", check.Name), slog.Any("error", err))
+			resp.Checks[check.Name] = err.Error()
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[check.Name] = "ok"
+	}
+	c.JSON(code, resp)
+}
+
+// Version godoc
+// @Summary     Build information
+// @Description Returns the build commit, Go version and the schema version of the database.
+// @Tags        health
+// @Produce     json
+// @Success     200  {object}  dto.VersionResponse
+// @Failure     500  {object}  dto.ProblemResponse   // Schema version could not be read
+// @Router      /version [get]
+func (h *HealthHandler) Version(c *gin.Context) {
+	resp := dto.VersionResponse{
+		Commit:    h.build.Commit,
+		GoVersion: h.build.GoVersion,
+	}
+	if h.build.SchemaVersion != nil {
+		version, err := h.build.SchemaVersion(c.Request.Context())
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		resp.SchemaVersion = &version
+	}
+	c.JSON(http.StatusOK, resp)
+}
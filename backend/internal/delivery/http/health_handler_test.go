@@ -0,0 +1,162 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
+)
+
+func setupHealthRouter(handler *HealthHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.ErrorHandler(discardLogger))
+	handler.RegisterRoutes(r)
+	return r
+}
+
+func passingCheck(name string) ReadinessCheck {
+	return ReadinessCheck{Name: name, Check: func(ctx context.Context) error { return nil }}
+}
+
+// TestHealthHandler_Healthz checks that liveness does not depend on readiness checks
+func TestHealthHandler_Healthz(t *testing.T) {
+	// Arrange
+	failing := ReadinessCheck{Name: "database", Check: func(ctx context.Context) error { return errors.New("down") }}
+	router := setupHealthRouter(NewHealthHandler(discardLogger, BuildInfo{}, failing))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+}
+
+// TestHealthHandler_Readyz_AllChecksPass checks that a healthy service reports every check as ok
+func TestHealthHandler_Readyz_AllChecksPass(t *testing.T) {
+	// Arrange
+	router := setupHealthRouter(NewHealthHandler(discardLogger, BuildInfo{}, passingCheck("database"), passingCheck("scheduler")))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	var resp dto.ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ok", resp.Status)
+	assert.Equal(t, map[string]string{"database": "ok", "scheduler": "ok"}, resp.Checks)
+}
+
+// TestHealthHandler_Readyz_CheckFails checks that one failing dependency makes the service unavailable
+func TestHealthHandler_Readyz_CheckFails(t *testing.T) {
+	// Arrange
+	failing := ReadinessCheck{Name: "migrations", Check: func(ctx context.Context) error {
+		return errors.New("2 pending migrations")
+	}}
+	router := setupHealthRouter(NewHealthHandler(discardLogger, BuildInfo{}, passingCheck("database"), failing))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var resp dto.ReadinessResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "unavailable", resp.Status)
+	assert.Equal(t, "ok", resp.Checks["database"])
+	assert.Equal(t, "2 pending migrations", resp.Checks["migrations"])
+}
+
+// TestHealthHandler_Readyz_PassesRequestContext checks that checks are bound to the request
+func TestHealthHandler_Readyz_PassesRequestContext(t *testing.T) {
+	// Arrange
+	type ctxKey struct{}
+	var got context.Context
+	check := ReadinessCheck{Name: "database", Check: func(ctx context.Context) error {
+		got = ctx
+		return nil
+	}}
+	router := setupHealthRouter(NewHealthHandler(discardLogger, BuildInfo{}, check))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/readyz", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "marker"))
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.NotNil(t, got)
+	assert.Equal(t, "marker", got.Value(ctxKey{}))
+}
+
+// TestHealthHandler_Version checks that build information and schema version are reported
+func TestHealthHandler_Version(t *testing.T) {
+	// Arrange
+	build := BuildInfo{
+		Commit:        "abc123",
+		GoVersion:     "go1.24.2",
+		SchemaVersion: func(ctx context.Context) (int64, error) { return 20250510, nil },
+	}
+	router := setupHealthRouter(NewHealthHandler(discardLogger, build))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/version", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"commit":"abc123","go_version":"go1.24.2","schema_version":20250510}`, w.Body.String())
+}
+
+// TestHealthHandler_Version_WithoutSchema checks that storage without a schema omits the version
+func TestHealthHandler_Version_WithoutSchema(t *testing.T) {
+	// Arrange
+	router := setupHealthRouter(NewHealthHandler(discardLogger, BuildInfo{Commit: "abc123", GoVersion: "go1.24.2"}))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/version", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"commit":"abc123","go_version":"go1.24.2"}`, w.Body.String())
+}
+
+// TestHealthHandler_Version_SchemaError checks that a failing schema lookup is reported as a server error
+func TestHealthHandler_Version_SchemaError(t *testing.T) {
+	// Arrange
+	build := BuildInfo{SchemaVersion: func(ctx context.Context) (int64, error) { return 0, errors.New("db down") }}
+	router := setupHealthRouter(NewHealthHandler(discardLogger, build))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/version", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
@@ -0,0 +1,51 @@
+package http
+
+import (
+	"net/http"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetTaskHistory godoc
+// @Summary     Get the history of a task
+// @Description Returns every recorded change of a task, oldest first: creation, updates, status changes,
+// @Description deletion and restoration, with the old and new field values and what made the change.
+// @Description Tasks in the trash keep their history until they are purged.
+// @Tags        tasks
+// @Produce     json
+// @Param       id   path      string  true  "Task ID"
+// @Success     200  {object}  dto.TaskHistoryResponse
+// @Failure     404  {object}  dto.ProblemResponse   // Task not found
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/history [get]
+func (h *TaskHandler) GetTaskHistory(c *gin.Context) {
+	events, err := h.usecase.GetTaskHistory(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	items := make([]dto.TaskEventResponse, 0, len(events))
+	for _, event := range events {
+		items = append(items, toTaskEventResponse(event))
+	}
+	c.JSON(http.StatusOK, dto.TaskHistoryResponse{Items: items})
+}
+
+func toTaskEventResponse(event *model.TaskEvent) dto.TaskEventResponse {
+	changes := make([]dto.TaskFieldChangeItem, 0, len(event.Changes))
+	for _, change := range event.Changes {
+		changes = append(changes, dto.TaskFieldChangeItem{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return dto.TaskEventResponse{
+		ID:        event.ID,
+		Type:      string(event.Type),
+		Origin:    string(event.Origin),
+		Version:   event.Version,
+		Changes:   changes,
+		RequestID: event.RequestID,
+		CreatedAt: event.CreatedAt,
+	}
+}
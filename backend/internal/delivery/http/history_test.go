@@ -0,0 +1,71 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskHandler_GetTaskHistory checks that events are returned in order with their field changes
+func TestTaskHandler_GetTaskHistory(t *testing.T) {
+	// Arrange
+	var gotID string
+	now := time.Now().UTC()
+	mockUC := &mockTaskUsecase{
+		GetTaskHistoryFunc: func(id string) ([]*model.TaskEvent, error) {
+			gotID = id
+			return []*model.TaskEvent{
+				{ID: 1, TaskID: id, Type: model.TaskEventCreated, Origin: model.OriginAPI, Version: 1, RequestID: "req-1", CreatedAt: now},
+				{ID: 2, TaskID: id, Type: model.TaskEventStatusChanged, Origin: model.OriginCron, Version: 2, CreatedAt: now,
+					Changes: []model.TaskFieldChange{{Field: "status", Old: json.RawMessage(`"ACTIVE"`), New: json.RawMessage(`"OVERDUE"`)}}},
+			}, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/1/history", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", gotID)
+	var resp dto.TaskHistoryResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, "CREATED", resp.Items[0].Type)
+	assert.Equal(t, "req-1", resp.Items[0].RequestID)
+	assert.NotNil(t, resp.Items[0].Changes)
+	assert.Equal(t, "CRON", resp.Items[1].Origin)
+	require.Len(t, resp.Items[1].Changes, 1)
+	assert.Equal(t, "status", resp.Items[1].Changes[0].Field)
+	assert.JSONEq(t, `"OVERDUE"`, string(resp.Items[1].Changes[0].New))
+}
+
+// TestTaskHandler_GetTaskHistory_NotFound checks that the history of an unknown task returns 404
+func TestTaskHandler_GetTaskHistory_NotFound(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		GetTaskHistoryFunc: func(id string) ([]*model.TaskEvent, error) {
+			return nil, repository.ErrTaskNotFound
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/missing/history", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+	"todo/internal/i18n"
+	"todo/internal/pkg/jsonpatch"
+	"todo/internal/validation"
+)
+
+// editableTaskMembers are the members of a task document a JSON Patch may
+// change. Everything else is maintained by the server.
+var editableTaskMembers = map[string]bool{
+	"title":       true,
+	"description": true,
+	"deadline":    true,
+	"priority":    true,
+}
+
+// taskMergePatchFromJSONPatch applies a JSON Patch (RFC 6902) to the task as
+// the API returns it and translates the outcome into the equivalent merge
+// patch, so both media types share the same validation and update path.
+// Editable members that were removed are cleared. Changes to server-managed
+// members and new members are reported as field errors.
+func taskMergePatchFromJSONPatch(task *model.Task, patch jsonpatch.Patch) (*dto.UpdateTaskRequest, error) {
+	before, err := taskDocument(task)
+	if err != nil {
+		return nil, err
+	}
+	after, err := patch.Apply(before)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []validation.FieldError
+	changed := make(map[string]any)
+	for name, old := range before {
+		value, ok := after[name]
+		if ok && reflect.DeepEqual(old, value) {
+			continue
+		}
+		if !editableTaskMembers[name] {
+			fields = append(fields, validation.Field(name, validation.CodeReadOnly, i18n.FieldReadOnly, name))
+			continue
+		}
+		changed[name] = value
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			fields = append(fields, validation.Field(name, validation.CodeUnknown, i18n.FieldUnknown, name))
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+	if err := validation.NewFieldsError(fields); err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(changed)
+	if err != nil {
+		return nil, err
+	}
+	return decodeTaskMergePatch(body)
+}
+
+// taskDocument is the task as a generic JSON object, the target JSON Patch
+// paths refer to.
+func taskDocument(task *model.Task) (map[string]any, error) {
+	data, err := json.Marshal(toTaskResponse(task))
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,110 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"reflect"
+	"sort"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+	"todo/internal/i18n"
+	"todo/internal/validation"
+)
+
+// MergePatchContentType is the media type of JSON Merge Patch documents.
+// Plain application/json is accepted for the same body as well.
+const MergePatchContentType = "application/merge-patch+json"
+
+// taskPatchMember describes one member a task merge patch may carry.
+type taskPatchMember struct {
+	dst      json.Unmarshaler
+	nullable bool
+	dateTime bool
+}
+
+// decodeTaskMergePatch reads a merge patch document member by member so every
+// problem is reported at once: unknown members, values of the wrong type and
+// nulls for fields that cannot be cleared each become a field error. A body
+// that is not a JSON object is rejected as malformed.
+func decodeTaskMergePatch(body []byte) (*dto.UpdateTaskRequest, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil, io.EOF
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(body, &members); err != nil {
+		return nil, err
+	}
+	if members == nil {
+		return nil, &json.UnmarshalTypeError{Value: "null", Type: reflect.TypeOf(members)}
+	}
+
+	var req dto.UpdateTaskRequest
+	known := map[string]taskPatchMember{
+		"title":       {dst: &req.Title},
+		"description": {dst: &req.Description, nullable: true},
+		"deadline":    {dst: &req.Deadline, nullable: true, dateTime: true},
+		"priority":    {dst: &req.Priority},
+	}
+
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var fields []validation.FieldError
+	for _, name := range names {
+		member, ok := known[name]
+		if !ok {
+			fields = append(fields, validation.Field(name, validation.CodeUnknown, i18n.FieldUnknown, name))
+			continue
+		}
+		raw := members[name]
+		if string(raw) == "null" && !member.nullable {
+			fields = append(fields, validation.Field(name, validation.CodeNotNull, i18n.FieldNotNull, name))
+			continue
+		}
+		if err := member.dst.UnmarshalJSON(raw); err != nil {
+			fields = append(fields, memberError(name, member, err))
+		}
+	}
+	if err := validation.NewFieldsError(fields); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+func memberError(name string, member taskPatchMember, err error) validation.FieldError {
+	if member.dateTime {
+		return validation.Field(name, validation.CodeInvalid, i18n.FieldDateTime, name)
+	}
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return validation.Field(name, validation.CodeInvalid, i18n.FieldWrongType, name, typeErr.Type.String())
+	}
+	return validation.Field(name, validation.CodeInvalid, i18n.FieldInvalid, name)
+}
+
+// applyTaskMergePatch copies the members present in req onto task.
+func applyTaskMergePatch(task *model.Task, req *dto.UpdateTaskRequest) {
+	if req.Title.Set {
+		task.Title = req.Title.Value
+	}
+	if req.Description.Set {
+		task.Description = nil
+		if !req.Description.Null {
+			task.Description = &req.Description.Value
+		}
+	}
+	if req.Deadline.Set {
+		task.Deadline = nil
+		if !req.Deadline.Null {
+			task.Deadline = &req.Deadline.Value
+		}
+	}
+	if req.Priority.Set {
+		task.Priority = model.TaskPriority(req.Priority.Value)
+	}
+}
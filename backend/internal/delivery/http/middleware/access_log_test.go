@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAccessLog_WritesOneStructuredLine(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logging.New(&out, "info", "json")))
+	router.GET("/api/tasks/:id", func(c *gin.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/7", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("expected a single JSON line, got %q: %v", out.String(), err)
+	}
+	want := map[string]any{
+		"msg":        "http request",
+		"level":      "INFO",
+		"method":     "GET",
+		"path":       "/api/tasks/7",
+		"route":      "/api/tasks/:id",
+		"status":     float64(200),
+		"request_id": "req-7",
+	}
+	for key, value := range want {
+		if line[key] != value {
+			t.Errorf("%s: expected %v, got %v", key, value, line[key])
+		}
+	}
+}
+
+func TestAccessLog_ServerErrorsAtErrorLevel(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(AccessLog(logging.New(&out, "info", "json")))
+	router.GET("/test", func(c *gin.Context) {
+		c.Status(http.StatusInternalServerError)
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON line, got %q: %v", out.String(), err)
+	}
+	if line["level"] != "ERROR" {
+		t.Errorf("expected ERROR level, got %v", line["level"])
+	}
+}
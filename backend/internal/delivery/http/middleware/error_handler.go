@@ -1,43 +1,129 @@
 package middleware
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
-	"log"
+	"log/slog"
 	"net/http"
-	"runtime/debug"
 	"todo/internal/domain/repository"
-	"todo/internal/validation"
+	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
+	"todo/internal/pkg/jsonpatch"
 )
 
-func ErrorHandler() gin.HandlerFunc {
+// ErrorHandler maps the first error recorded with c.Error to an RFC 7807
+// problem response, with titles and messages in the language asked for by
+// Accept-Language. Every response carries the request ID so a client report
+// can be matched with the log line written here.
+func ErrorHandler(logger *slog.Logger) gin.HandlerFunc {
+	useRequestFieldNames()
 	return func(c *gin.Context) {
 		c.Next()
 		if len(c.Errors) > 0 {
 			err := c.Errors[0].Err
-			log.Printf("Error: %v", err)
-			switch {
-			case errors.Is(err, repository.ErrTaskNotFound):
-				c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
-			case isValidationError(err):
-				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			default:
-				log.Printf("Stack trace: %s", debug.Stack())
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-			}
-			c.Abort()
+			ctx := c.Request.Context()
+			locale := requestLocale(c)
+			if errors.Is(err, repository.ErrTaskNotFound) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusNotFound, ProblemTypeNotFound, i18n.ProblemNotFound, i18n.Translate(locale, i18n.TaskNotFound), nil)
+				return
+			}
+			if errors.Is(err, repository.ErrTaskExists) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypeAlreadyExists, i18n.ProblemAlreadyExists, i18n.Translate(locale, i18n.TaskExists), nil)
+				return
+			}
+			if errors.Is(err, repository.ErrVersionConflict) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				// A client that sent If-Match asked for a conditional write, so a
+				// version mismatch fails its precondition. Without it the write
+				// only lost a race with another request.
+				if c.GetHeader("If-Match") != "" {
+					abortWithProblem(c, locale, http.StatusPreconditionFailed, ProblemTypePreconditionFailed, i18n.ProblemPreconditionFailed, i18n.Translate(locale, i18n.TaskPreconditionFailed), nil)
+					return
+				}
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypeConflict, i18n.ProblemConflict, i18n.Translate(locale, i18n.TaskModified), nil)
+				return
+			}
+			if errors.Is(err, usecase.ErrNothingToUndo) || errors.Is(err, usecase.ErrUndoExpired) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				detail := i18n.TaskUndoNothing
+				if errors.Is(err, usecase.ErrUndoExpired) {
+					detail = i18n.TaskUndoExpired
+				}
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypeUndoUnavailable, i18n.ProblemUndoUnavailable, i18n.Translate(locale, detail), nil)
+				return
+			}
+			if detail, ok := subtaskRuleDetail(err); ok {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypeSubtaskRule, i18n.ProblemSubtaskRule, i18n.Translate(locale, detail), nil)
+				return
+			}
+			if errors.Is(err, ErrRequestInProgress) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypeRequestInProgress, i18n.ProblemRequestInProgress, i18n.Translate(locale, i18n.RequestInProgress), nil)
+				return
+			}
+			if errors.Is(err, ErrIdempotencyKeyReused) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusUnprocessableEntity, ProblemTypeIdempotencyKeyReused, i18n.ProblemIdempotencyKeyReused, i18n.Translate(locale, i18n.IdempotencyKeyReused), nil)
+				return
+			}
+			var patchErr *jsonpatch.Error
+			if errors.As(err, &patchErr) && errors.Is(err, jsonpatch.ErrTestFailed) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusConflict, ProblemTypePatchTestFailed, i18n.ProblemPatchTestFailed, i18n.Translate(locale, i18n.PatchTestFailed, patchErr.Index, patchErr.Path), nil)
+				return
+			}
+			if detail, fields, ok := validationProblem(err, locale); ok {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusBadRequest, ProblemTypeValidation, i18n.ProblemValidation, detail, fields)
+				return
+			}
+			if detail, fields, ok := malformedRequest(err, locale); ok {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusBadRequest, ProblemTypeMalformed, i18n.ProblemMalformed, detail, fields)
+				return
+			}
+			if errors.Is(err, ErrUnsupportedMediaType) {
+				logger.InfoContext(ctx, "request failed", slog.Any("error", err), slog.String("content_type", c.ContentType()))
+				abortWithProblem(c, locale, http.StatusUnsupportedMediaType, ProblemTypeUnsupportedMedia, i18n.ProblemUnsupportedMedia, i18n.Translate(locale, i18n.MediaTypeUnsupported, c.ContentType()), nil)
+				return
+			}
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				logger.WarnContext(ctx, "request timed out", slog.Any("error", err))
+				abortWithProblem(c, locale, http.StatusGatewayTimeout, ProblemTypeTimeout, i18n.ProblemTimeout, "", nil)
+				return
+			}
+			logger.ErrorContext(ctx, "request failed", slog.Any("error", err), slog.Any("error_chain", errorChain(err)))
+			abortWithProblem(c, locale, http.StatusInternalServerError, ProblemTypeInternal, i18n.ProblemInternal, "", nil)
 		}
 	}
 }
 
-func isValidationError(err error) bool {
-	if errors.As(err, new(validator.ValidationErrors)) {
-		return true
+// errorChain describes err and every error it wraps, outermost first, so the
+// log shows where an unexpected error came from.
+func errorChain(err error) []string {
+	var chain []string
+	for err != nil {
+		chain = append(chain, fmt.Sprintf("%T: %v", err, err))
+		err = errors.Unwrap(err)
 	}
-	var vErr *validation.ValidationError
-	if errors.As(err, &vErr) {
-		return true
+	return chain
+}
+
+// subtaskRuleDetail returns the message id that explains a broken subtask
+// rule.
+func subtaskRuleDetail(err error) (string, bool) {
+	switch {
+	case errors.Is(err, usecase.ErrSubtaskDepthExceeded):
+		return i18n.TaskSubtaskTooDeep, true
+	case errors.Is(err, usecase.ErrSubtasksOpen):
+		return i18n.TaskSubtasksOpen, true
+	case errors.Is(err, usecase.ErrTaskHasSubtasks):
+		return i18n.TaskHasSubtasks, true
 	}
-	return false
+	return "", false
 }
@@ -1,21 +1,31 @@
 package middleware
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/gin-gonic/gin"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
+	"todo/internal/delivery/http/dto"
 	"todo/internal/domain/repository"
+	"todo/internal/i18n"
+	"todo/internal/logging"
 	"todo/internal/validation"
 )
 
+var discardLogger = slog.New(slog.DiscardHandler)
+
 func TestErrorHandler_TaskNotFound(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorHandler())
+	router.Use(ErrorHandler(discardLogger))
 	router.GET("/test", func(c *gin.Context) {
 		c.Error(repository.ErrTaskNotFound)
 	})
@@ -32,13 +42,16 @@ func TestErrorHandler_TaskNotFound(t *testing.T) {
 	if !strings.Contains(w.Body.String(), "task not found") {
 		t.Errorf("expected error message in body, got %s", w.Body.String())
 	}
+	if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, ProblemContentType) {
+		t.Errorf("expected %s content type, got %s", ProblemContentType, got)
+	}
 }
 
 func TestErrorHandler_ValidationError(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorHandler())
+	router.Use(ErrorHandler(discardLogger))
 	router.GET("/test", func(c *gin.Context) {
 		c.Error(validation.NewValidationError("validation failed"))
 	})
@@ -61,7 +74,7 @@ func TestErrorHandler_InternalError(t *testing.T) {
 	// Arrange
 	gin.SetMode(gin.TestMode)
 	router := gin.New()
-	router.Use(ErrorHandler())
+	router.Use(ErrorHandler(discardLogger))
 	router.GET("/test", func(c *gin.Context) {
 		c.Error(errors.New("some internal error"))
 	})
@@ -75,7 +88,239 @@ func TestErrorHandler_InternalError(t *testing.T) {
 	if w.Code != http.StatusInternalServerError {
 		t.Errorf("expected 500, got %d", w.Code)
 	}
-	if !strings.Contains(w.Body.String(), "internal server error") {
-		t.Errorf("expected error message in body, got %s", w.Body.String())
+	if !strings.Contains(w.Body.String(), `"type":"`+ProblemTypeInternal+`"`) {
+		t.Errorf("expected internal problem type in body, got %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "some internal error") {
+		t.Errorf("expected internal details to stay out of the body, got %s", w.Body.String())
+	}
+}
+
+func TestErrorHandler_LogsErrorChain(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var out bytes.Buffer
+	router := gin.New()
+	router.Use(ErrorHandler(logging.New(&out, "info", "json")))
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(fmt.Errorf("load task: %w", errors.New("connection reset")))
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	router.ServeHTTP(w, req)
+
+	// Assert
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON line, got %q: %v", out.String(), err)
+	}
+	want := []any{"*fmt.wrapError: load task: connection reset", "*errors.errorString: connection reset"}
+	if !reflect.DeepEqual(line["error_chain"], want) {
+		t.Errorf("expected error chain %v, got %v", want, line["error_chain"])
+	}
+	if _, ok := line["stack"]; ok {
+		t.Errorf("expected no middleware stack in the log line")
+	}
+}
+
+func TestErrorHandler_IncludesRequestID(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler(discardLogger))
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(errors.New("boom"))
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-42")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), `"request_id":"req-42"`) {
+		t.Errorf("expected request id in body, got %s", w.Body.String())
+	}
+}
+
+func TestErrorHandler_ValidationFieldErrors(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID(), ErrorHandler(discardLogger))
+	router.GET("/test", func(c *gin.Context) {
+		c.Error(validation.NewFieldError("page", validation.CodeMin, "page must be greater than 0"))
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	req.Header.Set(RequestIDHeader, "req-7")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	var problem dto.ProblemResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("expected problem body, got %s", w.Body.String())
+	}
+	want := dto.ProblemResponse{
+		Type:      ProblemTypeValidation,
+		Title:     "Validation failed",
+		Status:    http.StatusBadRequest,
+		Detail:    "page must be greater than 0",
+		Instance:  "/test",
+		RequestID: "req-7",
+		Errors:    []dto.FieldErrorResponse{{Field: "page", Code: "min", Message: "page must be greater than 0"}},
+	}
+	if !reflect.DeepEqual(want, problem) {
+		t.Errorf("expected %+v, got %+v", want, problem)
+	}
+}
+
+func TestErrorHandler_BindingErrorsUseRequestFieldNames(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(discardLogger))
+	router.POST("/test", func(c *gin.Context) {
+		var req struct {
+			Title    string `json:"title" binding:"required,min=4"`
+			Priority string `json:"priority" binding:"omitempty,oneof=LOW HIGH"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+		}
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/test", strings.NewReader(`{"title":"ab","priority":"URGENT"}`))
+	router.ServeHTTP(w, req)
+
+	// Assert
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected 400, got %d", w.Code)
+	}
+	var problem dto.ProblemResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("expected problem body, got %s", w.Body.String())
+	}
+	want := []dto.FieldErrorResponse{
+		{Field: "title", Code: "min", Message: "title must be at least 4 characters"},
+		{Field: "priority", Code: "oneof", Message: "priority must be one of LOW, HIGH"},
+	}
+	if !reflect.DeepEqual(want, problem.Errors) {
+		t.Errorf("expected %+v, got %+v", want, problem.Errors)
+	}
+	if problem.Detail != "title must be at least 4 characters; priority must be one of LOW, HIGH" {
+		t.Errorf("expected detail built from field messages, got %q", problem.Detail)
+	}
+}
+
+func TestErrorHandler_MalformedBody(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(discardLogger))
+	router.POST("/test", func(c *gin.Context) {
+		var req struct {
+			IsCompleted bool `json:"is_completed"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		body      string
+		wantField string
+	}{
+		{name: "syntax", body: `{"is_completed":`},
+		{name: "empty", body: ``},
+		{name: "wrong type", body: `{"is_completed":"yes"}`, wantField: "is_completed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/test", strings.NewReader(tt.body))
+			router.ServeHTTP(w, req)
+
+			// Assert
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("expected 400, got %d", w.Code)
+			}
+			var problem dto.ProblemResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("expected problem body, got %s", w.Body.String())
+			}
+			if problem.Type != ProblemTypeMalformed {
+				t.Errorf("expected malformed request type, got %s", problem.Type)
+			}
+			if tt.wantField != "" && (len(problem.Errors) != 1 || problem.Errors[0].Field != tt.wantField) {
+				t.Errorf("expected error for %s, got %+v", tt.wantField, problem.Errors)
+			}
+		})
+	}
+}
+
+func TestErrorHandler_LocalizesMessages(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(discardLogger))
+	router.POST("/usecase", func(c *gin.Context) {
+		c.Error(validation.NewFieldError("page", validation.CodeMin, i18n.PageTooSmall))
+	})
+	router.POST("/binding", func(c *gin.Context) {
+		var req struct {
+			Title string `json:"title" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(err)
+		}
+	})
+
+	tests := []struct {
+		path         string
+		acceptLang   string
+		wantLanguage string
+		wantTitle    string
+		wantMessage  string
+	}{
+		{path: "/usecase", acceptLang: "ru-RU,ru;q=0.9", wantLanguage: "ru", wantTitle: "Ошибка валидации", wantMessage: "номер страницы должен быть больше 0"},
+		{path: "/usecase", acceptLang: "fr", wantLanguage: "en", wantTitle: "Validation failed", wantMessage: "page must be greater than 0"},
+		{path: "/binding", acceptLang: "ru", wantLanguage: "ru", wantTitle: "Ошибка валидации", wantMessage: "поле title обязательно"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.acceptLang, func(t *testing.T) {
+			// Act
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", tt.path, strings.NewReader(`{}`))
+			req.Header.Set("Accept-Language", tt.acceptLang)
+			router.ServeHTTP(w, req)
+
+			// Assert
+			if got := w.Header().Get("Content-Language"); got != tt.wantLanguage {
+				t.Errorf("expected Content-Language %s, got %s", tt.wantLanguage, got)
+			}
+			var problem dto.ProblemResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("expected problem body, got %s", w.Body.String())
+			}
+			if problem.Title != tt.wantTitle {
+				t.Errorf("expected title %q, got %q", tt.wantTitle, problem.Title)
+			}
+			if len(problem.Errors) != 1 || problem.Errors[0].Message != tt.wantMessage {
+				t.Errorf("expected message %q, got %+v", tt.wantMessage, problem.Errors)
+			}
+		})
 	}
 }
@@ -0,0 +1,195 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/i18n"
+	"todo/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response that was stored earlier and
+	// sent again instead of running the request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+const maxIdempotencyKeyLength = 255
+
+var (
+	// ErrIdempotencyKeyReused is recorded when a key comes back with a request
+	// that differs from the one it was first used for.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was used for a different request")
+	// ErrRequestInProgress is recorded when the request that first used a key
+	// has not finished yet.
+	ErrRequestInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// replayedHeaders are the response headers stored with the body and sent
+// again on replay.
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+// Idempotency makes POST requests that carry an Idempotency-Key safe to retry.
+// The first request with a key claims it for lease and runs; a successful
+// response is stored for ttl and a retry with the same method, path and body
+// gets it back without running again. Failed requests release the key so
+// they can be retried. A request that outlives its lease may find the key
+// claimed again by a retry; it then leaves the newer claim alone. Requests
+// without the header are not affected. It is meant to be mounted on the
+// routes that accept the header, not on the whole router.
+func Idempotency(store repository.IdempotencyRepository, ttl, lease time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if err := validIdempotencyKey(key); err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		body, err := c.GetRawData()
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		// Database timestamps keep microseconds, and the claim is matched
+		// by its creation time when it is stored or released.
+		now := time.Now().UTC().Truncate(time.Microsecond)
+		record := &model.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: requestFingerprint(c.Request, body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(lease),
+		}
+		err = store.Create(ctx, record)
+		if errors.Is(err, repository.ErrIdempotencyKeyExists) {
+			replayIdempotentResponse(c, store, record)
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+		c.Writer = recorder.ResponseWriter
+
+		// The outcome is recorded even if the client has gone away: that is
+		// exactly the case a retry will come back for.
+		ctx = context.WithoutCancel(ctx)
+		status := recorder.Status()
+		if len(c.Errors) > 0 || !recorder.Written() || status < 200 || status >= 300 {
+			err := store.Delete(ctx, key, record.CreatedAt)
+			switch {
+			case errors.Is(err, repository.ErrIdempotencyKeyNotFound):
+				logger.WarnContext(ctx, "idempotency key lease expired before the request finished")
+			case err != nil:
+				logger.ErrorContext(ctx, "failed to release idempotency key", slog.Any("error", err))
+			}
+			return
+		}
+		record.StatusCode = status
+		record.Header = make(map[string]string)
+		for _, name := range replayedHeaders {
+			if value := recorder.Header().Get(name); value != "" {
+				record.Header[name] = value
+			}
+		}
+		record.Body = recorder.body.Bytes()
+		record.ExpiresAt = now.Add(ttl)
+		err = store.Update(ctx, record)
+		switch {
+		case errors.Is(err, repository.ErrIdempotencyKeyNotFound):
+			logger.WarnContext(ctx, "idempotency key lease expired before the request finished; response not stored")
+		case err != nil:
+			logger.ErrorContext(ctx, "failed to store idempotent response", slog.Any("error", err))
+		}
+	}
+}
+
+// replayIdempotentResponse answers a request whose key is already held by
+// record's key: with the stored response if it is a retry of a finished
+// request, otherwise with an error.
+func replayIdempotentResponse(c *gin.Context, store repository.IdempotencyRepository, retry *model.IdempotencyRecord) {
+	defer c.Abort()
+	stored, err := store.Find(c.Request.Context(), retry.Key, retry.CreatedAt)
+	switch {
+	case errors.Is(err, repository.ErrIdempotencyKeyNotFound):
+		// The claim expired between the two calls; the client may retry.
+		c.Error(ErrRequestInProgress)
+	case err != nil:
+		c.Error(err)
+	case stored.Fingerprint != retry.Fingerprint:
+		c.Error(ErrIdempotencyKeyReused)
+	case stored.StatusCode == 0:
+		c.Error(ErrRequestInProgress)
+	default:
+		for name, value := range stored.Header {
+			c.Header(name, value)
+		}
+		c.Header(IdempotentReplayedHeader, "true")
+		c.Writer.WriteHeader(stored.StatusCode)
+		_, _ = c.Writer.Write(stored.Body)
+	}
+}
+
+// validIdempotencyKey accepts printable ASCII without spaces up to
+// maxIdempotencyKeyLength characters, the same alphabet as X-Request-ID.
+func validIdempotencyKey(key string) error {
+	var field validation.FieldError
+	switch {
+	case len(key) > maxIdempotencyKeyLength:
+		field = validation.Field(IdempotencyKeyHeader, validation.CodeMax, i18n.FieldMaxLength, IdempotencyKeyHeader, strconv.Itoa(maxIdempotencyKeyLength))
+	case !validRequestID(key):
+		field = validation.Field(IdempotencyKeyHeader, validation.CodeInvalid, i18n.FieldInvalid, IdempotencyKeyHeader)
+	default:
+		return nil
+	}
+	return validation.NewFieldsError([]validation.FieldError{field})
+}
+
+// requestFingerprint identifies a request by method, path with query and
+// body. Retries are expected to resend the body byte for byte.
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// bodyRecorder keeps a copy of the response body as it is written.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
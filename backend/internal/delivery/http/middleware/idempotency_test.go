@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newIdempotentRouter serves POST /tasks, which answers 201 with a new id on
+// every call, or 400 for the body "fail". calls counts handler runs.
+func newIdempotentRouter(store *repository.IdempotencyMemoryRepository, calls *int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(discardLogger))
+	router.Use(Idempotency(store, time.Hour, time.Minute, discardLogger))
+	router.POST("/tasks", func(c *gin.Context) {
+		*calls++
+		body, _ := c.GetRawData()
+		if string(body) == "fail" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bad"})
+			return
+		}
+		c.Header("ETag", `"1"`)
+		c.JSON(http.StatusCreated, gin.H{"id": strconv.Itoa(*calls)})
+	})
+	return router
+}
+
+func postWithKey(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/tasks", strings.NewReader(body))
+	req.Header.Set(IdempotencyKeyHeader, key)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotentRouter(repository.NewIdempotencyMemoryRepository(), &calls)
+	first := postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Act
+	retry := postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Assert
+	if calls != 1 {
+		t.Fatalf("expected the handler to run once, ran %d times", calls)
+	}
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("expected the first response %d %s, got %d %s", first.Code, first.Body, retry.Code, retry.Body)
+	}
+	if retry.Header().Get("ETag") != `"1"` || !strings.HasPrefix(retry.Header().Get("Content-Type"), "application/json") {
+		t.Errorf("expected stored headers to be replayed, got %v", retry.Header())
+	}
+	if retry.Header().Get(IdempotentReplayedHeader) != "true" || first.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("expected only the replay to be marked")
+	}
+}
+
+func TestIdempotency_KeyReusedWithDifferentBody(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotentRouter(repository.NewIdempotencyMemoryRepository(), &calls)
+	postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Act
+	w := postWithKey(router, "key-1", `{"title":"Other task"}`)
+
+	// Assert
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", w.Code)
+	}
+	if !strings.Contains(w.Body.String(), ProblemTypeIdempotencyKeyReused) {
+		t.Errorf("expected %s problem, got %s", ProblemTypeIdempotencyKeyReused, w.Body)
+	}
+	if calls != 1 {
+		t.Errorf("expected the handler to run once, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_RequestInProgress(t *testing.T) {
+	// Arrange
+	calls := 0
+	store := repository.NewIdempotencyMemoryRepository()
+	router := newIdempotentRouter(store, &calls)
+	first := httptest.NewRequest("POST", "/tasks", strings.NewReader(`{"title":"Task"}`))
+	now := time.Now().UTC()
+	err := store.Create(context.Background(), &model.IdempotencyRecord{
+		Key:         "key-1",
+		Fingerprint: requestFingerprint(first, []byte(`{"title":"Task"}`)),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Minute),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	w := postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Assert
+	if w.Code != http.StatusConflict || !strings.Contains(w.Body.String(), ProblemTypeRequestInProgress) {
+		t.Errorf("expected 409 %s, got %d %s", ProblemTypeRequestInProgress, w.Code, w.Body)
+	}
+	if calls != 0 {
+		t.Errorf("expected the handler not to run, ran %d times", calls)
+	}
+}
+
+func TestIdempotency_FailedRequestReleasesKey(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotentRouter(repository.NewIdempotencyMemoryRepository(), &calls)
+	postWithKey(router, "key-1", "fail")
+
+	// Act
+	w := postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Assert
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected the key to be usable again, got %d %s", w.Code, w.Body)
+	}
+	if calls != 2 {
+		t.Errorf("expected the handler to run twice, ran %d times", calls)
+	}
+}
+
+// TestIdempotency_LeaseExpired checks that a request outliving its lease
+// leaves the claim of the retry that took the key over alone
+func TestIdempotency_LeaseExpired(t *testing.T) {
+	// Arrange
+	calls := 0
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(ErrorHandler(discardLogger))
+	router.Use(Idempotency(repository.NewIdempotencyMemoryRepository(), time.Hour, 0, discardLogger))
+	var retry *httptest.ResponseRecorder
+	router.POST("/tasks", func(c *gin.Context) {
+		calls++
+		call := calls
+		if call == 1 {
+			// The retry arrives while the first request is still running.
+			retry = postWithKey(router, "key-1", `{"title":"Task"}`)
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": strconv.Itoa(call)})
+	})
+
+	// Act
+	first := postWithKey(router, "key-1", `{"title":"Task"}`)
+	replay := postWithKey(router, "key-1", `{"title":"Task"}`)
+
+	// Assert
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("expected both requests to run, got %d and %d", first.Code, retry.Code)
+	}
+	if calls != 2 {
+		t.Errorf("expected the handler to run twice, ran %d times", calls)
+	}
+	if replay.Header().Get(IdempotentReplayedHeader) != "true" || replay.Body.String() != retry.Body.String() {
+		t.Errorf("expected the retry's response %s to be replayed, got %d %s", retry.Body, replay.Code, replay.Body)
+	}
+}
+
+func TestIdempotency_InvalidKey(t *testing.T) {
+	// Arrange
+	calls := 0
+	router := newIdempotentRouter(repository.NewIdempotencyMemoryRepository(), &calls)
+
+	for _, key := range []string{strings.Repeat("k", maxIdempotencyKeyLength+1), "two words"} {
+		// Act
+		w := postWithKey(router, key, `{"title":"Task"}`)
+
+		// Assert
+		if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), IdempotencyKeyHeader) {
+			t.Errorf("expected 400 naming %s for key %q, got %d %s", IdempotencyKeyHeader, key, w.Code, w.Body)
+		}
+	}
+	if calls != 0 {
+		t.Errorf("expected the handler not to run, ran %d times", calls)
+	}
+}
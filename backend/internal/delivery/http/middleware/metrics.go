@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPObserver records one finished HTTP request.
+type HTTPObserver interface {
+	ObserveHTTP(method, route string, status int, elapsed time.Duration)
+}
+
+// Metrics reports every request to observer, labelled with the route pattern
+// (e.g. /api/tasks/:id) rather than the raw path to keep label cardinality low.
+// Requests that match no route share the "unmatched" label.
+func Metrics(observer HTTPObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		observer.ObserveHTTP(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type recordedRequest struct {
+	method string
+	route  string
+	status int
+}
+
+type recordingObserver struct {
+	requests []recordedRequest
+}
+
+func (o *recordingObserver) ObserveHTTP(method, route string, status int, elapsed time.Duration) {
+	o.requests = append(o.requests, recordedRequest{method: method, route: route, status: status})
+}
+
+func TestMetrics_UsesRoutePattern(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	observer := &recordingObserver{}
+	router := gin.New()
+	router.Use(Metrics(observer))
+	router.GET("/api/tasks/:id", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/tasks/42", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/nowhere", nil))
+
+	// Assert
+	want := []recordedRequest{
+		{method: "GET", route: "/api/tasks/:id", status: http.StatusNoContent},
+		{method: "GET", route: "unmatched", status: http.StatusNotFound},
+	}
+	if len(observer.requests) != len(want) {
+		t.Fatalf("expected %d observations, got %d", len(want), len(observer.requests))
+	}
+	for i := range want {
+		if observer.requests[i] != want[i] {
+			t.Errorf("observation %d: expected %+v, got %+v", i, want[i], observer.requests[i])
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
+	"todo/internal/logging"
+	"todo/internal/pkg/jsonpatch"
+	"todo/internal/validation"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType is the media type of RFC 7807 error bodies.
+const ProblemContentType = "application/problem+json"
+
+// Problem type URIs. They identify the kind of failure and, unlike titles,
+// never change, so clients can branch on them.
+const (
+	ProblemTypeValidation           = "urn:todo:problem:validation"
+	ProblemTypeMalformed            = "urn:todo:problem:malformed-request"
+	ProblemTypeNotFound             = "urn:todo:problem:not-found"
+	ProblemTypeUnsupportedMedia     = "urn:todo:problem:unsupported-media-type"
+	ProblemTypeConflict             = "urn:todo:problem:conflict"
+	ProblemTypeAlreadyExists        = "urn:todo:problem:already-exists"
+	ProblemTypeRequestInProgress    = "urn:todo:problem:request-in-progress"
+	ProblemTypeIdempotencyKeyReused = "urn:todo:problem:idempotency-key-reused"
+	ProblemTypePatchTestFailed      = "urn:todo:problem:patch-test-failed"
+	ProblemTypePreconditionFailed   = "urn:todo:problem:precondition-failed"
+	ProblemTypeBatchAborted         = "urn:todo:problem:batch-aborted"
+	ProblemTypeUndoUnavailable      = "urn:todo:problem:undo-unavailable"
+	ProblemTypeSubtaskRule          = "urn:todo:problem:subtask-rule"
+	ProblemTypeTimeout              = "urn:todo:problem:timeout"
+	ProblemTypeInternal             = "urn:todo:problem:internal"
+)
+
+// ErrUnsupportedMediaType is recorded by handlers that cannot read the
+// request body's Content-Type. The handler lists what it accepts in a header
+// of its own, such as Accept-Patch.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// requestLocale picks the message locale from Accept-Language and announces
+// it, so caches keep one copy of an error response per language.
+func requestLocale(c *gin.Context) i18n.Locale {
+	locale := i18n.Match(c.GetHeader("Accept-Language"))
+	c.Header("Content-Language", locale.String())
+	c.Writer.Header().Add("Vary", "Accept-Language")
+	return locale
+}
+
+// abortWithProblem writes a problem details response for the current request.
+// titleID is the catalog entry for the title.
+func abortWithProblem(c *gin.Context, locale i18n.Locale, status int, problemType, titleID, detail string, fields []dto.FieldErrorResponse) {
+	c.Header("Content-Type", ProblemContentType)
+	c.AbortWithStatusJSON(status, newProblem(c, locale, status, problemType, titleID, detail, fields))
+}
+
+func newProblem(c *gin.Context, locale i18n.Locale, status int, problemType, titleID, detail string, fields []dto.FieldErrorResponse) dto.ProblemResponse {
+	return dto.ProblemResponse{
+		Type:      problemType,
+		Title:     i18n.Translate(locale, titleID),
+		Status:    status,
+		Detail:    detail,
+		Instance:  c.Request.URL.Path,
+		RequestID: logging.RequestID(c.Request.Context()),
+		Errors:    fields,
+	}
+}
+
+// OperationProblems describes the failed operations of a bulk request the
+// way ErrorHandler describes a failed request: problems[i] is nil where
+// errs[i] is. An error that is not about the operation itself, such as a
+// lost database connection, is returned so the whole request fails with it.
+func OperationProblems(c *gin.Context, errs []error) ([]*dto.ProblemResponse, error) {
+	problems := make([]*dto.ProblemResponse, len(errs))
+	var (
+		locale    i18n.Locale
+		localized bool
+	)
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		if !localized {
+			locale, localized = requestLocale(c), true
+		}
+		var problem dto.ProblemResponse
+		switch {
+		case errors.Is(err, usecase.ErrBatchAborted):
+			problem = newProblem(c, locale, http.StatusFailedDependency, ProblemTypeBatchAborted, i18n.ProblemBatchAborted, i18n.Translate(locale, i18n.BatchAborted), nil)
+		case errors.Is(err, repository.ErrTaskNotFound):
+			problem = newProblem(c, locale, http.StatusNotFound, ProblemTypeNotFound, i18n.ProblemNotFound, i18n.Translate(locale, i18n.TaskNotFound), nil)
+		case errors.Is(err, repository.ErrTaskExists):
+			problem = newProblem(c, locale, http.StatusConflict, ProblemTypeAlreadyExists, i18n.ProblemAlreadyExists, i18n.Translate(locale, i18n.BatchTaskExists), nil)
+		case errors.Is(err, repository.ErrVersionConflict):
+			problem = newProblem(c, locale, http.StatusConflict, ProblemTypeConflict, i18n.ProblemConflict, i18n.Translate(locale, i18n.TaskModified), nil)
+		default:
+			if detail, ok := subtaskRuleDetail(err); ok {
+				problem = newProblem(c, locale, http.StatusConflict, ProblemTypeSubtaskRule, i18n.ProblemSubtaskRule, i18n.Translate(locale, detail), nil)
+				break
+			}
+			if detail, fields, ok := validationProblem(err, locale); ok {
+				problem = newProblem(c, locale, http.StatusBadRequest, ProblemTypeValidation, i18n.ProblemValidation, detail, fields)
+				break
+			}
+			if detail, fields, ok := malformedRequest(err, locale); ok {
+				problem = newProblem(c, locale, http.StatusBadRequest, ProblemTypeMalformed, i18n.ProblemMalformed, detail, fields)
+				break
+			}
+			return nil, err
+		}
+		problems[i] = &problem
+	}
+	return problems, nil
+}
+
+// validationProblem converts errors from the usecase layer and from request
+// binding into a detail message and field errors in locale. ok is false when
+// err is neither.
+func validationProblem(err error, locale i18n.Locale) (detail string, fields []dto.FieldErrorResponse, ok bool) {
+	var vErr *validation.ValidationError
+	if errors.As(err, &vErr) {
+		if len(vErr.Fields) == 0 {
+			return vErr.Msg, nil, true
+		}
+		for _, field := range vErr.Fields {
+			fields = append(fields, dto.FieldErrorResponse{Field: field.Field, Code: field.Code, Message: field.Localize(locale)})
+		}
+		return joinMessages(fields), fields, true
+	}
+	var bindErrs validator.ValidationErrors
+	if errors.As(err, &bindErrs) {
+		for _, fe := range bindErrs {
+			fields = append(fields, dto.FieldErrorResponse{Field: fe.Field(), Code: fe.Tag(), Message: bindingMessage(fe, locale)})
+		}
+		return joinMessages(fields), fields, true
+	}
+	return "", nil, false
+}
+
+func joinMessages(fields []dto.FieldErrorResponse) string {
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func bindingMessage(fe validator.FieldError, locale i18n.Locale) string {
+	isString := fe.Kind() == reflect.String
+	switch {
+	case fe.Tag() == "required":
+		return i18n.Translate(locale, i18n.FieldRequired, fe.Field())
+	case fe.Tag() == "min" && isString:
+		return i18n.Translate(locale, i18n.FieldMinLength, fe.Field(), fe.Param())
+	case fe.Tag() == "min":
+		return i18n.Translate(locale, i18n.FieldMin, fe.Field(), fe.Param())
+	case fe.Tag() == "max" && isString:
+		return i18n.Translate(locale, i18n.FieldMaxLength, fe.Field(), fe.Param())
+	case fe.Tag() == "max":
+		return i18n.Translate(locale, i18n.FieldMax, fe.Field(), fe.Param())
+	case fe.Tag() == "oneof":
+		return i18n.Translate(locale, i18n.FieldOneOf, fe.Field(), strings.ReplaceAll(fe.Param(), " ", ", "))
+	default:
+		return i18n.Translate(locale, i18n.FieldInvalid, fe.Field())
+	}
+}
+
+// malformedRequest recognises bodies and query strings that could not be
+// decoded at all. The field is reported when the decoder knows it.
+func malformedRequest(err error, locale i18n.Locale) (detail string, fields []dto.FieldErrorResponse, ok bool) {
+	var (
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		timeErr   *time.ParseError
+		numErr    *strconv.NumError
+		patchErr  *jsonpatch.Error
+	)
+	switch {
+	case errors.As(err, &typeErr) && typeErr.Field == "":
+		return i18n.Translate(locale, i18n.BodyNotObject), nil, true
+	case errors.As(err, &typeErr):
+		message := i18n.Translate(locale, i18n.FieldWrongType, typeErr.Field, typeErr.Type.String())
+		return message, []dto.FieldErrorResponse{{Field: typeErr.Field, Code: validation.CodeInvalid, Message: message}}, true
+	case errors.As(err, &syntaxErr):
+		return i18n.Translate(locale, i18n.BodyInvalidJSON, syntaxErr.Offset), nil, true
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return i18n.Translate(locale, i18n.BodyEmpty), nil, true
+	case errors.As(err, &timeErr):
+		return i18n.Translate(locale, i18n.TimeFormat), nil, true
+	case errors.As(err, &numErr):
+		return i18n.Translate(locale, i18n.NumberInvalid, numErr.Num), nil, true
+	case errors.Is(err, jsonpatch.ErrNotArray):
+		return i18n.Translate(locale, i18n.PatchNotArray), nil, true
+	case errors.As(err, &patchErr):
+		return patchErrorDetail(patchErr, locale), nil, true
+	}
+	return "", nil, false
+}
+
+// patchErrorDetail describes a JSON Patch operation that could not be
+// applied. Failed test operations are not malformed and never get here.
+func patchErrorDetail(err *jsonpatch.Error, locale i18n.Locale) string {
+	switch {
+	case errors.Is(err, jsonpatch.ErrUnsupportedOp):
+		return i18n.Translate(locale, i18n.PatchOpUnsupported, err.Index, err.Op)
+	case errors.Is(err, jsonpatch.ErrMissingValue):
+		return i18n.Translate(locale, i18n.PatchValueMissing, err.Index)
+	case errors.Is(err, jsonpatch.ErrPathNotFound):
+		return i18n.Translate(locale, i18n.PatchPathNotFound, err.Index, err.Path)
+	default:
+		return i18n.Translate(locale, i18n.PatchPathInvalid, err.Index, err.Path)
+	}
+}
+
+var registerFieldNames sync.Once
+
+// useRequestFieldNames makes binding errors name fields as the client sent
+// them (json or form tag) instead of by Go struct field.
+func useRequestFieldNames() {
+	registerFieldNames.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name != "" && name != "-" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	})
+}
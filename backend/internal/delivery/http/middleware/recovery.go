@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"todo/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a 500 problem response and logs it with
+// the stack trace and request ID.
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(c *gin.Context, recovered any) {
+		ctx := c.Request.Context()
+		logger.ErrorContext(ctx, "panic recovered",
+			slog.Any("panic", recovered),
+			slog.String("stack", string(debug.Stack())),
+		)
+		abortWithProblem(c, requestLocale(c), http.StatusInternalServerError, ProblemTypeInternal, i18n.ProblemInternal, "", nil)
+	})
+}
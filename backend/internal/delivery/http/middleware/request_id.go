@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"todo/internal/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength keeps client-supplied IDs from bloating every log line.
+const maxRequestIDLength = 128
+
+// RequestID propagates the caller's X-Request-ID or assigns a new one. The ID
+// is echoed in the response header and stored in the request context, where
+// the logger picks it up.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = uuid.NewString()
+		}
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// validRequestID accepts non-empty IDs of printable ASCII without spaces, so
+// a client cannot inject line breaks or control characters into the logs.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
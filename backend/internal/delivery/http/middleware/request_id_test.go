@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"todo/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func serveWithRequestID(header string) (responseID, contextID string) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/test", func(c *gin.Context) {
+		contextID = logging.RequestID(c.Request.Context())
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/test", nil)
+	if header != "" {
+		req.Header.Set(RequestIDHeader, header)
+	}
+	router.ServeHTTP(w, req)
+	return w.Header().Get(RequestIDHeader), contextID
+}
+
+func TestRequestID_PropagatesClientID(t *testing.T) {
+	// Act
+	responseID, contextID := serveWithRequestID("client-id-1")
+
+	// Assert
+	if responseID != "client-id-1" || contextID != "client-id-1" {
+		t.Errorf("expected client id to be propagated, got header %q and context %q", responseID, contextID)
+	}
+}
+
+func TestRequestID_GeneratesMissingID(t *testing.T) {
+	// Act
+	responseID, contextID := serveWithRequestID("")
+
+	// Assert
+	if responseID == "" {
+		t.Fatal("expected a generated request id")
+	}
+	if responseID != contextID {
+		t.Errorf("expected header %q to match context %q", responseID, contextID)
+	}
+}
+
+func TestRequestID_ReplacesUnsafeID(t *testing.T) {
+	for _, header := range []string{"has space", "line\tbreak", strings.Repeat("a", maxRequestIDLength+1)} {
+		// Act
+		responseID, _ := serveWithRequestID(header)
+
+		// Assert
+		if responseID == header {
+			t.Errorf("expected %q to be replaced", header)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSubtasks godoc
+// @Summary     List the subtasks of a task
+// @Description Returns the subtasks of a task, oldest first, together with how many of them are completed.
+// @Description Subtasks are tasks of their own: they have their own status, history and subtasks.
+// @Tags        subtasks
+// @Produce     json
+// @Param       id   path      string  true  "Task ID"
+// @Success     200  {object}  dto.SubtasksResponse
+// @Failure     404  {object}  dto.ProblemResponse   // Task not found
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/subtasks [get]
+func (h *TaskHandler) ListSubtasks(c *gin.Context) {
+	tasks, err := h.usecase.ListSubtasks(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	resp := dto.SubtasksResponse{
+		Items:    make([]dto.TaskResponse, 0, len(tasks)),
+		Progress: dto.TaskProgress{Total: len(tasks)},
+	}
+	for _, task := range tasks {
+		resp.Items = append(resp.Items, toTaskResponse(task))
+		if task.IsCompleted {
+			resp.Progress.Done++
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// CreateSubtask godoc
+// @Summary     Create a subtask
+// @Description Creates a task under the task in the path, the same way POST /api/tasks does. Subtasks may be
+// @Description nested up to subtasks.max_depth levels below a top-level task. A subtask stays under its parent;
+// @Description it is read and changed under its own ID as well.
+// @Tags        subtasks
+// @Accept      json
+// @Produce     json
+// @Param       id               path      string                 true   "Parent task ID"
+// @Param       task             body      dto.CreateTaskRequest  true   "New subtask data"
+// @Success     201   {object}  dto.TaskResponse
+// @Header      201   {string}  ETag  "Current task version, for If-Match"
+// @Header      201   {string}  Location  "URL of the new task"
+// @Failure     400   {object}  dto.ProblemResponse   // Invalid input
+// @Failure     404   {object}  dto.ProblemResponse   // Parent task not found
+// @Failure     409   {object}  dto.ProblemResponse   // The id is taken, or the subtask would be nested too deep
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/subtasks [post]
+func (h *TaskHandler) CreateSubtask(c *gin.Context) {
+	var req dto.CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+
+	task := &model.Task{
+		ID:          req.ID,
+		Title:       req.Title,
+		Description: req.Description,
+		Deadline:    req.Deadline,
+		Priority:    model.TaskPriority(req.Priority),
+	}
+
+	createdTask, err := h.usecase.CreateSubtask(c.Request.Context(), c.Param("id"), task)
+	if err != nil {
+		if errors.Is(err, repository.ErrTaskExists) {
+			c.Header("Location", taskLocation(req.ID))
+		}
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", taskLocation(createdTask.ID))
+	c.Header("ETag", taskETag(createdTask.Version))
+	c.JSON(http.StatusCreated, toTaskResponse(createdTask))
+}
+
+// GetSubtask godoc
+// @Summary     Get a subtask
+// @Description Returns a subtask of the task in the path, like GET /api/tasks/{subtask_id}.
+// @Tags        subtasks
+// @Produce     json
+// @Param       id          path      string  true  "Parent task ID"
+// @Param       subtask_id  path      string  true  "Subtask ID"
+// @Success     200  {object}  dto.TaskResponse
+// @Header      200  {string}  ETag  "Current task version, for If-Match"
+// @Failure     404  {object}  dto.ProblemResponse   // No such subtask under the task
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/subtasks/{subtask_id} [get]
+func (h *TaskHandler) GetSubtask(c *gin.Context) {
+	h.forSubtask(c, h.GetTask)
+}
+
+// UpdateSubtask godoc
+// @Summary     Update a subtask
+// @Description Changes a subtask of the task in the path, like PATCH /api/tasks/{subtask_id}.
+// @Tags        subtasks
+// @Accept      json,application/merge-patch+json,application/json-patch+json
+// @Produce     json
+// @Param       id          path      string                 true   "Parent task ID"
+// @Param       subtask_id  path      string                 true   "Subtask ID"
+// @Param       If-Match    header    string                 false  "ETag the update is based on"
+// @Param       task        body      dto.UpdateTaskRequest  true   "Updated subtask data"
+// @Success     200   {object}  dto.TaskResponse
+// @Header      200   {string}  ETag  "New task version"
+// @Failure     400   {object}  dto.ProblemResponse   // Invalid input
+// @Failure     404   {object}  dto.ProblemResponse   // No such subtask under the task
+// @Failure     409   {object}  dto.ProblemResponse   // Subtask changed by a concurrent request or a JSON Patch test failed
+// @Failure     412   {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     415   {object}  dto.ProblemResponse   // Unsupported Content-Type
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/subtasks/{subtask_id} [patch]
+func (h *TaskHandler) UpdateSubtask(c *gin.Context) {
+	h.forSubtask(c, h.UpdateTask)
+}
+
+// UpdateSubtaskStatus godoc
+// @Summary     Mark a subtask as completed or not completed
+// @Description Changes the completion of a subtask of the task in the path, like PATCH /api/tasks/{subtask_id}/status.
+// @Tags        subtasks
+// @Accept      json
+// @Produce     json
+// @Param       id          path      string                      true   "Parent task ID"
+// @Param       subtask_id  path      string                      true   "Subtask ID"
+// @Param       If-Match    header    string                      false  "ETag the change is based on"
+// @Param       body        body      dto.UpdateTaskStatusRequest true   "Completion status"
+// @Success     200  {object}  dto.TaskResponse
+// @Header      200  {string}  ETag  "New task version"
+// @Failure     400  {object}  dto.ProblemResponse
+// @Failure     404  {object}  dto.ProblemResponse
+// @Failure     409  {object}  dto.ProblemResponse
+// @Failure     412  {object}  dto.ProblemResponse
+// @Failure     500  {object}  dto.ProblemResponse
+// @Router      /api/tasks/{id}/subtasks/{subtask_id}/status [patch]
+func (h *TaskHandler) UpdateSubtaskStatus(c *gin.Context) {
+	h.forSubtask(c, h.UpdateTaskStatus)
+}
+
+// DeleteSubtask godoc
+// @Summary     Delete a subtask
+// @Description Moves a subtask of the task in the path to the trash, like DELETE /api/tasks/{subtask_id}.
+// @Tags        subtasks
+// @Produce     json
+// @Param       id          path      string  true   "Parent task ID"
+// @Param       subtask_id  path      string  true   "Subtask ID"
+// @Param       If-Match    header    string  false  "ETag the deletion is based on"
+// @Success     204  "Subtask moved to the trash"
+// @Failure     404  {object}  dto.ProblemResponse   // No such subtask under the task
+// @Failure     409  {object}  dto.ProblemResponse   // The subtask has subtasks of its own
+// @Failure     412  {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/subtasks/{subtask_id} [delete]
+func (h *TaskHandler) DeleteSubtask(c *gin.Context) {
+	h.forSubtask(c, h.DeleteTask)
+}
+
+// forSubtask serves a request for a subtask with the handler for the task
+// itself, once the subtask is known to belong to the task in the path.
+func (h *TaskHandler) forSubtask(c *gin.Context, next gin.HandlerFunc) {
+	subtask, err := h.usecase.GetTask(c.Request.Context(), c.Param("subtask_id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if subtask.ParentID == nil || *subtask.ParentID != c.Param("id") {
+		c.Error(repository.ErrTaskNotFound)
+		return
+	}
+	for i := range c.Params {
+		if c.Params[i].Key == "id" {
+			c.Params[i].Value = subtask.ID
+		}
+	}
+	next(c)
+}
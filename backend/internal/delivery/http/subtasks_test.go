@@ -0,0 +1,171 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskHandler_ListSubtasks checks that the subtasks come with their parent and the parent's progress
+func TestTaskHandler_ListSubtasks(t *testing.T) {
+	// Arrange
+	var gotParent string
+	mockUC := &mockTaskUsecase{
+		ListSubtasksFunc: func(parentID string) ([]*model.Task, error) {
+			gotParent = parentID
+			done, open := newTestTask(), newTestTask()
+			done.ID, done.ParentID, done.IsCompleted = "2", utils.Ptr(parentID), true
+			open.ID, open.ParentID = "3", utils.Ptr(parentID)
+			open.Progress = &model.TaskProgress{Done: 0, Total: 4}
+			return []*model.Task{done, open}, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/1/subtasks", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "1", gotParent)
+	var resp dto.SubtasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, dto.TaskProgress{Done: 1, Total: 2}, resp.Progress)
+	require.Len(t, resp.Items, 2)
+	assert.Equal(t, "1", *resp.Items[0].ParentID)
+	assert.Nil(t, resp.Items[0].Progress)
+	assert.Equal(t, &dto.TaskProgress{Done: 0, Total: 4}, resp.Items[1].Progress)
+}
+
+// TestTaskHandler_CreateSubtask checks that a subtask is created under the parent in the path and
+// that a broken subtask rule is reported as 409
+func TestTaskHandler_CreateSubtask(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		status      int
+		problemType string
+	}{
+		{"created", nil, http.StatusCreated, ""},
+		{"unknown parent", repository.ErrTaskNotFound, http.StatusNotFound, middleware.ProblemTypeNotFound},
+		{"too deep", usecase.ErrSubtaskDepthExceeded, http.StatusConflict, middleware.ProblemTypeSubtaskRule},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var gotParent string
+			mockUC := &mockTaskUsecase{
+				CreateSubtaskFunc: func(parentID string, task *model.Task) (*model.Task, error) {
+					gotParent = parentID
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					task.ID, task.ParentID, task.Version = "2", utils.Ptr(parentID), 1
+					return task, nil
+				},
+			}
+			router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/tasks/1/subtasks", bytes.NewBufferString(`{"title":"Tag the build"}`))
+			req.Header.Set("Content-Type", "application/json")
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.status, w.Code)
+			assert.Equal(t, "1", gotParent)
+			if tt.err != nil {
+				var problem dto.ProblemResponse
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+				assert.Equal(t, tt.problemType, problem.Type)
+				return
+			}
+			assert.Equal(t, "/api/tasks/2", w.Header().Get("Location"))
+			var resp dto.TaskResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, "1", *resp.ParentID)
+		})
+	}
+}
+
+// TestTaskHandler_SubtaskRoutes checks that a subtask is served under its own parent only
+func TestTaskHandler_SubtaskRoutes(t *testing.T) {
+	// Arrange
+	var deleted string
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.ID, task.ParentID = id, utils.Ptr("1")
+			return task, nil
+		},
+		DeleteTaskFunc: func(id string, version int64) error {
+			deleted = id
+			return nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	get := httptest.NewRecorder()
+	getReq, _ := http.NewRequest("GET", "/api/tasks/1/subtasks/2", nil)
+	foreign := httptest.NewRecorder()
+	foreignReq, _ := http.NewRequest("DELETE", "/api/tasks/9/subtasks/2", nil)
+	del := httptest.NewRecorder()
+	delReq, _ := http.NewRequest("DELETE", "/api/tasks/1/subtasks/2", nil)
+
+	// Act
+	router.ServeHTTP(get, getReq)
+	router.ServeHTTP(foreign, foreignReq)
+	foreignDeleted := deleted
+	router.ServeHTTP(del, delReq)
+
+	// Assert
+	require.Equal(t, http.StatusOK, get.Code)
+	var resp dto.TaskResponse
+	require.NoError(t, json.Unmarshal(get.Body.Bytes(), &resp))
+	assert.Equal(t, "2", resp.ID)
+	assert.Equal(t, http.StatusNotFound, foreign.Code)
+	assert.Empty(t, foreignDeleted)
+	assert.Equal(t, http.StatusNoContent, del.Code)
+	assert.Equal(t, "2", deleted)
+}
+
+// TestTaskHandler_UpdateTaskStatus_SubtasksOpen checks that completing a task with open subtasks is
+// refused with the subtask rule problem
+func TestTaskHandler_UpdateTaskStatus_SubtasksOpen(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+		SetTaskCompletionFunc: func(task *model.Task) (*model.Task, error) {
+			return nil, usecase.ErrSubtasksOpen
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1/status", bytes.NewBufferString(`{"is_completed":true}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusConflict, w.Code)
+	var problem dto.ProblemResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+	assert.Equal(t, middleware.ProblemTypeSubtaskRule, problem.Type)
+	assert.Equal(t, "the task has open subtasks; complete them first", problem.Detail)
+}
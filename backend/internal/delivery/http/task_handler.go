@@ -1,44 +1,74 @@
 package http
 
 import (
+	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"log/slog"
 	"net/http"
-	"time"
 	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
 	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
 	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
+	"todo/internal/pkg/jsonpatch"
+	"todo/internal/validation"
 )
 
 type TaskHandler struct {
 	usecase usecase.TaskUsecase
+	logger  *slog.Logger
 }
 
-func NewTaskHandler(u usecase.TaskUsecase) *TaskHandler {
-	return &TaskHandler{usecase: u}
+func NewTaskHandler(u usecase.TaskUsecase, logger *slog.Logger) *TaskHandler {
+	return &TaskHandler{usecase: u, logger: logger}
 }
 
-func (h *TaskHandler) RegisterRoutes(r *gin.Engine) {
+// RegisterRoutes mounts the task routes on r. createMiddleware runs before
+// CreateTask only; the server passes the Idempotency middleware there.
+func (h *TaskHandler) RegisterRoutes(r *gin.Engine, createMiddleware ...gin.HandlerFunc) {
 	tasks := r.Group("/api/tasks")
 	{
-		tasks.POST("", h.CreateTask)
+		tasks.POST("", append(createMiddleware, h.CreateTask)...)
+		tasks.POST("/batch", h.BatchTasks)
 		tasks.GET("", h.ListTasks)
+		tasks.GET("/trash", h.ListTrash)
 		tasks.GET("/:id", h.GetTask)
+		tasks.PUT("/:id", h.ReplaceTask)
 		tasks.PATCH("/:id", h.UpdateTask)
 		tasks.PATCH("/:id/status", h.UpdateTaskStatus)
 		tasks.DELETE("/:id", h.DeleteTask)
+		tasks.POST("/:id/restore", h.RestoreTask)
+		tasks.GET("/:id/history", h.GetTaskHistory)
+		tasks.POST("/:id/undo", h.UndoTask)
+		tasks.GET("/:id/subtasks", h.ListSubtasks)
+		tasks.POST("/:id/subtasks", h.CreateSubtask)
+		tasks.GET("/:id/subtasks/:subtask_id", h.GetSubtask)
+		tasks.PATCH("/:id/subtasks/:subtask_id", h.UpdateSubtask)
+		tasks.PATCH("/:id/subtasks/:subtask_id/status", h.UpdateSubtaskStatus)
+		tasks.DELETE("/:id/subtasks/:subtask_id", h.DeleteSubtask)
 	}
 }
 
 // CreateTask godoc
 // @Summary     Create a new task
-// @Description Creates a new task with the provided data
+// @Description Creates a new task with the provided data. A retry with the same Idempotency-Key and body
+// @Description returns the stored response instead of creating another task. The optional id lets a client
+// @Description choose the ID (UUID v4 or v7); if it is taken the response is 409 with the task's Location.
 // @Tags        tasks
 // @Accept      json
 // @Produce     json
-// @Param       task  body      dto.CreateTaskRequest  true  "New task data"
+// @Param       task             body      dto.CreateTaskRequest  true   "New task data"
+// @Param       Idempotency-Key  header    string                 false  "Client-chosen key that makes retries safe"
 // @Success     201   {object}  dto.TaskResponse
-// @Failure     400   {object}  map[string]string   // Invalid input
-// @Failure     500   {object}  map[string]string   // Internal server error
+// @Header      201   {string}  ETag  "Current task version, for If-Match"
+// @Header      201   {string}  Location  "URL of the new task"
+// @Header      201   {string}  Idempotent-Replayed  "true when the response is a replay"
+// @Failure     400   {object}  dto.ProblemResponse   // Invalid input
+// @Failure     409   {object}  dto.ProblemResponse   // The id is taken, or a request with the same Idempotency-Key is still running
+// @Failure     422   {object}  dto.ProblemResponse   // Idempotency-Key was used for a different request
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
 // @Router      /api/tasks [post]
 func (h *TaskHandler) CreateTask(c *gin.Context) {
 	var req dto.CreateTaskRequest
@@ -48,36 +78,136 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 	}
 
 	task := &model.Task{
+		ID:          req.ID,
 		Title:       req.Title,
 		Description: req.Description,
 		Deadline:    req.Deadline,
 		Priority:    model.TaskPriority(req.Priority),
 	}
 
-	createdTask, err := h.usecase.CreateTask(task)
+	createdTask, err := h.usecase.CreateTask(c.Request.Context(), task)
 	if err != nil {
+		if errors.Is(err, repository.ErrTaskExists) {
+			c.Header("Location", taskLocation(req.ID))
+		}
 		c.Error(err)
 		return
 	}
 
-	resp := dto.TaskResponse{
-		ID:          createdTask.ID,
-		Title:       createdTask.Title,
-		Description: createdTask.Description,
-		Deadline:    createdTask.Deadline,
-		Status:      string(createdTask.Status),
-		Priority:    string(createdTask.Priority),
-		CreatedAt:   createdTask.CreatedAt,
-		UpdatedAt:   createdTask.UpdatedAt,
-		IsCompleted: createdTask.IsCompleted,
-	}
+	resp := toTaskResponse(createdTask)
 
+	c.Header("Location", taskLocation(createdTask.ID))
+	c.Header("ETag", taskETag(createdTask.Version))
 	c.JSON(http.StatusCreated, resp)
 }
 
+// ReplaceTask godoc
+// @Summary     Create or replace a task
+// @Description Stores the task under the ID from the URL. An existing task takes title, description, deadline
+// @Description and priority from the body: absent description and deadline are cleared, absent priority becomes
+// @Description MEDIUM, status and completion are kept. A missing task is created; its ID must be a UUID v4 or v7.
+// @Tags        tasks
+// @Accept      json
+// @Produce     json
+// @Param       id        path      string                 true   "Task ID"
+// @Param       If-Match  header    string                 false  "ETag the replacement is based on; the task must exist"
+// @Param       task      body      dto.CreateTaskRequest  true   "Task data"
+// @Success     200   {object}  dto.TaskResponse
+// @Success     201   {object}  dto.TaskResponse
+// @Header      200,201  {string}  ETag      "New task version"
+// @Header      201      {string}  Location  "URL of the new task"
+// @Failure     400   {object}  dto.ProblemResponse   // Invalid input
+// @Failure     409   {object}  dto.ProblemResponse   // Task changed or created by a concurrent request
+// @Failure     412   {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id} [put]
+func (h *TaskHandler) ReplaceTask(c *gin.Context) {
+	id := c.Param("id")
+	var req dto.CreateTaskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(err)
+		return
+	}
+	if req.ID != "" && req.ID != id {
+		c.Error(validation.NewFieldsError([]validation.FieldError{
+			validation.Field("id", validation.CodeInvalid, i18n.FieldMismatch, "id"),
+		}))
+		return
+	}
+
+	existing, err := h.usecase.GetTask(c.Request.Context(), id)
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		// If-Match, even "*", only holds for a task that exists.
+		if c.GetHeader("If-Match") != "" {
+			c.Error(repository.ErrVersionConflict)
+			return
+		}
+		h.createTaskAt(c, id, &req)
+		return
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if !ifMatchHolds(c.GetHeader("If-Match"), existing.Version) {
+		c.Error(repository.ErrVersionConflict)
+		return
+	}
+
+	existing.Title = req.Title
+	existing.Description = req.Description
+	existing.Deadline = req.Deadline
+	existing.Priority = model.TaskPriority(req.Priority)
+	if existing.Priority == "" {
+		existing.Priority = model.PriorityMedium
+	}
+
+	updatedTask, err := h.usecase.UpdateTask(c.Request.Context(), existing)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", taskETag(updatedTask.Version))
+	c.JSON(http.StatusOK, toTaskResponse(updatedTask))
+}
+
+// createTaskAt is the create half of ReplaceTask.
+func (h *TaskHandler) createTaskAt(c *gin.Context, id string, req *dto.CreateTaskRequest) {
+	task := &model.Task{
+		ID:          id,
+		Title:       req.Title,
+		Description: req.Description,
+		Deadline:    req.Deadline,
+		Priority:    model.TaskPriority(req.Priority),
+	}
+
+	createdTask, err := h.usecase.CreateTask(c.Request.Context(), task)
+	if err != nil {
+		// Another request created the task since it was looked up.
+		if errors.Is(err, repository.ErrTaskExists) {
+			c.Header("Location", taskLocation(id))
+		}
+		c.Error(err)
+		return
+	}
+
+	c.Header("Location", taskLocation(createdTask.ID))
+	c.Header("ETag", taskETag(createdTask.Version))
+	c.JSON(http.StatusCreated, toTaskResponse(createdTask))
+}
+
+// taskLocation is the URL of the task with the given ID.
+func taskLocation(id string) string {
+	return "/api/tasks/" + id
+}
+
 // ListTasks godoc
 // @Summary     List all tasks
-// @Description Returns a list of all existing tasks with optional filters and sorting
+// @Description Returns a list of all existing top-level tasks with optional filters and sorting; subtasks
+// @Description are listed under their parent.
+// @Description Passing the cursor parameter (empty for the first page) switches to keyset pagination:
+// @Description the response carries next_cursor instead of meta, and page is ignored.
 // @Tags        tasks
 // @Produce     json
 // @Param       status     query     string  false  "Task status"
@@ -86,8 +216,10 @@ func (h *TaskHandler) CreateTask(c *gin.Context) {
 // @Param       sort_order query     string  false  "Sort order: asc or desc"
 // @Param       page       query     int     false  "Page number"
 // @Param       page_size  query     int     false  "Page size"
+// @Param       cursor     query     string  false  "Opaque cursor from next_cursor"
 // @Success     200  {object}  dto.PaginatedTasksResponse
-// @Failure     500  {object}  map[string]string   // Internal server error
+// @Failure     400  {object}  dto.ProblemResponse   // Invalid filter or cursor
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
 // @Router      /api/tasks [get]
 func (h *TaskHandler) ListTasks(c *gin.Context) {
 	var query dto.ListTasksQuery
@@ -112,32 +244,30 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 		PageSize:  query.PageSize,
 	}
 
-	tasks, total, err := h.usecase.ListTasksWithFilter(filter)
-	if err != nil {
-		c.Error(err)
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		tasks, nextCursor, err := h.usecase.ListTasksByCursor(c.Request.Context(), filter, cursor)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		c.JSON(http.StatusOK, dto.PaginatedTasksResponse{
+			Items:      toTaskResponses(tasks),
+			NextCursor: nextCursor,
+		})
 		return
 	}
 
-	var respItems []dto.TaskResponse
-	for _, t := range tasks {
-		respItems = append(respItems, dto.TaskResponse{
-			ID:          t.ID,
-			Title:       t.Title,
-			Description: t.Description,
-			Deadline:    t.Deadline,
-			Status:      string(t.Status),
-			Priority:    string(t.Priority),
-			CreatedAt:   t.CreatedAt,
-			UpdatedAt:   t.UpdatedAt,
-			IsCompleted: t.IsCompleted,
-		})
+	tasks, total, err := h.usecase.ListTasksWithFilter(c.Request.Context(), filter)
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
 	totalPages := (total + filter.PageSize - 1) / filter.PageSize
 
 	resp := dto.PaginatedTasksResponse{
-		Items: respItems,
-		Meta: dto.PaginationMeta{
+		Items: toTaskResponses(tasks),
+		Meta: &dto.PaginationMeta{
 			Total:      total,
 			Page:       filter.Page,
 			PageSize:   filter.PageSize,
@@ -148,6 +278,39 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 	c.JSON(http.StatusOK, resp)
 }
 
+func toTaskResponses(tasks []*model.Task) []dto.TaskResponse {
+	var respItems []dto.TaskResponse
+	for _, t := range tasks {
+		respItems = append(respItems, toTaskResponse(t))
+	}
+	return respItems
+}
+
+func toTaskResponse(t *model.Task) dto.TaskResponse {
+	return dto.TaskResponse{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Deadline:    t.Deadline,
+		Status:      string(t.Status),
+		Priority:    string(t.Priority),
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		IsCompleted: t.IsCompleted,
+		Version:     t.Version,
+		DeletedAt:   t.DeletedAt,
+		ParentID:    t.ParentID,
+		Progress:    toTaskProgress(t.Progress),
+	}
+}
+
+func toTaskProgress(p *model.TaskProgress) *dto.TaskProgress {
+	if p == nil {
+		return nil
+	}
+	return &dto.TaskProgress{Done: p.Done, Total: p.Total}
+}
+
 // GetTask godoc
 // @Summary     Get a task by ID
 // @Description Returns a task by its identifier
@@ -155,129 +318,139 @@ func (h *TaskHandler) ListTasks(c *gin.Context) {
 // @Produce     json
 // @Param       id   path      string  true  "Task ID"
 // @Success     200  {object}  dto.TaskResponse
-// @Failure     404  {object}  map[string]string   // Task not found
-// @Failure     500  {object}  map[string]string   // Internal server error
+// @Header      200  {string}  ETag  "Current task version, for If-Match"
+// @Failure     404  {object}  dto.ProblemResponse   // Task not found
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
 // @Router      /api/tasks/{id} [get]
 func (h *TaskHandler) GetTask(c *gin.Context) {
 	id := c.Param("id")
-	task, err := h.usecase.GetTask(id)
+	task, err := h.usecase.GetTask(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	resp := dto.TaskResponse{
-		ID:          task.ID,
-		Title:       task.Title,
-		Description: task.Description,
-		Deadline:    task.Deadline,
-		Status:      string(task.Status),
-		Priority:    string(task.Priority),
-		CreatedAt:   task.CreatedAt,
-		UpdatedAt:   task.UpdatedAt,
-		IsCompleted: task.IsCompleted,
-	}
+	resp := toTaskResponse(task)
 
+	c.Header("ETag", taskETag(task.Version))
 	c.JSON(http.StatusOK, resp)
 }
 
 // UpdateTask godoc
 // @Summary     Update a task
-// @Description Updates an existing task by ID
+// @Description Applies a JSON Merge Patch (RFC 7396) to a task: absent fields are kept,
+// @Description null clears description or deadline. Unknown fields and values of the wrong type are rejected.
+// @Description With Content-Type application/json-patch+json the body is a JSON Patch (RFC 6902) with add, remove,
+// @Description replace and test operations over the task document; a failed test returns 409.
 // @Tags        tasks
-// @Accept      json
+// @Accept      json,application/merge-patch+json,application/json-patch+json
 // @Produce     json
-// @Param       id    path      string                 true  "Task ID"
-// @Param       task  body      dto.UpdateTaskRequest  true  "Updated task data"
+// @Param       id        path      string                 true   "Task ID"
+// @Param       If-Match  header    string                 false  "ETag the update is based on"
+// @Param       task      body      dto.UpdateTaskRequest  true   "Updated task data"
 // @Success     200   {object}  dto.TaskResponse
-// @Failure     400   {object}  map[string]string   // Invalid input
-// @Failure     404   {object}  map[string]string   // Task not found
-// @Failure     500   {object}  map[string]string   // Internal server error
+// @Header      200   {string}  ETag  "New task version"
+// @Failure     400   {object}  dto.ProblemResponse   // Invalid input
+// @Failure     404   {object}  dto.ProblemResponse   // Task not found
+// @Failure     409   {object}  dto.ProblemResponse   // Task changed by a concurrent request or a JSON Patch test failed
+// @Failure     412   {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     415   {object}  dto.ProblemResponse   // Unsupported Content-Type
+// @Failure     500   {object}  dto.ProblemResponse   // Internal server error
 // @Router      /api/tasks/{id} [patch]
 func (h *TaskHandler) UpdateTask(c *gin.Context) {
 	id := c.Param("id")
 
-	var rawBody map[string]interface{}
-	if err := c.ShouldBindJSON(&rawBody); err != nil {
-		c.Error(err)
+	contentType := c.ContentType()
+	switch contentType {
+	case MergePatchContentType, jsonpatch.ContentType, binding.MIMEJSON, "":
+	default:
+		c.Header("Accept-Patch", MergePatchContentType+", "+jsonpatch.ContentType)
+		c.Error(middleware.ErrUnsupportedMediaType)
 		return
 	}
-
-	var req dto.UpdateTaskRequest
-	if title, ok := rawBody["title"].(string); ok {
-		req.Title = &title
-	}
-	if desc, ok := rawBody["description"].(string); ok {
-		req.Description = &desc
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(err)
+		return
 	}
-	if deadline, ok := rawBody["deadline"]; ok {
-		if deadline == nil {
-			req.Deadline = nil
-		} else if deadlineStr, ok := deadline.(string); ok {
-			if t, err := time.Parse(time.RFC3339, deadlineStr); err == nil {
-				req.Deadline = &t
-			}
-		}
+	var (
+		req   *dto.UpdateTaskRequest
+		patch jsonpatch.Patch
+	)
+	if contentType == jsonpatch.ContentType {
+		patch, err = jsonpatch.Decode(body)
+	} else {
+		req, err = decodeTaskMergePatch(body)
 	}
-	if priority, ok := rawBody["priority"].(string); ok {
-		req.Priority = &priority
+	if err != nil {
+		c.Error(err)
+		return
 	}
 
-	existing, err := h.usecase.GetTask(id)
+	existing, err := h.usecase.GetTask(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
 		return
 	}
-
-	if req.Title != nil {
-		existing.Title = *req.Title
+	if !ifMatchHolds(c.GetHeader("If-Match"), existing.Version) {
+		c.Error(repository.ErrVersionConflict)
+		return
 	}
-	if req.Description != nil {
-		existing.Description = req.Description
+	// A JSON Patch is evaluated against the version just read. UpdateTask
+	// writes only if that version is still current, so the operations,
+	// including any test, take effect as a whole or not at all.
+	if patch != nil {
+		if req, err = taskMergePatchFromJSONPatch(existing, patch); err != nil {
+			c.Error(err)
+			return
+		}
 	}
 
-	if _, exists := rawBody["deadline"]; exists {
-		existing.Deadline = req.Deadline
-	}
-	if req.Priority != nil {
-		existing.Priority = model.TaskPriority(*req.Priority)
-	}
+	applyTaskMergePatch(existing, req)
 
-	updatedTask, err := h.usecase.UpdateTask(existing)
+	updatedTask, err := h.usecase.UpdateTask(c.Request.Context(), existing)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	resp := dto.TaskResponse{
-		ID:          updatedTask.ID,
-		Title:       updatedTask.Title,
-		Description: updatedTask.Description,
-		Deadline:    updatedTask.Deadline,
-		Status:      string(updatedTask.Status),
-		Priority:    string(updatedTask.Priority),
-		CreatedAt:   updatedTask.CreatedAt,
-		UpdatedAt:   updatedTask.UpdatedAt,
-		IsCompleted: updatedTask.IsCompleted,
-	}
+	resp := toTaskResponse(updatedTask)
 
+	c.Header("ETag", taskETag(updatedTask.Version))
 	c.JSON(http.StatusOK, resp)
 }
 
 // DeleteTask godoc
 // @Summary     Delete a task
-// @Description Deletes a task by its identifier
+// @Description Moves a task to the trash. It disappears from listings and can be restored
+// @Description until the trash is purged.
 // @Tags        tasks
 // @Produce     json
-// @Param       id   path      string  true  "Task ID"
-// @Success     204  "Task successfully deleted"
-// @Failure     404  {object}  map[string]string   // Task not found
-// @Failure     500  {object}  map[string]string   // Internal server error
+// @Param       id        path      string  true   "Task ID"
+// @Param       If-Match  header    string  false  "ETag the deletion is based on"
+// @Success     204  "Task moved to the trash"
+// @Failure     404  {object}  dto.ProblemResponse   // Task not found
+// @Failure     412  {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
 // @Router      /api/tasks/{id} [delete]
 func (h *TaskHandler) DeleteTask(c *gin.Context) {
 	id := c.Param("id")
 
-	if err := h.usecase.DeleteTask(id); err != nil {
+	version := repository.AnyVersion
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		existing, err := h.usecase.GetTask(c.Request.Context(), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if !ifMatchHolds(ifMatch, existing.Version) {
+			c.Error(repository.ErrVersionConflict)
+			return
+		}
+		version = existing.Version
+	}
+
+	if err := h.usecase.DeleteTask(c.Request.Context(), id, version); err != nil {
 		c.Error(err)
 		return
 	}
@@ -291,12 +464,16 @@ func (h *TaskHandler) DeleteTask(c *gin.Context) {
 // @Tags        tasks
 // @Accept      json
 // @Produce     json
-// @Param       id   path      string                     true  "Task ID"
-// @Param       body body      dto.UpdateTaskStatusRequest true  "Completion status"
+// @Param       id       path      string                     true   "Task ID"
+// @Param       If-Match header    string                     false  "ETag the change is based on"
+// @Param       body     body      dto.UpdateTaskStatusRequest true   "Completion status"
 // @Success     200  {object}  dto.TaskResponse
-// @Failure     400  {object}  map[string]string
-// @Failure     404  {object}  map[string]string
-// @Failure     500  {object}  map[string]string
+// @Header      200  {string}  ETag  "New task version"
+// @Failure     400  {object}  dto.ProblemResponse
+// @Failure     404  {object}  dto.ProblemResponse
+// @Failure     409  {object}  dto.ProblemResponse
+// @Failure     412  {object}  dto.ProblemResponse
+// @Failure     500  {object}  dto.ProblemResponse
 // @Router      /api/tasks/{id}/status [patch]
 func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 	id := c.Param("id")
@@ -306,30 +483,25 @@ func (h *TaskHandler) UpdateTaskStatus(c *gin.Context) {
 		return
 	}
 
-	task, err := h.usecase.GetTask(id)
+	task, err := h.usecase.GetTask(c.Request.Context(), id)
 	if err != nil {
 		c.Error(err)
 		return
 	}
+	if !ifMatchHolds(c.GetHeader("If-Match"), task.Version) {
+		c.Error(repository.ErrVersionConflict)
+		return
+	}
 
 	task.IsCompleted = req.IsCompleted
 
-	updatedTask, err := h.usecase.SetTaskCompletion(task)
+	updatedTask, err := h.usecase.SetTaskCompletion(c.Request.Context(), task)
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	resp := dto.TaskResponse{
-		ID:          updatedTask.ID,
-		Title:       updatedTask.Title,
-		Description: updatedTask.Description,
-		Deadline:    updatedTask.Deadline,
-		Status:      string(updatedTask.Status),
-		Priority:    string(updatedTask.Priority),
-		CreatedAt:   updatedTask.CreatedAt,
-		UpdatedAt:   updatedTask.UpdatedAt,
-		IsCompleted: updatedTask.IsCompleted,
-	}
+	resp := toTaskResponse(updatedTask)
+	c.Header("ETag", taskETag(updatedTask.Version))
 	c.JSON(http.StatusOK, resp)
 }
@@ -2,12 +2,15 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 	"todo/internal/delivery/http/dto"
@@ -15,44 +18,96 @@ import (
 	"todo/internal/domain/model"
 	"todo/internal/domain/repository"
 	"todo/internal/pkg/utils"
+	storage "todo/internal/repository"
 	"todo/internal/validation"
 )
 
+var discardLogger = slog.New(slog.DiscardHandler)
+
 // --- Mock Usecase ---
 
 type mockTaskUsecase struct {
 	CreateTaskFunc          func(*model.Task) (*model.Task, error)
 	ListTasksWithFilterFunc func(*model.TaskFilter) ([]*model.Task, int, error)
+	ListTasksByCursorFunc   func(*model.TaskFilter, string) ([]*model.Task, string, error)
 	GetTaskFunc             func(string) (*model.Task, error)
 	UpdateTaskFunc          func(*model.Task) (*model.Task, error)
-	DeleteTaskFunc          func(string) error
+	DeleteTaskFunc          func(string, int64) error
 	SetTaskCompletionFunc   func(*model.Task) (*model.Task, error)
-	UpdateOverdueTasksFunc  func() error
+	UpdateOverdueTasksFunc  func() (int, error)
+	ApplyBatchFunc          func([]model.TaskOperation, bool) ([]model.TaskOperationResult, error)
+	GetDeletedTaskFunc      func(string) (*model.Task, error)
+	ListDeletedTasksFunc    func(int, int) ([]*model.Task, int, error)
+	RestoreTaskFunc         func(string, int64) (*model.Task, error)
+	GetTaskHistoryFunc      func(string) ([]*model.TaskEvent, error)
+	UndoTaskFunc            func(string, int64) (*model.Task, error)
+	CreateSubtaskFunc       func(string, *model.Task) (*model.Task, error)
+	ListSubtasksFunc        func(string) ([]*model.Task, error)
 }
 
-func (m *mockTaskUsecase) CreateTask(t *model.Task) (*model.Task, error) {
+func (m *mockTaskUsecase) CreateTask(ctx context.Context, t *model.Task) (*model.Task, error) {
 	return m.CreateTaskFunc(t)
 }
-func (m *mockTaskUsecase) ListTasksWithFilter(f *model.TaskFilter) ([]*model.Task, int, error) {
+func (m *mockTaskUsecase) ListTasksWithFilter(ctx context.Context, f *model.TaskFilter) ([]*model.Task, int, error) {
 	return m.ListTasksWithFilterFunc(f)
 }
-func (m *mockTaskUsecase) GetTask(id string) (*model.Task, error) {
+func (m *mockTaskUsecase) ListTasksByCursor(ctx context.Context, f *model.TaskFilter, cursor string) ([]*model.Task, string, error) {
+	return m.ListTasksByCursorFunc(f, cursor)
+}
+func (m *mockTaskUsecase) GetTask(ctx context.Context, id string) (*model.Task, error) {
 	return m.GetTaskFunc(id)
 }
-func (m *mockTaskUsecase) UpdateTask(t *model.Task) (*model.Task, error) {
+func (m *mockTaskUsecase) UpdateTask(ctx context.Context, t *model.Task) (*model.Task, error) {
 	return m.UpdateTaskFunc(t)
 }
-func (m *mockTaskUsecase) DeleteTask(id string) error {
-	return m.DeleteTaskFunc(id)
+func (m *mockTaskUsecase) DeleteTask(ctx context.Context, id string, version int64) error {
+	return m.DeleteTaskFunc(id, version)
 }
-func (m *mockTaskUsecase) SetTaskCompletion(t *model.Task) (*model.Task, error) {
+func (m *mockTaskUsecase) SetTaskCompletion(ctx context.Context, t *model.Task) (*model.Task, error) {
 	return m.SetTaskCompletionFunc(t)
 }
-func (m *mockTaskUsecase) UpdateOverdueTasks() error {
+func (m *mockTaskUsecase) ApplyBatch(ctx context.Context, ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+	return m.ApplyBatchFunc(ops, atomic)
+}
+func (m *mockTaskUsecase) GetDeletedTask(ctx context.Context, id string) (*model.Task, error) {
+	return m.GetDeletedTaskFunc(id)
+}
+func (m *mockTaskUsecase) ListDeletedTasks(ctx context.Context, page, pageSize int) ([]*model.Task, int, error) {
+	return m.ListDeletedTasksFunc(page, pageSize)
+}
+func (m *mockTaskUsecase) RestoreTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	return m.RestoreTaskFunc(id, version)
+}
+func (m *mockTaskUsecase) UndoTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	return m.UndoTaskFunc(id, version)
+}
+func (m *mockTaskUsecase) GetTaskHistory(ctx context.Context, id string) ([]*model.TaskEvent, error) {
+	return m.GetTaskHistoryFunc(id)
+}
+func (m *mockTaskUsecase) CreateSubtask(ctx context.Context, parentID string, t *model.Task) (*model.Task, error) {
+	return m.CreateSubtaskFunc(parentID, t)
+}
+func (m *mockTaskUsecase) ListSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	return m.ListSubtasksFunc(parentID)
+}
+func (m *mockTaskUsecase) PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int64, error) {
+	return 0, nil
+}
+func (m *mockTaskUsecase) UpdateOverdueTasks(ctx context.Context) (int, error) {
 	if m.UpdateOverdueTasksFunc != nil {
 		return m.UpdateOverdueTasksFunc()
 	}
-	return nil
+	return 0, nil
+}
+
+type contextRecordingUsecase struct {
+	*mockTaskUsecase
+	got *context.Context
+}
+
+func (m *contextRecordingUsecase) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	*m.got = ctx
+	return m.mockTaskUsecase.GetTask(ctx, id)
 }
 
 // --- Helpers ---
@@ -60,7 +115,7 @@ func (m *mockTaskUsecase) UpdateOverdueTasks() error {
 func setupRouter(handler *TaskHandler) *gin.Engine {
 	gin.SetMode(gin.TestMode)
 	r := gin.New()
-	r.Use(middleware.ErrorHandler())
+	r.Use(middleware.ErrorHandler(discardLogger))
 	handler.RegisterRoutes(r)
 	return r
 }
@@ -77,6 +132,7 @@ func newTestTask() *model.Task {
 		CreatedAt:   now,
 		UpdatedAt:   &now,
 		IsCompleted: false,
+		Version:     1,
 	}
 }
 
@@ -91,7 +147,7 @@ func TestTaskHandler_CreateTask_Success(t *testing.T) {
 			return task, nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	reqBody := dto.CreateTaskRequest{Title: "Test"}
@@ -115,7 +171,7 @@ func TestTaskHandler_CreateTask_ValidationError(t *testing.T) {
 			return nil, validation.NewValidationError("validation error")
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	reqBody := dto.CreateTaskRequest{Title: "bad"}
@@ -131,6 +187,192 @@ func TestTaskHandler_CreateTask_ValidationError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+// TestTaskHandler_CreateTask_ClientID checks that a client-chosen id is passed on and a taken
+// one returns 409 pointing at the existing task
+func TestTaskHandler_CreateTask_ClientID(t *testing.T) {
+	// Arrange
+	const id = "0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"
+	var gotID string
+	mockUC := &mockTaskUsecase{
+		CreateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			gotID = task.ID
+			return nil, repository.ErrTaskExists
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/tasks", strings.NewReader(`{"id":"`+id+`","title":"Offline task"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, id, gotID)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "/api/tasks/"+id, w.Header().Get("Location"))
+	assert.Contains(t, w.Body.String(), middleware.ProblemTypeAlreadyExists)
+}
+
+// TestTaskHandler_CreateTask_Idempotency checks that the create middleware covers POST /api/tasks
+// only: a create is replayed, other POST routes run again
+func TestTaskHandler_CreateTask_Idempotency(t *testing.T) {
+	// Arrange
+	creates, undos := 0, 0
+	mockUC := &mockTaskUsecase{
+		CreateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			creates++
+			task.ID = "1"
+			return task, nil
+		},
+		UndoTaskFunc: func(id string, version int64) (*model.Task, error) {
+			undos++
+			return newTestTask(), nil
+		},
+	}
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.ErrorHandler(discardLogger))
+	NewTaskHandler(mockUC, discardLogger).RegisterRoutes(router, middleware.Idempotency(storage.NewIdempotencyMemoryRepository(), time.Hour, time.Minute, discardLogger))
+	post := func(path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(middleware.IdempotencyKeyHeader, "key-1")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act
+	post("/api/tasks", `{"title":"Test"}`)
+	replay := post("/api/tasks", `{"title":"Test"}`)
+	post("/api/tasks/1/undo", "")
+	undo := post("/api/tasks/1/undo", "")
+
+	// Assert
+	assert.Equal(t, 1, creates)
+	assert.Equal(t, "true", replay.Header().Get(middleware.IdempotentReplayedHeader))
+	assert.Equal(t, 2, undos)
+	assert.Equal(t, http.StatusOK, undo.Code)
+	assert.Empty(t, undo.Header().Get(middleware.IdempotentReplayedHeader))
+}
+
+// TestTaskHandler_ReplaceTask_Creates checks that PUT on a missing task creates it under the URL id
+func TestTaskHandler_ReplaceTask_Creates(t *testing.T) {
+	// Arrange
+	const id = "0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(string) (*model.Task, error) {
+			return nil, repository.ErrTaskNotFound
+		},
+		CreateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			task.Version = 1
+			return task, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/tasks/"+id, strings.NewReader(`{"title":"Offline task"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/api/tasks/"+id, w.Header().Get("Location"))
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+	var resp dto.TaskResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, id, resp.ID)
+}
+
+// TestTaskHandler_ReplaceTask_Replaces checks that PUT on an existing task replaces the editable
+// fields and keeps the rest
+func TestTaskHandler_ReplaceTask_Replaces(t *testing.T) {
+	// Arrange
+	var saved *model.Task
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(string) (*model.Task, error) {
+			task := newTestTask()
+			task.Description = utils.Ptr("old description")
+			task.Priority = model.PriorityHigh
+			task.IsCompleted = true
+			return task, nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			saved = task
+			task.Version++
+			return task, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PUT", "/api/tasks/1", strings.NewReader(`{"title":"Replaced"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"1"`)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+	assert.Equal(t, "Replaced", saved.Title)
+	assert.Nil(t, saved.Description)
+	assert.Equal(t, model.PriorityMedium, saved.Priority)
+	assert.True(t, saved.IsCompleted)
+}
+
+// TestTaskHandler_ReplaceTask_Rejected checks the PUT requests that must not write anything
+func TestTaskHandler_ReplaceTask_Rejected(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		ifMatch  string
+		existing bool
+		want     int
+	}{
+		{name: "id differs from URL", body: `{"id":"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60","title":"Task"}`, existing: true, want: http.StatusBadRequest},
+		{name: "If-Match on missing task", body: `{"title":"Task"}`, ifMatch: "*", want: http.StatusPreconditionFailed},
+		{name: "stale If-Match", body: `{"title":"Task"}`, ifMatch: `"7"`, existing: true, want: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUC := &mockTaskUsecase{
+				GetTaskFunc: func(string) (*model.Task, error) {
+					if !tt.existing {
+						return nil, repository.ErrTaskNotFound
+					}
+					return newTestTask(), nil
+				},
+				CreateTaskFunc: func(*model.Task) (*model.Task, error) {
+					t.Fatal("CreateTask must not be called")
+					return nil, nil
+				},
+				UpdateTaskFunc: func(*model.Task) (*model.Task, error) {
+					t.Fatal("UpdateTask must not be called")
+					return nil, nil
+				},
+			}
+			router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PUT", "/api/tasks/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+		})
+	}
+}
+
 // TestTaskHandler_ListTasks_Success checks that tasks are listed successfully
 func TestTaskHandler_ListTasks_Success(t *testing.T) {
 	// Arrange
@@ -139,7 +381,7 @@ func TestTaskHandler_ListTasks_Success(t *testing.T) {
 			return []*model.Task{newTestTask()}, 1, nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
@@ -160,7 +402,7 @@ func TestTaskHandler_ListTasks_Error(t *testing.T) {
 			return nil, 0, errors.New("internal error")
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
@@ -173,6 +415,35 @@ func TestTaskHandler_ListTasks_Error(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
 
+// TestTaskHandler_ListTasks_CursorMode checks that the cursor parameter switches to keyset pagination
+func TestTaskHandler_ListTasks_CursorMode(t *testing.T) {
+	// Arrange
+	var gotCursor string
+	mockUC := &mockTaskUsecase{
+		ListTasksByCursorFunc: func(f *model.TaskFilter, cursor string) ([]*model.Task, string, error) {
+			gotCursor = cursor
+			return []*model.Task{newTestTask()}, "next", nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks?cursor=abc&page_size=1", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "abc", gotCursor)
+	var resp dto.PaginatedTasksResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Len(t, resp.Items, 1)
+	assert.Equal(t, "next", resp.NextCursor)
+	assert.Nil(t, resp.Meta)
+}
+
 // TestTaskHandler_GetTask_Success checks that a task is retrieved successfully by ID
 func TestTaskHandler_GetTask_Success(t *testing.T) {
 	// Arrange
@@ -181,17 +452,44 @@ func TestTaskHandler_GetTask_Success(t *testing.T) {
 			return newTestTask(), nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/1", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"1"`, w.Header().Get("ETag"))
+}
+
+// TestTaskHandler_GetTask_PassesRequestContext checks that the usecase receives the request context
+func TestTaskHandler_GetTask_PassesRequestContext(t *testing.T) {
+	// Arrange
+	type ctxKey struct{}
+	var got context.Context
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+	}
+	handler := NewTaskHandler(&contextRecordingUsecase{mockTaskUsecase: mockUC, got: &got}, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("GET", "/api/tasks/1", nil)
+	req = req.WithContext(context.WithValue(req.Context(), ctxKey{}, "marker"))
 
 	// Act
 	router.ServeHTTP(w, req)
 
 	// Assert
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotNil(t, got)
+	assert.Equal(t, "marker", got.Value(ctxKey{}))
 }
 
 // TestTaskHandler_GetTask_NotFound checks that requesting non-existent task returns not found error
@@ -202,7 +500,7 @@ func TestTaskHandler_GetTask_NotFound(t *testing.T) {
 			return nil, repository.ErrTaskNotFound
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
@@ -227,10 +525,10 @@ func TestTaskHandler_UpdateTask_Success(t *testing.T) {
 			return task, nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
-	reqBody := dto.UpdateTaskRequest{Title: utils.Ptr("Updated")}
+	reqBody := dto.UpdateTaskRequest{Title: dto.PatchValue("Updated")}
 	body, _ := json.Marshal(reqBody)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader(body))
@@ -254,10 +552,10 @@ func TestTaskHandler_UpdateTask_ValidationError(t *testing.T) {
 			return nil, validation.NewValidationError("validation error")
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
-	reqBody := dto.UpdateTaskRequest{Title: utils.Ptr("bad")}
+	reqBody := dto.UpdateTaskRequest{Title: dto.PatchValue("bad")}
 	body, _ := json.Marshal(reqBody)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("PATCH", "/api/tasks/1", bytes.NewReader(body))
@@ -270,6 +568,239 @@ func TestTaskHandler_UpdateTask_ValidationError(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
+// TestTaskHandler_UpdateTask_MergePatchNullClears checks that null clears nullable fields
+// while absent members keep their value
+func TestTaskHandler_UpdateTask_MergePatchNullClears(t *testing.T) {
+	// Arrange
+	var saved *model.Task
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.Description = utils.Ptr("old description")
+			task.Deadline = utils.Ptr(time.Now().Add(time.Hour))
+			return task, nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			saved = task
+			return task, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"description":null}`))
+	req.Header.Set("Content-Type", MergePatchContentType)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, saved.Description)
+	assert.NotNil(t, saved.Deadline)
+	assert.Equal(t, "Test", saved.Title)
+}
+
+// TestTaskHandler_UpdateTask_MergePatchFieldErrors checks that every bad member is reported
+// as a field error and nothing is written
+func TestTaskHandler_UpdateTask_MergePatchFieldErrors(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			t.Fatal("UpdateTask must not be called")
+			return nil, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	body := `{"title":null,"deadline":"tomorrow","priority":5,"colour":"red"}`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", MergePatchContentType)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var resp dto.ProblemResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []dto.FieldErrorResponse{
+		{Field: "colour", Code: validation.CodeUnknown, Message: "colour is not a known field"},
+		{Field: "deadline", Code: validation.CodeInvalid, Message: "deadline must be a date-time in RFC 3339 format"},
+		{Field: "priority", Code: validation.CodeInvalid, Message: "priority must be a string"},
+		{Field: "title", Code: validation.CodeNotNull, Message: "title cannot be null"},
+	}, resp.Errors)
+}
+
+// TestTaskHandler_UpdateTask_MalformedPatch checks that bodies that are not JSON objects are rejected
+func TestTaskHandler_UpdateTask_MalformedPatch(t *testing.T) {
+	for _, body := range []string{``, `null`, `[{"title":"x"}]`, `{"title":`} {
+		// Arrange
+		handler := NewTaskHandler(&mockTaskUsecase{}, discardLogger)
+		router := setupRouter(handler)
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(body))
+		req.Header.Set("Content-Type", MergePatchContentType)
+
+		// Act
+		router.ServeHTTP(w, req)
+
+		// Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code, "body %q", body)
+		assert.Contains(t, w.Body.String(), middleware.ProblemTypeMalformed, "body %q", body)
+	}
+}
+
+// TestTaskHandler_UpdateTask_JSONPatch checks that a JSON Patch whose test holds is applied
+// and removing an editable member clears it
+func TestTaskHandler_UpdateTask_JSONPatch(t *testing.T) {
+	// Arrange
+	var saved *model.Task
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.Description = utils.Ptr("old description")
+			return task, nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			saved = task
+			return task, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	body := `[
+		{"op":"test","path":"/status","value":"ACTIVE"},
+		{"op":"replace","path":"/priority","value":"HIGH"},
+		{"op":"remove","path":"/description"}
+	]`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, model.PriorityHigh, saved.Priority)
+	assert.Nil(t, saved.Description)
+	assert.Equal(t, "Test", saved.Title)
+}
+
+// TestTaskHandler_UpdateTask_JSONPatchTestFailed checks that a failed test operation
+// returns 409 and nothing is written
+func TestTaskHandler_UpdateTask_JSONPatchTestFailed(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			t.Fatal("UpdateTask must not be called")
+			return nil, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	body := `[{"op":"test","path":"/status","value":"COMPLETED"},{"op":"replace","path":"/priority","value":"HIGH"}]`
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp dto.ProblemResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, middleware.ProblemTypePatchTestFailed, resp.Type)
+	assert.Equal(t, `operation 0: the value at "/status" does not match`, resp.Detail)
+}
+
+// TestTaskHandler_UpdateTask_JSONPatchErrors checks that patches touching server-managed
+// members or failing to apply are rejected with 400
+func TestTaskHandler_UpdateTask_JSONPatchErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		fields []dto.FieldErrorResponse
+		detail string
+	}{
+		{
+			name: "read-only and unknown members",
+			body: `[{"op":"replace","path":"/version","value":7},{"op":"remove","path":"/status"},{"op":"add","path":"/colour","value":"red"}]`,
+			fields: []dto.FieldErrorResponse{
+				{Field: "colour", Code: validation.CodeUnknown, Message: "colour is not a known field"},
+				{Field: "status", Code: validation.CodeReadOnly, Message: "status cannot be changed"},
+				{Field: "version", Code: validation.CodeReadOnly, Message: "version cannot be changed"},
+			},
+		},
+		{
+			name:   "invalid value",
+			body:   `[{"op":"replace","path":"/title","value":null}]`,
+			fields: []dto.FieldErrorResponse{{Field: "title", Code: validation.CodeNotNull, Message: "title cannot be null"}},
+		},
+		{name: "not an array", body: `{"op":"add"}`, detail: "request body must be a JSON array of patch operations"},
+		{name: "unsupported op", body: `[{"op":"copy","from":"/title","path":"/description"}]`, detail: `operation 0: "copy" is not supported; use add, remove, replace or test`},
+		{name: "missing path", body: `[{"op":"remove","path":"/deadline/year"}]`, detail: `operation 0: there is no value at "/deadline/year"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUC := &mockTaskUsecase{
+				GetTaskFunc: func(id string) (*model.Task, error) {
+					return newTestTask(), nil
+				},
+				UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+					t.Fatal("UpdateTask must not be called")
+					return nil, nil
+				},
+			}
+			router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json-patch+json")
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var resp dto.ProblemResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Equal(t, tt.fields, resp.Errors)
+			if tt.detail != "" {
+				assert.Equal(t, tt.detail, resp.Detail)
+			}
+		})
+	}
+}
+
+// TestTaskHandler_UpdateTask_UnsupportedMediaType checks that other patch formats get 415 with Accept-Patch
+func TestTaskHandler_UpdateTask_UnsupportedMediaType(t *testing.T) {
+	// Arrange
+	handler := NewTaskHandler(&mockTaskUsecase{}, discardLogger)
+	router := setupRouter(handler)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`title=Updated`))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+	assert.Equal(t, "application/merge-patch+json, application/json-patch+json", w.Header().Get("Accept-Patch"))
+	assert.Contains(t, w.Body.String(), middleware.ProblemTypeUnsupportedMedia)
+}
+
 // TestTaskHandler_UpdateTask_NotFound checks that updating non-existent task returns not found error
 func TestTaskHandler_UpdateTask_NotFound(t *testing.T) {
 	// Arrange
@@ -278,10 +809,10 @@ func TestTaskHandler_UpdateTask_NotFound(t *testing.T) {
 			return nil, repository.ErrTaskNotFound
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
-	reqBody := dto.UpdateTaskRequest{Title: utils.Ptr("Updated")}
+	reqBody := dto.UpdateTaskRequest{Title: dto.PatchValue("Updated")}
 	body, _ := json.Marshal(reqBody)
 	w := httptest.NewRecorder()
 	req, _ := http.NewRequest("PATCH", "/tasks/1", bytes.NewReader(body))
@@ -294,15 +825,102 @@ func TestTaskHandler_UpdateTask_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+// TestTaskHandler_UpdateTask_IfMatch checks that a matching If-Match lets the update through
+// and the response carries the new ETag
+func TestTaskHandler_UpdateTask_IfMatch(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			task.Version++
+			return task, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"title":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"0", "1"`)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `"2"`, w.Header().Get("ETag"))
+}
+
+// TestTaskHandler_UpdateTask_IfMatchMismatch checks that a stale If-Match returns 412 without writing
+func TestTaskHandler_UpdateTask_IfMatchMismatch(t *testing.T) {
+	// Arrange
+	updated := false
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.Version = 3
+			return task, nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			updated = true
+			return task, nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"title":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("If-Match", `"2"`)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.Contains(t, w.Body.String(), middleware.ProblemTypePreconditionFailed)
+	assert.False(t, updated)
+}
+
+// TestTaskHandler_UpdateTask_ConcurrentWrite checks that losing a race without If-Match returns 409
+func TestTaskHandler_UpdateTask_ConcurrentWrite(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+		UpdateTaskFunc: func(task *model.Task) (*model.Task, error) {
+			return nil, repository.ErrVersionConflict
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("PATCH", "/api/tasks/1", strings.NewReader(`{"title":"Updated"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), middleware.ProblemTypeConflict)
+}
+
 // TestTaskHandler_DeleteTask_Success checks that a task is deleted successfully
 func TestTaskHandler_DeleteTask_Success(t *testing.T) {
 	// Arrange
 	mockUC := &mockTaskUsecase{
-		DeleteTaskFunc: func(id string) error {
+		DeleteTaskFunc: func(id string, version int64) error {
 			return nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
@@ -319,11 +937,11 @@ func TestTaskHandler_DeleteTask_Success(t *testing.T) {
 func TestTaskHandler_DeleteTask_NotFound(t *testing.T) {
 	// Arrange
 	mockUC := &mockTaskUsecase{
-		DeleteTaskFunc: func(id string) error {
+		DeleteTaskFunc: func(id string, version int64) error {
 			return repository.ErrTaskNotFound
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	w := httptest.NewRecorder()
@@ -336,6 +954,42 @@ func TestTaskHandler_DeleteTask_NotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+// TestTaskHandler_DeleteTask_IfMatch checks that If-Match turns into a versioned delete
+// and a mismatch is refused with 412
+func TestTaskHandler_DeleteTask_IfMatch(t *testing.T) {
+	// Arrange
+	var gotVersion int64 = -1
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			return newTestTask(), nil
+		},
+		DeleteTaskFunc: func(id string, version int64) error {
+			gotVersion = version
+			return nil
+		},
+	}
+	handler := NewTaskHandler(mockUC, discardLogger)
+	router := setupRouter(handler)
+
+	stale := httptest.NewRecorder()
+	staleReq, _ := http.NewRequest("DELETE", "/api/tasks/1", nil)
+	staleReq.Header.Set("If-Match", `W/"1"`)
+	fresh := httptest.NewRecorder()
+	freshReq, _ := http.NewRequest("DELETE", "/api/tasks/1", nil)
+	freshReq.Header.Set("If-Match", `"1"`)
+
+	// Act
+	router.ServeHTTP(stale, staleReq)
+	staleVersion := gotVersion
+	router.ServeHTTP(fresh, freshReq)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+	assert.Equal(t, int64(-1), staleVersion)
+	assert.Equal(t, http.StatusNoContent, fresh.Code)
+	assert.Equal(t, int64(1), gotVersion)
+}
+
 // TestTaskHandler_UpdateTaskStatus_Success checks that task status is updated successfully
 func TestTaskHandler_UpdateTaskStatus_Success(t *testing.T) {
 	// Arrange
@@ -349,7 +1003,7 @@ func TestTaskHandler_UpdateTaskStatus_Success(t *testing.T) {
 			return task, nil
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	reqBody := dto.UpdateTaskStatusRequest{IsCompleted: true}
@@ -376,7 +1030,7 @@ func TestTaskHandler_UpdateTaskStatus_ValidationError(t *testing.T) {
 			return nil, validation.NewValidationError("validation error")
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	reqBody := dto.UpdateTaskStatusRequest{IsCompleted: true}
@@ -400,7 +1054,7 @@ func TestTaskHandler_UpdateTaskStatus_NotFound(t *testing.T) {
 			return nil, repository.ErrTaskNotFound
 		},
 	}
-	handler := NewTaskHandler(mockUC)
+	handler := NewTaskHandler(mockUC, discardLogger)
 	router := setupRouter(handler)
 
 	reqBody := dto.UpdateTaskStatusRequest{IsCompleted: true}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"net/http"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListTrash godoc
+// @Summary     List deleted tasks
+// @Description Returns the tasks in the trash, most recently deleted first
+// @Tags        trash
+// @Produce     json
+// @Param       page       query     int     false  "Page number"
+// @Param       page_size  query     int     false  "Page size"
+// @Success     200  {object}  dto.PaginatedTasksResponse
+// @Failure     400  {object}  dto.ProblemResponse   // Invalid page
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/trash [get]
+func (h *TaskHandler) ListTrash(c *gin.Context) {
+	var query dto.ListTrashQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.Error(err)
+		return
+	}
+	if c.Query("page") == "" {
+		query.Page = 1
+	}
+	if c.Query("page_size") == "" {
+		query.PageSize = 10
+	}
+
+	tasks, total, err := h.usecase.ListDeletedTasks(c.Request.Context(), query.Page, query.PageSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.PaginatedTasksResponse{
+		Items: toTaskResponses(tasks),
+		Meta: &dto.PaginationMeta{
+			Total:      total,
+			Page:       query.Page,
+			PageSize:   query.PageSize,
+			TotalPages: (total + query.PageSize - 1) / query.PageSize,
+		},
+	})
+}
+
+// RestoreTask godoc
+// @Summary     Restore a deleted task
+// @Description Takes a task out of the trash. Its status is recalculated, so a task whose deadline passed
+// @Description while it was deleted comes back overdue.
+// @Tags        trash
+// @Produce     json
+// @Param       id        path      string  true   "Task ID"
+// @Param       If-Match  header    string  false  "ETag of the deleted task"
+// @Success     200  {object}  dto.TaskResponse
+// @Header      200  {string}  ETag  "New task version"
+// @Failure     404  {object}  dto.ProblemResponse   // The task is not in the trash
+// @Failure     409  {object}  dto.ProblemResponse   // The task changed concurrently
+// @Failure     412  {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/restore [post]
+func (h *TaskHandler) RestoreTask(c *gin.Context) {
+	id := c.Param("id")
+
+	version := repository.AnyVersion
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		deleted, err := h.usecase.GetDeletedTask(c.Request.Context(), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if !ifMatchHolds(ifMatch, deleted.Version) {
+			c.Error(repository.ErrVersionConflict)
+			return
+		}
+		version = deleted.Version
+	}
+
+	restored, err := h.usecase.RestoreTask(c.Request.Context(), id, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", taskETag(restored.Version))
+	c.JSON(http.StatusOK, toTaskResponse(restored))
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskHandler_ListTrash checks that the trash is listed with default paging and that the
+// route is not taken for a task ID
+func TestTaskHandler_ListTrash(t *testing.T) {
+	// Arrange
+	var gotPage, gotPageSize int
+	deletedAt := time.Now().UTC()
+	mockUC := &mockTaskUsecase{
+		ListDeletedTasksFunc: func(page, pageSize int) ([]*model.Task, int, error) {
+			gotPage, gotPageSize = page, pageSize
+			task := newTestTask()
+			task.DeletedAt = &deletedAt
+			return []*model.Task{task}, 11, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/tasks/trash", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, gotPage)
+	assert.Equal(t, 10, gotPageSize)
+	var resp dto.PaginatedTasksResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Items, 1)
+	require.NotNil(t, resp.Items[0].DeletedAt)
+	assert.WithinDuration(t, deletedAt, *resp.Items[0].DeletedAt, time.Second)
+	assert.Equal(t, &dto.PaginationMeta{Total: 11, Page: 1, PageSize: 10, TotalPages: 2}, resp.Meta)
+}
+
+// TestTaskHandler_RestoreTask_IfMatch checks that If-Match is compared with the deleted task and
+// that the restored task comes back with its new ETag
+func TestTaskHandler_RestoreTask_IfMatch(t *testing.T) {
+	// Arrange
+	var gotVersion int64 = -1
+	mockUC := &mockTaskUsecase{
+		GetDeletedTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.Version = 2
+			return task, nil
+		},
+		RestoreTaskFunc: func(id string, version int64) (*model.Task, error) {
+			gotVersion = version
+			task := newTestTask()
+			task.Version = 3
+			return task, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+
+	stale := httptest.NewRecorder()
+	staleReq, _ := http.NewRequest("POST", "/api/tasks/1/restore", nil)
+	staleReq.Header.Set("If-Match", `"1"`)
+	fresh := httptest.NewRecorder()
+	freshReq, _ := http.NewRequest("POST", "/api/tasks/1/restore", nil)
+	freshReq.Header.Set("If-Match", `"2"`)
+
+	// Act
+	router.ServeHTTP(stale, staleReq)
+	staleVersion := gotVersion
+	router.ServeHTTP(fresh, freshReq)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+	assert.Equal(t, int64(-1), staleVersion)
+	assert.Equal(t, http.StatusOK, fresh.Code)
+	assert.Equal(t, int64(2), gotVersion)
+	assert.Equal(t, `"3"`, fresh.Header().Get("ETag"))
+}
+
+// TestTaskHandler_RestoreTask_NotFound checks that restoring a task that is not in the trash returns 404
+func TestTaskHandler_RestoreTask_NotFound(t *testing.T) {
+	// Arrange
+	mockUC := &mockTaskUsecase{
+		RestoreTaskFunc: func(id string, version int64) (*model.Task, error) {
+			return nil, repository.ErrTaskNotFound
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/tasks/1/restore", nil)
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,52 @@
+package http
+
+import (
+	"net/http"
+	"todo/internal/domain/repository"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UndoTask godoc
+// @Summary     Undo the last change of a task
+// @Description Reverts the last update or completion change made through the API, if it was made within the
+// @Description undo window, and recalculates the status. The undo is itself recorded in the history and cannot
+// @Description be undone. Changes made by the overdue job are not undone; if one came after the client's change,
+// @Description the request fails with 409 like any other change made since.
+// @Tags        tasks
+// @Produce     json
+// @Param       id        path      string  true   "Task ID"
+// @Param       If-Match  header    string  false  "ETag of the task"
+// @Success     200  {object}  dto.TaskResponse
+// @Header      200  {string}  ETag  "New task version"
+// @Failure     404  {object}  dto.ProblemResponse   // Task not found
+// @Failure     409  {object}  dto.ProblemResponse   // The task changed since, or there is nothing to undo
+// @Failure     412  {object}  dto.ProblemResponse   // If-Match does not match the current ETag
+// @Failure     500  {object}  dto.ProblemResponse   // Internal server error
+// @Router      /api/tasks/{id}/undo [post]
+func (h *TaskHandler) UndoTask(c *gin.Context) {
+	id := c.Param("id")
+
+	version := repository.AnyVersion
+	if ifMatch := c.GetHeader("If-Match"); ifMatch != "" {
+		current, err := h.usecase.GetTask(c.Request.Context(), id)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+		if !ifMatchHolds(ifMatch, current.Version) {
+			c.Error(repository.ErrVersionConflict)
+			return
+		}
+		version = current.Version
+	}
+
+	task, err := h.usecase.UndoTask(c.Request.Context(), id, version)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("ETag", taskETag(task.Version))
+	c.JSON(http.StatusOK, toTaskResponse(task))
+}
@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"todo/internal/delivery/http/dto"
+	"todo/internal/delivery/http/middleware"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTaskHandler_UndoTask checks that the reverted task comes back with its new ETag and that
+// If-Match is compared with the current task
+func TestTaskHandler_UndoTask(t *testing.T) {
+	// Arrange
+	var gotVersion int64 = -1
+	mockUC := &mockTaskUsecase{
+		GetTaskFunc: func(id string) (*model.Task, error) {
+			task := newTestTask()
+			task.Version = 2
+			return task, nil
+		},
+		UndoTaskFunc: func(id string, version int64) (*model.Task, error) {
+			gotVersion = version
+			task := newTestTask()
+			task.Version = 3
+			return task, nil
+		},
+	}
+	router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+
+	stale := httptest.NewRecorder()
+	staleReq, _ := http.NewRequest("POST", "/api/tasks/1/undo", nil)
+	staleReq.Header.Set("If-Match", `"1"`)
+	fresh := httptest.NewRecorder()
+	freshReq, _ := http.NewRequest("POST", "/api/tasks/1/undo", nil)
+	freshReq.Header.Set("If-Match", `"2"`)
+
+	// Act
+	router.ServeHTTP(stale, staleReq)
+	staleVersion := gotVersion
+	router.ServeHTTP(fresh, freshReq)
+
+	// Assert
+	assert.Equal(t, http.StatusPreconditionFailed, stale.Code)
+	assert.Equal(t, int64(-1), staleVersion)
+	require.Equal(t, http.StatusOK, fresh.Code)
+	assert.Equal(t, int64(2), gotVersion)
+	assert.Equal(t, `"3"`, fresh.Header().Get("ETag"))
+}
+
+// TestTaskHandler_UndoTask_Refused checks that every reason to refuse an undo is reported as 409
+func TestTaskHandler_UndoTask_Refused(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		problemType string
+	}{
+		{"changed since", repository.ErrVersionConflict, middleware.ProblemTypeConflict},
+		{"nothing to undo", usecase.ErrNothingToUndo, middleware.ProblemTypeUndoUnavailable},
+		{"window expired", usecase.ErrUndoExpired, middleware.ProblemTypeUndoUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockUC := &mockTaskUsecase{
+				UndoTaskFunc: func(id string, version int64) (*model.Task, error) {
+					return nil, tt.err
+				},
+			}
+			router := setupRouter(NewTaskHandler(mockUC, discardLogger))
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/api/tasks/1/undo", nil)
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusConflict, w.Code)
+			var problem dto.ProblemResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &problem))
+			assert.Equal(t, tt.problemType, problem.Type)
+			assert.NotEmpty(t, problem.Detail)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package model
+
+// TaskOperationKind names what a bulk operation does to a task.
+type TaskOperationKind string
+
+const (
+	OperationCreate        TaskOperationKind = "create"
+	OperationUpdate        TaskOperationKind = "update"
+	OperationSetCompletion TaskOperationKind = "set_completion"
+	OperationDelete        TaskOperationKind = "delete"
+)
+
+// TaskOperation is one step of a bulk request. A create stores Task. The
+// other kinds target the task with ID; a non-zero Version must match the
+// stored one. An update passes the stored task to Apply, which changes it in
+// place, and a completion change sets IsCompleted.
+type TaskOperation struct {
+	Kind        TaskOperationKind
+	ID          string
+	Version     int64
+	Task        *Task
+	Apply       func(task *Task)
+	IsCompleted bool
+}
+
+// TaskOperationResult is the outcome of one TaskOperation. Task is the task
+// as stored afterwards and is nil for deletes and failed operations.
+type TaskOperationResult struct {
+	Task *Task
+	Err  error
+}
+
+// TaskRef points at a task at a given version.
+type TaskRef struct {
+	ID      string
+	Version int64
+}
+
+// TaskBatch groups the writes of one bulk request by kind.
+type TaskBatch struct {
+	Creates []*Task
+	Updates []*Task
+	Deletes []TaskRef
+}
+
+// TaskBatchErrors holds the outcome of every write in a TaskBatch, index for
+// index. A nil entry is a write that succeeded.
+type TaskBatchErrors struct {
+	Creates []error
+	Updates []error
+	Deletes []error
+}
+
+// Failed reports whether any write failed.
+func (e TaskBatchErrors) Failed() bool {
+	for _, errs := range [][]error{e.Creates, e.Updates, e.Deletes} {
+		for _, err := range errs {
+			if err != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// IdempotencyRecord remembers a request sent with an Idempotency-Key and the
+// response it produced. Fingerprint identifies the request so that a key
+// reused for a different one can be told apart from a retry. A record whose
+// StatusCode is 0 belongs to a request that is still running.
+type IdempotencyRecord struct {
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	Header      map[string]string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
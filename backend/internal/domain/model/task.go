@@ -1,6 +1,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,16 @@ const (
 	PriorityCritical TaskPriority = "CRITICAL"
 )
 
+// IsValid reports whether p is one of the known priorities.
+func (p TaskPriority) IsValid() bool {
+	switch p {
+	case PriorityLow, PriorityMedium, PriorityHigh, PriorityCritical:
+		return true
+	default:
+		return false
+	}
+}
+
 type TaskFilter struct {
 	Status    string
 	Priority  string
@@ -31,14 +42,68 @@ type TaskFilter struct {
 	PageSize  int
 }
 
+// IsDescending reports the effective sort direction: without a known SortBy
+// tasks are listed newest first.
+func (f *TaskFilter) IsDescending() bool {
+	switch f.SortBy {
+	case "deadline", "created_at", "priority":
+		return strings.EqualFold(f.SortOrder, "desc")
+	default:
+		return true
+	}
+}
+
+// TaskCursor holds the sort key and id of the last task on a keyset page.
+// Only the field matching the active sort is meaningful.
+type TaskCursor struct {
+	Deadline  *time.Time
+	CreatedAt time.Time
+	Priority  TaskPriority
+	ID        string
+}
+
+// TaskCount is the number of tasks sharing a status and priority.
+type TaskCount struct {
+	Status   TaskStatus
+	Priority TaskPriority
+	Count    int
+}
+
+// TaskProgress counts the subtasks of a task and how many of them are done.
+type TaskProgress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// CompletionRule decides whether a task with open subtasks may be completed.
+type CompletionRule string
+
+const (
+	// CompletionIndependent lets tasks be completed regardless of their
+	// subtasks.
+	CompletionIndependent CompletionRule = "independent"
+	// CompletionRequireSubtasks lets a task be completed only once all of its
+	// subtasks are.
+	CompletionRequireSubtasks CompletionRule = "require_subtasks"
+)
+
+// Task is a single to-do item. Version starts at 1 and is bumped by the
+// repository on every update, so a write can be tied to the state it was
+// based on. DeletedAt is set while the task is in the trash. ParentID is set
+// for a subtask and never changes once it is stored. Progress is not stored:
+// the usecase fills it in for tasks that have subtasks.
 type Task struct {
-	ID          string       `json:"id"`
-	Title       string       `json:"title"`
-	Description *string      `json:"description"`
-	Deadline    *time.Time   `json:"deadline"`
-	Status      TaskStatus   `json:"status"`
-	Priority    TaskPriority `json:"priority"`
-	CreatedAt   time.Time    `json:"created_at"`
-	UpdatedAt   *time.Time   `json:"updated_at"`
-	IsCompleted bool         `json:"is_completed"`
+	ID          string        `json:"id"`
+	Title       string        `json:"title"`
+	Description *string       `json:"description"`
+	Deadline    *time.Time    `json:"deadline"`
+	Status      TaskStatus    `json:"status"`
+	Priority    TaskPriority  `json:"priority"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at"`
+	IsCompleted bool          `json:"is_completed"`
+	Version     int64         `json:"version"`
+	DeletedAt   *time.Time    `json:"deleted_at,omitempty"`
+	ParentID    *string       `json:"parent_id,omitempty"`
+	Progress    *TaskProgress `json:"progress,omitempty"`
 }
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type TaskEventType string
+
+const (
+	TaskEventCreated       TaskEventType = "CREATED"
+	TaskEventUpdated       TaskEventType = "UPDATED"
+	TaskEventStatusChanged TaskEventType = "STATUS_CHANGED"
+	TaskEventDeleted       TaskEventType = "DELETED"
+	TaskEventRestored      TaskEventType = "RESTORED"
+	TaskEventUndone        TaskEventType = "UNDONE"
+)
+
+// TaskEventOrigin tells what made a change: a client request, a scheduled
+// job, or a client request whose values came from title macros.
+type TaskEventOrigin string
+
+const (
+	OriginAPI   TaskEventOrigin = "API"
+	OriginCron  TaskEventOrigin = "CRON"
+	OriginMacro TaskEventOrigin = "MACRO"
+)
+
+// TaskFieldChange is the JSON value of a task field before and after a
+// change. Old is null for a task that was just created.
+type TaskFieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+// TaskEvent is one entry of a task's history. Version is the task version the
+// change produced and RequestID the request that made it, if any. IDs grow
+// with every event, so they order the history.
+type TaskEvent struct {
+	ID        int64
+	TaskID    string
+	Type      TaskEventType
+	Origin    TaskEventOrigin
+	Version   int64
+	Changes   []TaskFieldChange
+	RequestID string
+	CreatedAt time.Time
+}
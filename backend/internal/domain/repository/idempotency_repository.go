@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo/internal/domain/model"
+)
+
+// ErrIdempotencyKeyNotFound is returned when no unexpired record holds the key,
+// and by Update and Delete when the claim they were given is no longer held.
+var ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+
+// ErrIdempotencyKeyExists is returned by Create when an unexpired record
+// already holds the key.
+var ErrIdempotencyKeyExists = errors.New("idempotency key already exists")
+
+// IdempotencyRepository stores idempotency records. A record is live until its
+// ExpiresAt; expired records are ignored and may be replaced before they are
+// purged.
+type IdempotencyRepository interface {
+	// Create claims record.Key. It replaces an expired record for the same
+	// key and returns ErrIdempotencyKeyExists if a live one holds it.
+	Create(ctx context.Context, record *model.IdempotencyRecord) error
+	// Find returns the record for key unless it expired at or before now.
+	Find(ctx context.Context, key string, now time.Time) (*model.IdempotencyRecord, error)
+	// Update stores the response and expiry of the claim on record.Key made at
+	// record.CreatedAt.
+	Update(ctx context.Context, record *model.IdempotencyRecord) error
+	// Delete releases the claim on key made at createdAt so the request can be
+	// tried again.
+	Delete(ctx context.Context, key string, createdAt time.Time) error
+	// DeleteExpired removes the records that expired at or before now and
+	// returns how many there were.
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
@@ -0,0 +1,18 @@
+package repository
+
+import (
+	"context"
+	"todo/internal/domain/model"
+)
+
+// TaskEventRepository stores the history of tasks. Events are only ever
+// appended; they are removed together with their task when it is purged from
+// the trash.
+type TaskEventRepository interface {
+	// Append stores events in order and sets their IDs.
+	Append(ctx context.Context, events ...*model.TaskEvent) error
+	// FindByTaskID returns the history of a task, oldest first.
+	FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error)
+	// DeleteByTaskIDs removes the history of the given tasks.
+	DeleteByTaskIDs(ctx context.Context, taskIDs []string) error
+}
@@ -1,16 +1,85 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"time"
 	"todo/internal/domain/model"
 )
 
 var ErrTaskNotFound = errors.New("task not found")
 
+// ErrTaskExists is returned by Create when a task with the same ID is
+// already stored.
+var ErrTaskExists = errors.New("task already exists")
+
+// ErrVersionConflict is returned when a write targets a task version that is
+// no longer current because another writer got there first.
+var ErrVersionConflict = errors.New("task was modified concurrently")
+
+// AnyVersion passed to Delete removes the task regardless of its version.
+const AnyVersion int64 = 0
+
+// TaskRepository stores tasks. Deleted tasks stay in the trash until they are
+// purged; apart from the trash methods, every method treats them as absent.
 type TaskRepository interface {
-	Create(task *model.Task) error
-	Update(task *model.Task) error
-	Delete(id string) error
-	FindByID(id string) (*model.Task, error)
-	FindAll() ([]*model.Task, error)
+	// Create stores the task at version 1 and sets task.Version accordingly.
+	// It returns ErrTaskExists if the ID is taken.
+	Create(ctx context.Context, task *model.Task) error
+	// Update writes the task only if its stored version still equals
+	// task.Version and returns ErrVersionConflict otherwise. On success
+	// task.Version holds the new version.
+	Update(ctx context.Context, task *model.Task) error
+	// Delete moves the task to the trash if its stored version equals
+	// version, or unconditionally when version is AnyVersion. It sets
+	// DeletedAt and bumps the version, so writes based on the task fail.
+	Delete(ctx context.Context, id string, version int64) error
+	// CreateMany stores the tasks with a single statement, each at version 1.
+	// The returned errors line up with tasks: ErrTaskExists marks a taken ID
+	// and nil a stored task. IDs must be distinct.
+	CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error)
+	// UpdateMany writes the tasks with a single statement under the same
+	// version rule as Update. The returned errors line up with tasks and are
+	// ErrTaskNotFound, ErrVersionConflict or nil. IDs must be distinct.
+	UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error)
+	// DeleteMany moves the referenced tasks to the trash with a single
+	// statement under the same version rule as Delete. The returned errors line up with refs.
+	DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error)
+	FindByID(ctx context.Context, id string) (*model.Task, error)
+	// FindByIDs returns the stored tasks among ids, in no particular order.
+	FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error)
+	FindAll(ctx context.Context) ([]*model.Task, error)
+	// FindWithFilter returns one page of tasks matching the filter together with
+	// the total number of matching tasks. The filter is expected to be validated.
+	// Like FindAfter it lists top-level tasks only; subtasks are found with
+	// FindSubtasks.
+	FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error)
+	// FindAfter returns up to limit tasks matching the filter that sort strictly
+	// after the cursor. A nil cursor starts from the beginning.
+	FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error)
+	// CountByStatusAndPriority returns the number of tasks for every status and
+	// priority pair that has at least one task.
+	CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error)
+	// FindDeletedByID returns a task in the trash, or ErrTaskNotFound.
+	FindDeletedByID(ctx context.Context, id string) (*model.Task, error)
+	// FindDeleted returns up to limit tasks in the trash, most recently
+	// deleted first, together with the total number of tasks in the trash.
+	FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error)
+	// Restore takes the task out of the trash and writes its status,
+	// UpdatedAt and ParentID under the same version rule as Update; ParentID
+	// may only be cleared, for a subtask whose parent is gone. It returns
+	// ErrTaskNotFound if the task is not in the trash.
+	Restore(ctx context.Context, task *model.Task) error
+	// FindSubtasks returns the subtasks of a task, oldest first.
+	FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error)
+	// CountSubtasks returns the progress of every task among parentIDs that
+	// has subtasks.
+	CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error)
+	// PurgeDeleted permanently removes the tasks deleted before the given
+	// time and returns their IDs.
+	PurgeDeleted(ctx context.Context, before time.Time) ([]string, error)
+	// MarkOverdue moves every active, not completed task whose deadline is
+	// before now to OVERDUE with a single statement. It sets UpdatedAt to now,
+	// bumps the versions and returns the changed tasks in no particular order.
+	MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error)
 }
@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrRollback may be returned by a unit of work function to roll the
+// transaction back without reporting a failure: WithTx then returns nil.
+var ErrRollback = errors.New("transaction rolled back")
+
+// Repositories are the repositories available inside a unit of work. All of
+// them share the same transaction.
+type Repositories struct {
+	Tasks  TaskRepository
+	Events TaskEventRepository
+}
+
+// UnitOfWork runs a group of repository calls as one transaction.
+type UnitOfWork interface {
+	// WithTx calls fn with repositories bound to a new transaction, commits
+	// when fn returns nil and rolls back otherwise, returning fn's error.
+	// A transaction that fails because of a concurrent one may be retried, so
+	// fn can run more than once and must not have effects outside repos.
+	WithTx(ctx context.Context, fn func(repos Repositories) error) error
+}
@@ -1,15 +1,80 @@
 package usecase
 
 import (
+	"context"
+	"errors"
+	"time"
+
 	"todo/internal/domain/model"
 )
 
+// ErrBatchAborted marks the operations of an atomic batch that were rolled
+// back, or never tried, because another operation failed.
+var ErrBatchAborted = errors.New("operation aborted because another operation in the batch failed")
+
+// ErrNothingToUndo means that the last change a client made to a task cannot
+// be undone, or that there is none.
+var ErrNothingToUndo = errors.New("no change to undo")
+
+// ErrUndoExpired means that the last change a client made to a task is older
+// than the undo window.
+var ErrUndoExpired = errors.New("change is too old to undo")
+
+// ErrSubtaskDepthExceeded means that a subtask would be nested deeper than
+// the configured limit.
+var ErrSubtaskDepthExceeded = errors.New("subtask would be nested too deep")
+
+// ErrSubtasksOpen means that a task cannot be completed while some of its
+// subtasks are open.
+var ErrSubtasksOpen = errors.New("task has open subtasks")
+
+// ErrTaskHasSubtasks means that a task cannot be deleted while it has
+// subtasks.
+var ErrTaskHasSubtasks = errors.New("task has subtasks")
+
 type TaskUsecase interface {
-	CreateTask(task *model.Task) (*model.Task, error)
-	UpdateTask(task *model.Task) (*model.Task, error)
-	DeleteTask(id string) error
-	GetTask(id string) (*model.Task, error)
-	ListTasksWithFilter(filter *model.TaskFilter) ([]*model.Task, int, error)
-	SetTaskCompletion(task *model.Task) (*model.Task, error)
-	UpdateOverdueTasks() error
+	CreateTask(ctx context.Context, task *model.Task) (*model.Task, error)
+	UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error)
+	// DeleteTask moves the task at the given version to the trash;
+	// repository.AnyVersion skips the check. Tasks with subtasks are refused
+	// with ErrTaskHasSubtasks.
+	DeleteTask(ctx context.Context, id string, version int64) error
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	ListTasksWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error)
+	ListTasksByCursor(ctx context.Context, filter *model.TaskFilter, cursor string) ([]*model.Task, string, error)
+	SetTaskCompletion(ctx context.Context, task *model.Task) (*model.Task, error)
+	// ApplyBatch runs the operations with one bulk write per kind and returns
+	// a result for each, in order. When atomic is set either every operation
+	// takes effect or none does, and the ones that did not fail themselves
+	// report ErrBatchAborted. The error is for failures of the batch as a
+	// whole, such as a lost database connection.
+	ApplyBatch(ctx context.Context, ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error)
+	// GetDeletedTask returns a task in the trash.
+	GetDeletedTask(ctx context.Context, id string) (*model.Task, error)
+	// ListDeletedTasks returns one page of the trash, most recently deleted
+	// first, together with the number of tasks in it.
+	ListDeletedTasks(ctx context.Context, page, pageSize int) ([]*model.Task, int, error)
+	// RestoreTask takes the task at the given version out of the trash and
+	// recalculates its status; repository.AnyVersion skips the check.
+	RestoreTask(ctx context.Context, id string, version int64) (*model.Task, error)
+	// PurgeDeletedTasks permanently removes the tasks that have been in the
+	// trash for longer than retention and returns how many there were.
+	PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int64, error)
+	// UndoTask reverts the last update or completion change a client made to
+	// the task at the given version and recalculates its status;
+	// repository.AnyVersion skips the check. It fails with
+	// repository.ErrVersionConflict if the task changed since.
+	UndoTask(ctx context.Context, id string, version int64) (*model.Task, error)
+	// CreateSubtask creates task as a subtask of the task parentID. It fails
+	// with ErrSubtaskDepthExceeded if the parent is already nested as deep as
+	// subtasks may go.
+	CreateSubtask(ctx context.Context, parentID string, task *model.Task) (*model.Task, error)
+	// ListSubtasks returns the subtasks of a task, oldest first.
+	ListSubtasks(ctx context.Context, parentID string) ([]*model.Task, error)
+	// GetTaskHistory returns the changes of a task, oldest first. Deleted
+	// tasks keep their history until they are purged.
+	GetTaskHistory(ctx context.Context, id string) ([]*model.TaskEvent, error)
+	// UpdateOverdueTasks marks active tasks past their deadline as overdue and
+	// returns how many it changed.
+	UpdateOverdueTasks(ctx context.Context) (int, error)
 }
@@ -0,0 +1,77 @@
+package i18n
+
+var english = map[string]string{
+	TitleTooShort:   "title must be at least %d characters",
+	DeadlineInPast:  "deadline cannot be in the past",
+	StatusInvalid:   "invalid task status",
+	PriorityInvalid: "invalid task priority",
+
+	PageTooSmall:       "page must be greater than 0",
+	PageSizeTooSmall:   "page_size must be greater than 0",
+	SortByInvalid:      "invalid sort_by field",
+	SortOrderInvalid:   "invalid sort_order value",
+	CursorInvalid:      "invalid cursor",
+	CursorSortMismatch: "cursor does not match sort parameters",
+
+	FieldRequired:  "%s is required",
+	FieldMin:       "%s must be at least %s",
+	FieldMinLength: "%s must be at least %s characters",
+	FieldMax:       "%s must be at most %s",
+	FieldMaxLength: "%s must be at most %s characters",
+	FieldOneOf:     "%s must be one of %s",
+	FieldWrongType: "%s must be a %s",
+	FieldInvalid:   "%s is invalid",
+	FieldNotNull:   "%s cannot be null",
+	FieldUnknown:   "%s is not a known field",
+	FieldDateTime:  "%s must be a date-time in RFC 3339 format",
+	FieldReadOnly:  "%s cannot be changed",
+	FieldUUID:      "%s must be a UUID v4 or v7 in lowercase hyphenated form",
+	FieldMismatch:  "%s does not match the URL",
+
+	BodyInvalidJSON:      "request body is not valid JSON at offset %d",
+	BodyEmpty:            "request body is empty or truncated",
+	BodyNotObject:        "request body must be a JSON object",
+	TimeFormat:           "time values must be in RFC 3339 format",
+	MediaTypeUnsupported: "Content-Type %q is not supported here",
+	NumberInvalid:        "%q is not a valid number",
+
+	PatchNotArray:      "request body must be a JSON array of patch operations",
+	PatchOpUnsupported: "operation %d: %q is not supported; use add, remove, replace or test",
+	PatchPathInvalid:   "operation %d: %q is not a valid JSON Pointer for this document",
+	PatchPathNotFound:  "operation %d: there is no value at %q",
+	PatchValueMissing:  "operation %d: value is required",
+	PatchTestFailed:    "operation %d: the value at %q does not match",
+
+	IdempotencyKeyReused: "Idempotency-Key was already used for a different request; use a new key",
+	RequestInProgress:    "a request with the same Idempotency-Key is still being processed; retry later",
+
+	BatchDuplicateTask: "task %s appears in more than one operation",
+	BatchOpUnsupported: "unsupported operation %q",
+	BatchTaskExists:    "a task with this id already exists",
+	BatchAborted:       "not applied because another operation in the batch failed",
+
+	TaskNotFound:                "task not found",
+	TaskModified:                "the task was changed by another request; fetch it again and retry",
+	TaskExists:                  "a task with this id already exists; see Location",
+	TaskPreconditionFailed:      "the task no longer matches If-Match; fetch it again to get the current ETag",
+	TaskUndoNothing:             "the task has no update or completion change to undo",
+	TaskUndoExpired:             "the last change of the task is too old to undo",
+	TaskSubtaskTooDeep:          "subtasks cannot be nested this deep",
+	TaskSubtasksOpen:            "the task has open subtasks; complete them first",
+	TaskHasSubtasks:             "the task has subtasks; delete them first",
+	ProblemValidation:           "Validation failed",
+	ProblemMalformed:            "Malformed request",
+	ProblemNotFound:             "Task not found",
+	ProblemUnsupportedMedia:     "Unsupported media type",
+	ProblemConflict:             "Conflicting update",
+	ProblemAlreadyExists:        "Task already exists",
+	ProblemPatchTestFailed:      "Patch test failed",
+	ProblemRequestInProgress:    "Request in progress",
+	ProblemIdempotencyKeyReused: "Idempotency key reused",
+	ProblemPreconditionFailed:   "Precondition failed",
+	ProblemBatchAborted:         "Operation aborted",
+	ProblemUndoUnavailable:      "Nothing to undo",
+	ProblemSubtaskRule:          "Subtask rule violated",
+	ProblemTimeout:              "Request timed out",
+	ProblemInternal:             "Internal server error",
+}
@@ -0,0 +1,52 @@
+package i18n
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+// Locale identifies a message catalog.
+type Locale = language.Tag
+
+// Shipped locales.
+var (
+	English = language.English
+	Russian = language.Russian
+)
+
+// Default is the locale used when a client asks for none we ship, and for
+// messages written to logs.
+var Default = English
+
+// catalogs maps a locale to its messages. Messages are fmt formats; every
+// translation of a message takes the same arguments in the same order.
+var catalogs = map[Locale]map[string]string{
+	English: english,
+	Russian: russian,
+}
+
+// supported lists the locales in catalogs, Default first so the matcher falls
+// back to it.
+var supported = []Locale{English, Russian}
+
+var matcher = language.NewMatcher(supported)
+
+// Match picks the best shipped locale for an Accept-Language header value.
+func Match(acceptLanguage string) Locale {
+	_, index := language.MatchStrings(matcher, acceptLanguage)
+	return supported[index]
+}
+
+// Translate renders message id in locale. Messages missing from the locale
+// fall back to Default, and unknown ids are returned as is.
+func Translate(locale Locale, id string, args ...any) string {
+	format, ok := catalogs[locale][id]
+	if !ok {
+		format, ok = catalogs[Default][id]
+	}
+	if !ok {
+		return id
+	}
+	return fmt.Sprintf(format, args...)
+}
@@ -0,0 +1,66 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+var formatVerb = regexp.MustCompile(`%[-+# 0]*\d*(?:\.\d+)?[a-zA-Z]`)
+
+// TestCatalogs_TranslateEveryMessage checks that each shipped locale has every message id and no unknown ones
+func TestCatalogs_TranslateEveryMessage(t *testing.T) {
+	for _, locale := range supported {
+		t.Run(locale.String(), func(t *testing.T) {
+			catalog := catalogs[locale]
+			for _, id := range All {
+				assert.NotEmpty(t, catalog[id], "missing translation for %s", id)
+			}
+			assert.Len(t, catalog, len(All), "catalog has ids that are not listed in All")
+		})
+	}
+}
+
+// TestCatalogs_SameArguments checks that translations take the same format arguments as English
+func TestCatalogs_SameArguments(t *testing.T) {
+	for _, locale := range supported {
+		for _, id := range All {
+			assert.Equal(t,
+				formatVerb.FindAllString(catalogs[Default][id], -1),
+				formatVerb.FindAllString(catalogs[locale][id], -1),
+				"%s: %s arguments differ from %s", id, locale, Default)
+		}
+	}
+}
+
+// TestMatch checks that Accept-Language picks the closest shipped locale
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Locale
+	}{
+		{header: "", want: English},
+		{header: "ru", want: Russian},
+		{header: "ru-RU,ru;q=0.9,en;q=0.8", want: Russian},
+		{header: "en-US,en;q=0.9", want: English},
+		{header: "de-DE", want: English},
+		{header: "de;q=0.9, ru;q=0.5", want: Russian},
+		{header: "en;q=0.3, ru;q=0.7", want: Russian},
+		{header: "not a language", want: English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.header))
+		})
+	}
+}
+
+// TestTranslate checks formatting and the fallbacks for missing messages
+func TestTranslate(t *testing.T) {
+	assert.Equal(t, "название должно содержать не менее 4 символов", Translate(Russian, TitleTooShort, 4))
+	assert.Equal(t, "title must be at least 4 characters", Translate(English, TitleTooShort, 4))
+	assert.Equal(t, "task not found", Translate(language.German, TaskNotFound))
+	assert.Equal(t, "no.such.message", Translate(Russian, "no.such.message"))
+}
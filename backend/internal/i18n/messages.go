@@ -0,0 +1,101 @@
+package i18n
+
+// Message ids. They are stable: clients never see them, but catalogs and
+// callers are keyed by them.
+const (
+	// Task fields.
+	TitleTooShort   = "task.title.too_short"
+	DeadlineInPast  = "task.deadline.in_past"
+	StatusInvalid   = "task.status.invalid"
+	PriorityInvalid = "task.priority.invalid"
+
+	// Listing parameters.
+	PageTooSmall       = "list.page.too_small"
+	PageSizeTooSmall   = "list.page_size.too_small"
+	SortByInvalid      = "list.sort_by.invalid"
+	SortOrderInvalid   = "list.sort_order.invalid"
+	CursorInvalid      = "list.cursor.invalid"
+	CursorSortMismatch = "list.cursor.sort_mismatch"
+
+	// Request binding rules; the first argument is the field name.
+	FieldRequired  = "field.required"
+	FieldMin       = "field.min"
+	FieldMinLength = "field.min_length"
+	FieldMax       = "field.max"
+	FieldMaxLength = "field.max_length"
+	FieldOneOf     = "field.oneof"
+	FieldWrongType = "field.wrong_type"
+	FieldInvalid   = "field.invalid"
+	FieldNotNull   = "field.not_null"
+	FieldUnknown   = "field.unknown"
+	FieldDateTime  = "field.date_time"
+	FieldReadOnly  = "field.read_only"
+	FieldUUID      = "field.uuid"
+	FieldMismatch  = "field.mismatch"
+
+	// Requests that cannot be decoded.
+	BodyInvalidJSON      = "request.invalid_json"
+	BodyEmpty            = "request.empty_body"
+	BodyNotObject        = "request.not_object"
+	TimeFormat           = "request.time_format"
+	MediaTypeUnsupported = "request.media_type_unsupported"
+	NumberInvalid        = "request.number_invalid"
+
+	// JSON Patch operations; the first argument is the operation index.
+	PatchNotArray      = "patch.not_array"
+	PatchOpUnsupported = "patch.op_unsupported"
+	PatchPathInvalid   = "patch.path_invalid"
+	PatchPathNotFound  = "patch.path_not_found"
+	PatchValueMissing  = "patch.value_missing"
+	PatchTestFailed    = "patch.test_failed"
+
+	IdempotencyKeyReused = "request.idempotency_key_reused"
+	RequestInProgress    = "request.in_progress"
+
+	// Bulk operations.
+	BatchDuplicateTask = "batch.duplicate_task"
+	BatchOpUnsupported = "batch.op_unsupported"
+	BatchTaskExists    = "batch.task_exists"
+	BatchAborted       = "batch.aborted"
+
+	// Problem titles and details.
+	TaskNotFound                = "task.not_found"
+	TaskModified                = "task.modified"
+	TaskExists                  = "task.exists"
+	TaskPreconditionFailed      = "task.precondition_failed"
+	TaskUndoNothing             = "task.undo.nothing"
+	TaskUndoExpired             = "task.undo.expired"
+	TaskSubtaskTooDeep          = "task.subtask.too_deep"
+	TaskSubtasksOpen            = "task.subtask.open"
+	TaskHasSubtasks             = "task.subtask.exists"
+	ProblemValidation           = "problem.validation"
+	ProblemMalformed            = "problem.malformed"
+	ProblemNotFound             = "problem.not_found"
+	ProblemUnsupportedMedia     = "problem.unsupported_media_type"
+	ProblemConflict             = "problem.conflict"
+	ProblemAlreadyExists        = "problem.already_exists"
+	ProblemPatchTestFailed      = "problem.patch_test_failed"
+	ProblemRequestInProgress    = "problem.request_in_progress"
+	ProblemIdempotencyKeyReused = "problem.idempotency_key_reused"
+	ProblemPreconditionFailed   = "problem.precondition_failed"
+	ProblemBatchAborted         = "problem.batch_aborted"
+	ProblemUndoUnavailable      = "problem.undo_unavailable"
+	ProblemSubtaskRule          = "problem.subtask_rule"
+	ProblemTimeout              = "problem.timeout"
+	ProblemInternal             = "problem.internal"
+)
+
+// All lists every message id; each catalog must translate all of them.
+var All = []string{
+	TitleTooShort, DeadlineInPast, StatusInvalid, PriorityInvalid,
+	PageTooSmall, PageSizeTooSmall, SortByInvalid, SortOrderInvalid, CursorInvalid, CursorSortMismatch,
+	FieldRequired, FieldMin, FieldMinLength, FieldMax, FieldMaxLength, FieldOneOf, FieldWrongType, FieldInvalid,
+	FieldNotNull, FieldUnknown, FieldDateTime, FieldReadOnly, FieldUUID, FieldMismatch,
+	BodyInvalidJSON, BodyEmpty, BodyNotObject, TimeFormat, MediaTypeUnsupported, NumberInvalid,
+	PatchNotArray, PatchOpUnsupported, PatchPathInvalid, PatchPathNotFound, PatchValueMissing, PatchTestFailed,
+	IdempotencyKeyReused, RequestInProgress,
+	BatchDuplicateTask, BatchOpUnsupported, BatchTaskExists, BatchAborted,
+	TaskNotFound, TaskExists, TaskModified, TaskPreconditionFailed, TaskUndoNothing, TaskUndoExpired,
+	TaskSubtaskTooDeep, TaskSubtasksOpen, TaskHasSubtasks,
+	ProblemValidation, ProblemMalformed, ProblemNotFound, ProblemUnsupportedMedia, ProblemConflict, ProblemAlreadyExists, ProblemPatchTestFailed, ProblemRequestInProgress, ProblemIdempotencyKeyReused, ProblemPreconditionFailed, ProblemBatchAborted, ProblemUndoUnavailable, ProblemSubtaskRule, ProblemTimeout, ProblemInternal,
+}
@@ -0,0 +1,77 @@
+package i18n
+
+var russian = map[string]string{
+	TitleTooShort:   "название должно содержать не менее %d символов",
+	DeadlineInPast:  "срок не может быть в прошлом",
+	StatusInvalid:   "недопустимый статус задачи",
+	PriorityInvalid: "недопустимый приоритет задачи",
+
+	PageTooSmall:       "номер страницы должен быть больше 0",
+	PageSizeTooSmall:   "размер страницы должен быть больше 0",
+	SortByInvalid:      "недопустимое поле сортировки sort_by",
+	SortOrderInvalid:   "недопустимый порядок сортировки sort_order",
+	CursorInvalid:      "недопустимый курсор",
+	CursorSortMismatch: "курсор не соответствует параметрам сортировки",
+
+	FieldRequired:  "поле %s обязательно",
+	FieldMin:       "поле %s должно быть не меньше %s",
+	FieldMinLength: "поле %s должно содержать не менее %s символов",
+	FieldMax:       "поле %s должно быть не больше %s",
+	FieldMaxLength: "поле %s должно содержать не более %s символов",
+	FieldOneOf:     "поле %s должно принимать одно из значений: %s",
+	FieldWrongType: "поле %s должно иметь тип %s",
+	FieldInvalid:   "поле %s заполнено неверно",
+	FieldNotNull:   "поле %s не может быть null",
+	FieldUnknown:   "неизвестное поле %s",
+	FieldDateTime:  "поле %s должно содержать дату и время в формате RFC 3339",
+	FieldReadOnly:  "поле %s нельзя изменить",
+	FieldUUID:      "поле %s должно содержать UUID версии 4 или 7 в нижнем регистре с дефисами",
+	FieldMismatch:  "поле %s не совпадает с URL",
+
+	BodyInvalidJSON:      "тело запроса не является корректным JSON (позиция %d)",
+	BodyEmpty:            "тело запроса пустое или обрезано",
+	BodyNotObject:        "тело запроса должно быть JSON-объектом",
+	TimeFormat:           "время должно быть в формате RFC 3339",
+	MediaTypeUnsupported: "Content-Type %q здесь не поддерживается",
+	NumberInvalid:        "%q не является числом",
+
+	PatchNotArray:      "тело запроса должно быть JSON-массивом операций",
+	PatchOpUnsupported: "операция %d: %q не поддерживается; используйте add, remove, replace или test",
+	PatchPathInvalid:   "операция %d: %q не является корректным JSON Pointer для этого документа",
+	PatchPathNotFound:  "операция %d: по пути %q нет значения",
+	PatchValueMissing:  "операция %d: не указано value",
+	PatchTestFailed:    "операция %d: значение по пути %q не совпадает",
+
+	IdempotencyKeyReused: "Idempotency-Key уже использован для другого запроса; используйте новый ключ",
+	RequestInProgress:    "запрос с тем же Idempotency-Key ещё выполняется; повторите позже",
+
+	BatchDuplicateTask: "задача %s встречается в нескольких операциях",
+	BatchOpUnsupported: "операция %q не поддерживается",
+	BatchTaskExists:    "задача с таким id уже существует",
+	BatchAborted:       "не выполнено, потому что другая операция пакета завершилась ошибкой",
+
+	TaskNotFound:                "задача не найдена",
+	TaskModified:                "задача была изменена другим запросом; получите её заново и повторите",
+	TaskExists:                  "задача с таким id уже существует; см. Location",
+	TaskPreconditionFailed:      "задача не соответствует If-Match; получите её заново, чтобы узнать текущий ETag",
+	TaskUndoNothing:             "у задачи нет изменения или смены статуса, которые можно отменить",
+	TaskUndoExpired:             "последнее изменение задачи слишком давнее, чтобы его отменить",
+	TaskSubtaskTooDeep:          "подзадачи нельзя вкладывать так глубоко",
+	TaskSubtasksOpen:            "у задачи есть незавершённые подзадачи; сначала завершите их",
+	TaskHasSubtasks:             "у задачи есть подзадачи; сначала удалите их",
+	ProblemValidation:           "Ошибка валидации",
+	ProblemMalformed:            "Некорректный запрос",
+	ProblemNotFound:             "Задача не найдена",
+	ProblemUnsupportedMedia:     "Неподдерживаемый тип содержимого",
+	ProblemConflict:             "Конфликт изменений",
+	ProblemAlreadyExists:        "Задача уже существует",
+	ProblemPatchTestFailed:      "Проверка патча не пройдена",
+	ProblemRequestInProgress:    "Запрос выполняется",
+	ProblemIdempotencyKeyReused: "Повторное использование ключа идемпотентности",
+	ProblemPreconditionFailed:   "Предусловие не выполнено",
+	ProblemBatchAborted:         "Операция отменена",
+	ProblemUndoUnavailable:      "Нечего отменять",
+	ProblemSubtaskRule:          "Нарушено правило подзадач",
+	ProblemTimeout:              "Превышено время ожидания запроса",
+	ProblemInternal:             "Внутренняя ошибка сервера",
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestNew_AddsRequestIDFromContext checks that records logged with a request context carry its ID
+func TestNew_AddsRequestIDFromContext(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := New(&out, "info", "json")
+	ctx := WithRequestID(context.Background(), "req-1")
+
+	// Act
+	logger.With("component", "test").InfoContext(ctx, "hello")
+
+	// Assert
+	assert.Contains(t, out.String(), `"request_id":"req-1"`)
+	assert.Contains(t, out.String(), `"component":"test"`)
+}
+
+// TestNew_AddsTraceIDFromContext checks that records logged inside a span can be joined with the trace
+func TestNew_AddsTraceIDFromContext(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := New(&out, "info", "json")
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+
+	// Act
+	logger.InfoContext(ctx, "hello")
+
+	// Assert
+	assert.Contains(t, out.String(), `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, out.String(), `"span_id":"00f067aa0ba902b7"`)
+}
+
+// TestNew_WithoutRequestID checks that no empty request_id attribute is added
+func TestNew_WithoutRequestID(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := New(&out, "info", "text")
+
+	// Act
+	logger.Info("hello")
+
+	// Assert
+	assert.Contains(t, out.String(), "msg=hello")
+	assert.NotContains(t, out.String(), "request_id")
+	assert.NotContains(t, out.String(), "trace_id")
+}
+
+// TestNew_FiltersByLevel checks that records below the configured level are dropped
+func TestNew_FiltersByLevel(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	logger := New(&out, "warn", "text")
+
+	// Act
+	logger.Info("quiet")
+	logger.Warn("loud")
+
+	// Assert
+	assert.False(t, strings.Contains(out.String(), "quiet"))
+	assert.Contains(t, out.String(), "loud")
+}
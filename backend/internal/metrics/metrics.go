@@ -0,0 +1,181 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"todo/internal/domain/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "todo"
+
+// taskCountTimeout bounds the query behind the task gauges so a slow database
+// cannot stall a scrape.
+const taskCountTimeout = 5 * time.Second
+
+// Metrics owns the Prometheus registry of the service and the collectors
+// recorded by the HTTP middleware, the repositories and the overdue job.
+type Metrics struct {
+	registry *prometheus.Registry
+	logger   *slog.Logger
+
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	queryDuration   *prometheus.HistogramVec
+	overdueRuns     prometheus.Counter
+	overdueFailures prometheus.Counter
+	overdueMarked   prometheus.Counter
+}
+
+func New(logger *slog.Logger) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		logger:   logger,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route and status code.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "query_duration_seconds",
+			Help:      "Task repository call latency by method and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method", "outcome"}),
+		overdueRuns: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "overdue_job",
+			Name:      "runs_total",
+			Help:      "Runs of the job that marks tasks past their deadline as overdue.",
+		}),
+		overdueFailures: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "overdue_job",
+			Name:      "failures_total",
+			Help:      "Runs of the overdue job that ended with an error.",
+		}),
+		overdueMarked: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "overdue_job",
+			Name:      "tasks_transitioned_total",
+			Help:      "Tasks moved to OVERDUE by the overdue job.",
+		}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.queryDuration,
+		m.overdueRuns,
+		m.overdueFailures,
+		m.overdueMarked,
+	)
+	return m
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+func (m *Metrics) ObserveHTTP(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) ObserveQuery(method string, elapsed time.Duration, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	m.queryDuration.WithLabelValues(method, outcome).Observe(elapsed.Seconds())
+}
+
+// ObserveOverdueRun records one run of the overdue job. transitioned counts
+// the tasks it changed, even when the run stopped early with an error.
+func (m *Metrics) ObserveOverdueRun(transitioned int, err error) {
+	m.overdueRuns.Inc()
+	m.overdueMarked.Add(float64(transitioned))
+	if err != nil {
+		m.overdueFailures.Inc()
+	}
+}
+
+// RegisterDB exposes the sql.DBStats of the connection pool.
+func (m *Metrics) RegisterDB(db *sql.DB, name string) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// RegisterTaskCounts exposes the number of tasks per status and priority,
+// computed by count on every scrape.
+func (m *Metrics) RegisterTaskCounts(count func(ctx context.Context) ([]model.TaskCount, error)) {
+	m.registry.MustRegister(&taskCountCollector{count: count, logger: m.logger})
+}
+
+var taskCountDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "tasks"),
+	"Number of tasks by status and priority.",
+	[]string{"status", "priority"}, nil,
+)
+
+var (
+	taskStatuses   = []model.TaskStatus{model.StatusActive, model.StatusCompleted, model.StatusOverdue, model.StatusLate}
+	taskPriorities = []model.TaskPriority{model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityCritical}
+)
+
+type taskCountCollector struct {
+	count  func(ctx context.Context) ([]model.TaskCount, error)
+	logger *slog.Logger
+}
+
+func (c *taskCountCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- taskCountDesc
+}
+
+// Collect reports every status and priority pair, including empty ones, so a
+// series drops to zero instead of disappearing when its last task changes.
+func (c *taskCountCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), taskCountTimeout)
+	defer cancel()
+	counts, err := c.count(ctx)
+	if err != nil {
+		c.logger.ErrorContext(ctx, "failed to count tasks for metrics", slog.Any("error", err))
+		ch <- prometheus.NewInvalidMetric(taskCountDesc, err)
+		return
+	}
+
+	type key struct {
+		status   model.TaskStatus
+		priority model.TaskPriority
+	}
+	values := make(map[key]int, len(counts))
+	for _, status := range taskStatuses {
+		for _, priority := range taskPriorities {
+			values[key{status, priority}] = 0
+		}
+	}
+	for _, count := range counts {
+		values[key{count.Status, count.Priority}] += count.Count
+	}
+	for k, v := range values {
+		ch <- prometheus.MustNewConstMetric(taskCountDesc, prometheus.GaugeValue, float64(v), string(k.status), string(k.priority))
+	}
+}
@@ -0,0 +1,95 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMetrics_ObserveHTTP checks that requests are counted per method, route and status
+func TestMetrics_ObserveHTTP(t *testing.T) {
+	// Arrange
+	m := New(slog.New(slog.DiscardHandler))
+
+	// Act
+	m.ObserveHTTP("GET", "/api/tasks/:id", 200, 10*time.Millisecond)
+	m.ObserveHTTP("GET", "/api/tasks/:id", 200, 20*time.Millisecond)
+	m.ObserveHTTP("GET", "/api/tasks/:id", 404, time.Millisecond)
+
+	// Assert
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/tasks/:id", "200")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.httpRequests.WithLabelValues("GET", "/api/tasks/:id", "404")))
+	assert.Equal(t, 2, testutil.CollectAndCount(m.httpDuration))
+}
+
+// TestMetrics_ObserveQuery checks that failed queries get their own outcome label
+func TestMetrics_ObserveQuery(t *testing.T) {
+	// Arrange
+	m := New(slog.New(slog.DiscardHandler))
+
+	// Act
+	m.ObserveQuery("FindByID", time.Millisecond, nil)
+	m.ObserveQuery("FindByID", time.Millisecond, errors.New("boom"))
+
+	// Assert
+	assert.Equal(t, 2, testutil.CollectAndCount(m.queryDuration))
+}
+
+// TestMetrics_ObserveOverdueRun checks the overdue job counters
+func TestMetrics_ObserveOverdueRun(t *testing.T) {
+	// Arrange
+	m := New(slog.New(slog.DiscardHandler))
+
+	// Act
+	m.ObserveOverdueRun(3, nil)
+	m.ObserveOverdueRun(1, context.DeadlineExceeded)
+
+	// Assert
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.overdueRuns))
+	assert.Equal(t, 1.0, testutil.ToFloat64(m.overdueFailures))
+	assert.Equal(t, 4.0, testutil.ToFloat64(m.overdueMarked))
+}
+
+// TestMetrics_TaskCounts checks that every status and priority pair is reported, empty ones as zero
+func TestMetrics_TaskCounts(t *testing.T) {
+	// Arrange
+	m := New(slog.New(slog.DiscardHandler))
+	m.RegisterTaskCounts(func(ctx context.Context) ([]model.TaskCount, error) {
+		return []model.TaskCount{{Status: model.StatusActive, Priority: model.PriorityHigh, Count: 5}}, nil
+	})
+
+	// Act
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+
+	// Assert
+	body := w.Body.String()
+	assert.Contains(t, body, `todo_tasks{priority="HIGH",status="ACTIVE"} 5`)
+	assert.Contains(t, body, `todo_tasks{priority="LOW",status="COMPLETED"} 0`)
+	assert.Equal(t, 16, strings.Count(body, "todo_tasks{"))
+}
+
+// TestMetrics_TaskCountsError checks that a failing count surfaces as a scrape error
+func TestMetrics_TaskCountsError(t *testing.T) {
+	// Arrange
+	m := New(slog.New(slog.DiscardHandler))
+	m.RegisterTaskCounts(func(ctx context.Context) ([]model.TaskCount, error) {
+		return nil, errors.New("db down")
+	})
+
+	// Act
+	_, err := m.registry.Gather()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "db down")
+}
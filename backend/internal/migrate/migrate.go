@@ -0,0 +1,143 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"text/tabwriter"
+	"time"
+	"todo/migrations"
+
+	"github.com/pressly/goose/v3"
+)
+
+// ErrSchemaTooNew means the database was migrated by a newer build. Serving
+// from an older binary could misread or corrupt data, so callers refuse to start.
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+// Migrator applies the migrations embedded in the binary to a database.
+type Migrator struct {
+	provider *goose.Provider
+	logger   *slog.Logger
+}
+
+// New returns a Migrator for a postgres or sqlite database.
+func New(db *sql.DB, driver string, logger *slog.Logger) (*Migrator, error) {
+	var (
+		dialect goose.Dialect
+		fsys    fs.FS = migrations.FS
+	)
+	switch driver {
+	case "postgres":
+		dialect = goose.DialectPostgres
+	case "sqlite":
+		dialect = goose.DialectSQLite3
+		sub, err := fs.Sub(migrations.FS, "sqlite")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sub
+	default:
+		return nil, fmt.Errorf("migrations are not supported for driver %q", driver)
+	}
+
+	provider, err := goose.NewProvider(dialect, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{provider: provider, logger: logger}, nil
+}
+
+// Versions returns the schema version recorded in the database and the latest
+// version embedded in the binary. A database that was never migrated is at 0.
+func (m *Migrator) Versions(ctx context.Context) (current, latest int64, err error) {
+	sources := m.provider.ListSources()
+	latest = sources[len(sources)-1].Version
+
+	current, err = m.provider.GetDBVersion(ctx)
+	if err != nil {
+		return 0, latest, err
+	}
+	return current, latest, nil
+}
+
+// Check returns ErrSchemaTooNew when the database is ahead of the binary and
+// reports whether migrations are still pending.
+func (m *Migrator) Check(ctx context.Context) (pending bool, err error) {
+	current, latest, err := m.Versions(ctx)
+	if err != nil {
+		return false, err
+	}
+	if current > latest {
+		return false, fmt.Errorf("%w: database is at version %d, binary knows up to %d", ErrSchemaTooNew, current, latest)
+	}
+	return current < latest, nil
+}
+
+// Up applies every pending migration.
+func (m *Migrator) Up(ctx context.Context) error {
+	if _, err := m.Check(ctx); err != nil {
+		return err
+	}
+	results, err := m.provider.Up(ctx)
+	for _, result := range results {
+		m.logResult(ctx, result)
+	}
+	return err
+}
+
+// Down rolls back the most recently applied migration.
+func (m *Migrator) Down(ctx context.Context) error {
+	if _, err := m.Check(ctx); err != nil {
+		return err
+	}
+	result, err := m.provider.Down(ctx)
+	if result != nil {
+		m.logResult(ctx, result)
+	}
+	return err
+}
+
+func (m *Migrator) logResult(ctx context.Context, result *goose.MigrationResult) {
+	attrs := []slog.Attr{
+		slog.String("direction", result.Direction),
+		slog.String("migration", result.Source.Path),
+		slog.Duration("duration", result.Duration),
+	}
+	if result.Error != nil {
+		m.logger.LogAttrs(ctx, slog.LevelError, "migration failed", append(attrs, slog.Any("error", result.Error))...)
+		return
+	}
+	m.logger.LogAttrs(ctx, slog.LevelInfo, "migration applied", attrs...)
+}
+
+// Status writes the state of every embedded migration to w.
+func (m *Migrator) Status(ctx context.Context, w io.Writer) error {
+	statuses, err := m.provider.Status(ctx)
+	if err != nil {
+		return err
+	}
+	current, latest, err := m.Versions(ctx)
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATE\tAPPLIED AT\tMIGRATION")
+	for _, status := range statuses {
+		appliedAt := "-"
+		if !status.AppliedAt.IsZero() {
+			appliedAt = status.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", status.State, appliedAt, status.Source.Path)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "database version %d, binary version %d\n", current, latest)
+	return err
+}
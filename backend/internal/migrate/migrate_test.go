@@ -0,0 +1,138 @@
+package migrate
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestMigrator(t *testing.T) (*Migrator, *sql.DB) {
+	t.Helper()
+	db, err := sql.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	// Every connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := New(db, "sqlite", slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	return migrator, db
+}
+
+// TestNew_UnsupportedDriver checks that drivers without migrations are rejected
+func TestNew_UnsupportedDriver(t *testing.T) {
+	// Act
+	_, err := New(&sql.DB{}, "memory", slog.New(slog.DiscardHandler))
+
+	// Assert
+	assert.ErrorContains(t, err, "not supported")
+}
+
+// TestMigrator_UpAppliesEverything checks that a fresh database reaches the latest version
+func TestMigrator_UpAppliesEverything(t *testing.T) {
+	// Arrange
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+
+	// Act
+	err := migrator.Up(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	current, latest, err := migrator.Versions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, latest, current)
+	_, err = db.Exec(`SELECT id FROM tasks`)
+	assert.NoError(t, err)
+	pending, err := migrator.Check(ctx)
+	assert.NoError(t, err)
+	assert.False(t, pending)
+}
+
+// TestMigrator_DownRollsBackOneStep checks that down reverts only the latest migration
+func TestMigrator_DownRollsBackOneStep(t *testing.T) {
+	// Arrange
+	migrator, _ := newTestMigrator(t)
+	ctx := context.Background()
+	require.NoError(t, migrator.Up(ctx))
+
+	// Act
+	err := migrator.Down(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	current, latest, err := migrator.Versions(ctx)
+	require.NoError(t, err)
+	assert.Less(t, current, latest)
+	pending, err := migrator.Check(ctx)
+	assert.NoError(t, err)
+	assert.True(t, pending)
+}
+
+// TestMigrator_DownRollsBackEverything checks that every migration can be reverted and applied again
+func TestMigrator_DownRollsBackEverything(t *testing.T) {
+	// Arrange
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+	require.NoError(t, migrator.Up(ctx))
+
+	// Act
+	for {
+		current, _, err := migrator.Versions(ctx)
+		require.NoError(t, err)
+		if current == 0 {
+			break
+		}
+		require.NoError(t, migrator.Down(ctx), "down from %d", current)
+	}
+	err := migrator.Up(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	var partial int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'index' AND sql LIKE '%WHERE deleted_at IS NULL'`).Scan(&partial))
+	assert.Equal(t, 4, partial)
+}
+
+// TestMigrator_RefusesNewerSchema checks that a database migrated by a newer build is not touched
+func TestMigrator_RefusesNewerSchema(t *testing.T) {
+	// Arrange
+	migrator, db := newTestMigrator(t)
+	ctx := context.Background()
+	require.NoError(t, migrator.Up(ctx))
+	_, err := db.Exec(`INSERT INTO goose_db_version (version_id, is_applied) VALUES (99990101, 1)`)
+	require.NoError(t, err)
+
+	// Act
+	_, checkErr := migrator.Check(ctx)
+	upErr := migrator.Up(ctx)
+	downErr := migrator.Down(ctx)
+
+	// Assert
+	assert.ErrorIs(t, checkErr, ErrSchemaTooNew)
+	assert.ErrorIs(t, upErr, ErrSchemaTooNew)
+	assert.ErrorIs(t, downErr, ErrSchemaTooNew)
+}
+
+// TestMigrator_Status checks that every embedded migration is listed with its state
+func TestMigrator_Status(t *testing.T) {
+	// Arrange
+	migrator, _ := newTestMigrator(t)
+	ctx := context.Background()
+	var out bytes.Buffer
+
+	// Act
+	err := migrator.Status(ctx, &out)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "pending")
+	assert.Contains(t, out.String(), "20250503_001_create_tasks.sql")
+	assert.Contains(t, out.String(), "database version 0")
+}
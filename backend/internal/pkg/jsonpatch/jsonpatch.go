@@ -0,0 +1,258 @@
+// Package jsonpatch applies JSON Patch documents (RFC 6902) to decoded JSON
+// objects. It supports the add, remove, replace and test operations.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ContentType is the media type of JSON Patch documents.
+const ContentType = "application/json-patch+json"
+
+var (
+	// ErrNotArray means the patch document is not a JSON array.
+	ErrNotArray = errors.New("patch must be a JSON array of operations")
+	// ErrUnsupportedOp means the operation is not one of add, remove, replace or test.
+	ErrUnsupportedOp = errors.New("unsupported operation")
+	// ErrInvalidPath means the path is not a JSON Pointer this package can apply.
+	ErrInvalidPath = errors.New("invalid path")
+	// ErrPathNotFound means the path does not point at an existing value.
+	ErrPathNotFound = errors.New("path not found")
+	// ErrMissingValue means an operation that needs a value has none.
+	ErrMissingValue = errors.New("missing value")
+	// ErrTestFailed means a test operation found a different value.
+	ErrTestFailed = errors.New("test failed")
+)
+
+// Error reports the operation that stopped a patch. Index is its position in
+// the patch, counting from zero.
+type Error struct {
+	Index int
+	Op    string
+	Path  string
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d (%s %q): %v", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Operation is one step of a patch.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Patch is an ordered list of operations applied as a whole.
+type Patch []Operation
+
+// Decode parses a JSON Patch document. Operations are checked when applied.
+func Decode(body []byte) (Patch, error) {
+	var patch Patch
+	if err := json.Unmarshal(body, &patch); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field == "" {
+			return nil, ErrNotArray
+		}
+		return nil, err
+	}
+	if patch == nil {
+		return nil, ErrNotArray
+	}
+	return patch, nil
+}
+
+// Apply runs the operations in order against a copy of doc and returns the
+// result. If any operation fails, doc is left untouched and the error is an
+// *Error. The document root can be tested but not replaced or removed.
+func (p Patch) Apply(doc map[string]any) (map[string]any, error) {
+	result, err := deepCopy(doc)
+	if err != nil {
+		return nil, err
+	}
+	for i, op := range p {
+		if err := apply(result, op); err != nil {
+			return nil, &Error{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return result, nil
+}
+
+func apply(doc map[string]any, op Operation) error {
+	tokens, err := parsePointer(op.Path)
+	if err != nil {
+		return err
+	}
+
+	var value any
+	switch op.Op {
+	case "add", "replace", "test":
+		if len(op.Value) == 0 {
+			return ErrMissingValue
+		}
+		if err := json.Unmarshal(op.Value, &value); err != nil {
+			return err
+		}
+	case "remove":
+	default:
+		return ErrUnsupportedOp
+	}
+
+	if len(tokens) == 0 {
+		if op.Op != "test" {
+			return ErrInvalidPath
+		}
+		if !reflect.DeepEqual(map[string]any(doc), value) {
+			return ErrTestFailed
+		}
+		return nil
+	}
+
+	parent, err := resolve(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	last := tokens[len(tokens)-1]
+
+	switch container := parent.(type) {
+	case map[string]any:
+		current, exists := container[last]
+		if !exists && op.Op != "add" {
+			return ErrPathNotFound
+		}
+		switch op.Op {
+		case "add", "replace":
+			container[last] = value
+		case "remove":
+			delete(container, last)
+		case "test":
+			if !reflect.DeepEqual(current, value) {
+				return ErrTestFailed
+			}
+		}
+		return nil
+	case []any:
+		return applyToArray(doc, tokens, container, last, op.Op, value)
+	default:
+		return ErrPathNotFound
+	}
+}
+
+// applyToArray handles operations whose target is an array element. Arrays
+// change length on add and remove, so the new slice is stored back into its
+// parent.
+func applyToArray(doc map[string]any, tokens []string, array []any, last, op string, value any) error {
+	if op == "add" && last == "-" {
+		return store(doc, tokens[:len(tokens)-1], append(array, value))
+	}
+	index, err := strconv.Atoi(last)
+	if err != nil || index < 0 || (last != "0" && strings.HasPrefix(last, "0")) {
+		return ErrInvalidPath
+	}
+	limit := len(array)
+	if op == "add" {
+		limit++
+	}
+	if index >= limit {
+		return ErrPathNotFound
+	}
+
+	switch op {
+	case "add":
+		grown := append(array[:index:index], value)
+		return store(doc, tokens[:len(tokens)-1], append(grown, array[index:]...))
+	case "remove":
+		shrunk := append(array[:index:index], array[index+1:]...)
+		return store(doc, tokens[:len(tokens)-1], shrunk)
+	case "replace":
+		array[index] = value
+	case "test":
+		if !reflect.DeepEqual(array[index], value) {
+			return ErrTestFailed
+		}
+	}
+	return nil
+}
+
+// store replaces the value at tokens, which is known to exist.
+func store(doc map[string]any, tokens []string, value any) error {
+	parent, err := resolve(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return err
+	}
+	last := tokens[len(tokens)-1]
+	switch container := parent.(type) {
+	case map[string]any:
+		container[last] = value
+	case []any:
+		index, _ := strconv.Atoi(last)
+		container[index] = value
+	}
+	return nil
+}
+
+func resolve(doc map[string]any, tokens []string) (any, error) {
+	var current any = doc
+	for _, token := range tokens {
+		switch container := current.(type) {
+		case map[string]any:
+			next, ok := container[token]
+			if !ok {
+				return nil, ErrPathNotFound
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(container) {
+				return nil, ErrPathNotFound
+			}
+			current = container[index]
+		default:
+			return nil, ErrPathNotFound
+		}
+	}
+	return current, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into unescaped reference
+// tokens. The empty pointer refers to the whole document.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, ErrInvalidPath
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		for j := 0; j < len(token); j++ {
+			if token[j] == '~' && (j+1 == len(token) || (token[j+1] != '0' && token[j+1] != '1')) {
+				return nil, ErrInvalidPath
+			}
+		}
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+func deepCopy(doc map[string]any) (map[string]any, error) {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var clone map[string]any
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	return clone, nil
+}
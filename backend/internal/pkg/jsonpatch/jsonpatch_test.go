@@ -0,0 +1,94 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func decodeDoc(t *testing.T, raw string) map[string]any {
+	t.Helper()
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal([]byte(raw), &doc))
+	return doc
+}
+
+// TestApply checks the operations against examples from RFC 6902 appendix A
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add object member", `{"foo":"bar"}`, `[{"op":"add","path":"/baz","value":"qux"}]`, `{"baz":"qux","foo":"bar"}`},
+		{"add array element", `{"foo":["bar","baz"]}`, `[{"op":"add","path":"/foo/1","value":"qux"}]`, `{"foo":["bar","qux","baz"]}`},
+		{"append to array", `{"foo":["bar"]}`, `[{"op":"add","path":"/foo/-","value":"qux"}]`, `{"foo":["bar","qux"]}`},
+		{"remove object member", `{"baz":"qux","foo":"bar"}`, `[{"op":"remove","path":"/baz"}]`, `{"foo":"bar"}`},
+		{"remove array element", `{"foo":["bar","qux","baz"]}`, `[{"op":"remove","path":"/foo/1"}]`, `{"foo":["bar","baz"]}`},
+		{"replace value", `{"baz":"qux","foo":"bar"}`, `[{"op":"replace","path":"/baz","value":"boo"}]`, `{"baz":"boo","foo":"bar"}`},
+		{"replace with null", `{"baz":"qux"}`, `[{"op":"replace","path":"/baz","value":null}]`, `{"baz":null}`},
+		{"test then replace", `{"status":"ACTIVE","priority":"LOW"}`, `[{"op":"test","path":"/status","value":"ACTIVE"},{"op":"replace","path":"/priority","value":"HIGH"}]`, `{"status":"ACTIVE","priority":"HIGH"}`},
+		{"escaped pointer", `{"a/b":1,"m~n":2}`, `[{"op":"test","path":"/a~1b","value":1},{"op":"replace","path":"/m~0n","value":3}]`, `{"a/b":1,"m~n":3}`},
+		{"test whole document", `{"foo":1}`, `[{"op":"test","path":"","value":{"foo":1}}]`, `{"foo":1}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := Decode([]byte(tt.patch))
+			require.NoError(t, err)
+
+			got, err := patch.Apply(decodeDoc(t, tt.doc))
+
+			require.NoError(t, err)
+			assert.Equal(t, decodeDoc(t, tt.want), got)
+		})
+	}
+}
+
+// TestApply_Errors checks that failing operations name the step and leave the document as it was
+func TestApply_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		index int
+		want  error
+	}{
+		{"failed test", `[{"op":"replace","path":"/foo","value":2},{"op":"test","path":"/foo","value":"1"}]`, 1, ErrTestFailed},
+		{"missing member", `[{"op":"remove","path":"/bar"}]`, 0, ErrPathNotFound},
+		{"missing parent", `[{"op":"add","path":"/bar/baz","value":1}]`, 0, ErrPathNotFound},
+		{"array index out of range", `[{"op":"add","path":"/list/3","value":1}]`, 0, ErrPathNotFound},
+		{"array index with leading zero", `[{"op":"replace","path":"/list/01","value":1}]`, 0, ErrInvalidPath},
+		{"pointer without slash", `[{"op":"replace","path":"foo","value":1}]`, 0, ErrInvalidPath},
+		{"bad escape", `[{"op":"replace","path":"/f~2o","value":1}]`, 0, ErrInvalidPath},
+		{"replace root", `[{"op":"replace","path":"","value":{}}]`, 0, ErrInvalidPath},
+		{"missing value", `[{"op":"add","path":"/bar"}]`, 0, ErrMissingValue},
+		{"unsupported op", `[{"op":"move","path":"/bar"}]`, 0, ErrUnsupportedOp},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := decodeDoc(t, `{"foo":1,"list":[1,2]}`)
+			patch, err := Decode([]byte(tt.patch))
+			require.NoError(t, err)
+
+			got, err := patch.Apply(doc)
+
+			assert.Nil(t, got)
+			assert.ErrorIs(t, err, tt.want)
+			var patchErr *Error
+			require.ErrorAs(t, err, &patchErr)
+			assert.Equal(t, tt.index, patchErr.Index)
+			assert.Equal(t, decodeDoc(t, `{"foo":1,"list":[1,2]}`), doc)
+		})
+	}
+}
+
+// TestDecode_NotArray checks that anything but an array of operations is refused
+func TestDecode_NotArray(t *testing.T) {
+	for _, body := range []string{`{"op":"add"}`, `null`, `"add"`} {
+		_, err := Decode([]byte(body))
+
+		assert.ErrorIs(t, err, ErrNotArray, body)
+	}
+}
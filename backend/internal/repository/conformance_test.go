@@ -0,0 +1,664 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/pkg/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTaskRepositoryConformance is the behaviour every repository.TaskRepository
+// implementation must provide. newRepo must return an empty repository.
+func runTaskRepositoryConformance(t *testing.T, newRepo func(t *testing.T) repository.TaskRepository) {
+	// Database timestamps keep microseconds, so fixtures are truncated to match.
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	ctx := context.Background()
+
+	newTask := func(id string, createdAt time.Time) *model.Task {
+		return &model.Task{
+			ID:        id,
+			Title:     "Task " + id,
+			Status:    model.StatusActive,
+			Priority:  model.PriorityMedium,
+			CreatedAt: createdAt,
+		}
+	}
+
+	t.Run("FindByID returns ErrTaskNotFound for unknown id", func(t *testing.T) {
+		repo := newRepo(t)
+
+		task, err := repo.FindByID(ctx, "missing")
+
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+		assert.Nil(t, task)
+	})
+
+	t.Run("Update returns ErrTaskNotFound for unknown id", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.Update(ctx, newTask("missing", base))
+
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	})
+
+	t.Run("Delete returns ErrTaskNotFound for unknown id", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.Delete(ctx, "missing", repository.AnyVersion)
+
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	})
+
+	t.Run("Create returns ErrTaskExists for a taken id", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("taken", base)))
+
+		err := repo.Create(ctx, newTask("taken", base.Add(time.Second)))
+
+		assert.ErrorIs(t, err, repository.ErrTaskExists)
+		got, findErr := repo.FindByID(ctx, "taken")
+		require.NoError(t, findErr)
+		assert.True(t, got.CreatedAt.Equal(base))
+	})
+
+	t.Run("Create starts at version 1 and Update bumps it", func(t *testing.T) {
+		repo := newRepo(t)
+		task := newTask("versioned", base)
+		require.NoError(t, repo.Create(ctx, task))
+		assert.Equal(t, int64(1), task.Version)
+
+		require.NoError(t, repo.Update(ctx, task))
+		got, err := repo.FindByID(ctx, "versioned")
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), task.Version)
+		assert.Equal(t, int64(2), got.Version)
+	})
+
+	t.Run("Update with a stale version returns ErrVersionConflict", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("stale", base)))
+		first, err := repo.FindByID(ctx, "stale")
+		require.NoError(t, err)
+		second, err := repo.FindByID(ctx, "stale")
+		require.NoError(t, err)
+
+		first.Title = "First writer"
+		require.NoError(t, repo.Update(ctx, first))
+		second.Title = "Second writer"
+		err = repo.Update(ctx, second)
+		got, findErr := repo.FindByID(ctx, "stale")
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		assert.Equal(t, int64(1), second.Version)
+		require.NoError(t, findErr)
+		assert.Equal(t, "First writer", got.Title)
+	})
+
+	t.Run("Delete with a stale version returns ErrVersionConflict", func(t *testing.T) {
+		repo := newRepo(t)
+		task := newTask("kept", base)
+		require.NoError(t, repo.Create(ctx, task))
+		require.NoError(t, repo.Update(ctx, task))
+
+		err := repo.Delete(ctx, "kept", 1)
+		_, findErr := repo.FindByID(ctx, "kept")
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		assert.NoError(t, findErr)
+		assert.NoError(t, repo.Delete(ctx, "kept", 2))
+	})
+
+	t.Run("nullable fields round-trip as nil", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("plain", base)))
+
+		task, err := repo.FindByID(ctx, "plain")
+
+		require.NoError(t, err)
+		assert.Nil(t, task.Description)
+		assert.Nil(t, task.Deadline)
+		assert.Nil(t, task.UpdatedAt)
+		assert.Nil(t, task.ParentID)
+	})
+
+	t.Run("all fields round-trip", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("parent", base)))
+		task := newTask("full", base)
+		task.Description = utils.Ptr("desc")
+		task.Deadline = utils.Ptr(base.Add(time.Hour))
+		task.UpdatedAt = utils.Ptr(base.Add(time.Minute))
+		task.Status = model.StatusCompleted
+		task.Priority = model.PriorityCritical
+		task.IsCompleted = true
+		task.ParentID = utils.Ptr("parent")
+		require.NoError(t, repo.Create(ctx, task))
+
+		got, err := repo.FindByID(ctx, "full")
+
+		require.NoError(t, err)
+		assert.Equal(t, task.Title, got.Title)
+		assert.Equal(t, "desc", *got.Description)
+		assert.True(t, task.Deadline.Equal(*got.Deadline))
+		assert.True(t, task.UpdatedAt.Equal(*got.UpdatedAt))
+		assert.True(t, task.CreatedAt.Equal(got.CreatedAt))
+		assert.Equal(t, model.StatusCompleted, got.Status)
+		assert.Equal(t, model.PriorityCritical, got.Priority)
+		assert.True(t, got.IsCompleted)
+		assert.Equal(t, "parent", *got.ParentID)
+	})
+
+	t.Run("Update can clear nullable fields", func(t *testing.T) {
+		repo := newRepo(t)
+		task := newTask("clear", base)
+		task.Description = utils.Ptr("desc")
+		task.Deadline = utils.Ptr(base.Add(time.Hour))
+		require.NoError(t, repo.Create(ctx, task))
+
+		task.Description = nil
+		task.Deadline = nil
+		task.Title = "Renamed"
+		require.NoError(t, repo.Update(ctx, task))
+		got, err := repo.FindByID(ctx, "clear")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Title)
+		assert.Nil(t, got.Description)
+		assert.Nil(t, got.Deadline)
+	})
+
+	t.Run("Delete moves the task to the trash", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("gone", base)))
+
+		require.NoError(t, repo.Delete(ctx, "gone", repository.AnyVersion))
+		_, err := repo.FindByID(ctx, "gone")
+
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+		deleted, err := repo.FindDeletedByID(ctx, "gone")
+		require.NoError(t, err)
+		require.NotNil(t, deleted.DeletedAt)
+		assert.Equal(t, int64(2), deleted.Version)
+	})
+
+	t.Run("deleted tasks are left out of reads and writes", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("kept", base)))
+		require.NoError(t, repo.Create(ctx, newTask("gone", base.Add(time.Minute))))
+		require.NoError(t, repo.Delete(ctx, "gone", repository.AnyVersion))
+		gone, err := repo.FindDeletedByID(ctx, "gone")
+		require.NoError(t, err)
+
+		all, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		page, total, err := repo.FindWithFilter(ctx, &model.TaskFilter{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		byIDs, err := repo.FindByIDs(ctx, []string{"kept", "gone"})
+		require.NoError(t, err)
+		counts, err := repo.CountByStatusAndPriority(ctx)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"kept"}, taskIDs(all))
+		assert.Equal(t, []string{"kept"}, taskIDs(page))
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []string{"kept"}, taskIDs(byIDs))
+		assert.Equal(t, []model.TaskCount{{Status: model.StatusActive, Priority: model.PriorityMedium, Count: 1}}, counts)
+		assert.ErrorIs(t, repo.Update(ctx, gone), repository.ErrTaskNotFound)
+		assert.ErrorIs(t, repo.Delete(ctx, "gone", repository.AnyVersion), repository.ErrTaskNotFound)
+		assert.ErrorIs(t, repo.Create(ctx, newTask("gone", base)), repository.ErrTaskExists)
+	})
+
+	t.Run("FindDeleted lists the most recently deleted first", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, id := range []string{"a", "b", "c", "live"} {
+			require.NoError(t, repo.Create(ctx, newTask(id, base)))
+		}
+		for _, id := range []string{"a", "b", "c"} {
+			require.NoError(t, repo.Delete(ctx, id, repository.AnyVersion))
+		}
+
+		first, total, err := repo.FindDeleted(ctx, 0, 2)
+		require.NoError(t, err)
+		rest, _, err := repo.FindDeleted(ctx, 2, 2)
+		require.NoError(t, err)
+
+		assert.Equal(t, 3, total)
+		assert.Equal(t, []string{"c", "b"}, taskIDs(first))
+		assert.Equal(t, []string{"a"}, taskIDs(rest))
+	})
+
+	t.Run("Restore takes the task out of the trash", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("back", base)))
+		require.NoError(t, repo.Delete(ctx, "back", repository.AnyVersion))
+		deleted, err := repo.FindDeletedByID(ctx, "back")
+		require.NoError(t, err)
+		updatedAt := base.Add(time.Hour)
+		deleted.Status = model.StatusOverdue
+		deleted.UpdatedAt = &updatedAt
+
+		require.NoError(t, repo.Restore(ctx, deleted))
+
+		assert.Equal(t, int64(3), deleted.Version)
+		assert.Nil(t, deleted.DeletedAt)
+		got, err := repo.FindByID(ctx, "back")
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusOverdue, got.Status)
+		assert.Equal(t, int64(3), got.Version)
+		assert.Nil(t, got.DeletedAt)
+		_, err = repo.FindDeletedByID(ctx, "back")
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	})
+
+	t.Run("Restore checks the version and the trash", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("live", base)))
+		require.NoError(t, repo.Create(ctx, newTask("gone", base)))
+		require.NoError(t, repo.Delete(ctx, "gone", repository.AnyVersion))
+		stale := newTask("gone", base)
+		stale.Version = 1
+		live := newTask("live", base)
+		live.Version = 1
+
+		assert.ErrorIs(t, repo.Restore(ctx, stale), repository.ErrVersionConflict)
+		assert.ErrorIs(t, repo.Restore(ctx, live), repository.ErrTaskNotFound)
+	})
+
+	t.Run("PurgeDeleted removes only tasks deleted before the cutoff", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("live", base)))
+		require.NoError(t, repo.Create(ctx, newTask("gone", base)))
+		require.NoError(t, repo.Delete(ctx, "gone", repository.AnyVersion))
+
+		kept, err := repo.PurgeDeleted(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		purged, err := repo.PurgeDeleted(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+
+		assert.Empty(t, kept)
+		assert.Equal(t, []string{"gone"}, purged)
+		_, err = repo.FindDeletedByID(ctx, "gone")
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+		_, err = repo.FindByID(ctx, "live")
+		assert.NoError(t, err)
+	})
+
+	t.Run("PurgeDeleted detaches the subtasks of a purged task", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("parent", base)))
+		child := newTask("child", base)
+		child.ParentID = utils.Ptr("parent")
+		require.NoError(t, repo.Create(ctx, child))
+		require.NoError(t, repo.Delete(ctx, "parent", repository.AnyVersion))
+		time.Sleep(time.Millisecond)
+		cutoff := time.Now()
+		time.Sleep(time.Millisecond)
+		require.NoError(t, repo.Delete(ctx, "child", repository.AnyVersion))
+
+		purged, err := repo.PurgeDeleted(ctx, cutoff)
+		require.NoError(t, err)
+		left, err := repo.FindDeletedByID(ctx, "child")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"parent"}, purged)
+		assert.Nil(t, left.ParentID)
+	})
+
+	t.Run("MarkOverdue moves only active open tasks past their deadline", func(t *testing.T) {
+		repo := newRepo(t)
+		past, future := base.Add(-time.Hour), base.Add(time.Hour)
+		for _, id := range []string{"overdue", "future", "completed", "no-deadline", "deleted"} {
+			task := newTask(id, base)
+			switch id {
+			case "future":
+				task.Deadline = &future
+			case "completed":
+				task.Deadline, task.IsCompleted, task.Status = &past, true, model.StatusLate
+			case "overdue", "deleted":
+				task.Deadline = &past
+			}
+			require.NoError(t, repo.Create(ctx, task))
+		}
+		require.NoError(t, repo.Delete(ctx, "deleted", repository.AnyVersion))
+
+		marked, err := repo.MarkOverdue(ctx, base)
+		require.NoError(t, err)
+		again, err := repo.MarkOverdue(ctx, base)
+		require.NoError(t, err)
+		stored, err := repo.FindByID(ctx, "overdue")
+		require.NoError(t, err)
+		upcoming, err := repo.FindByID(ctx, "future")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"overdue"}, taskIDs(marked))
+		assert.Equal(t, model.StatusOverdue, marked[0].Status)
+		assert.Equal(t, int64(2), marked[0].Version)
+		assert.Empty(t, again)
+		assert.Equal(t, model.StatusOverdue, stored.Status)
+		assert.Equal(t, int64(2), stored.Version)
+		require.NotNil(t, stored.UpdatedAt)
+		assert.True(t, base.Equal(*stored.UpdatedAt))
+		assert.Equal(t, model.StatusActive, upcoming.Status)
+	})
+
+	t.Run("FindSubtasks lists live children oldest first", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("parent", base)))
+		for i, id := range []string{"c", "a", "b", "gone"} {
+			child := newTask(id, base.Add(time.Duration(i/2)*time.Minute))
+			child.ParentID = utils.Ptr("parent")
+			require.NoError(t, repo.Create(ctx, child))
+		}
+		require.NoError(t, repo.Create(ctx, newTask("other", base)))
+		require.NoError(t, repo.Delete(ctx, "gone", repository.AnyVersion))
+
+		children, err := repo.FindSubtasks(ctx, "parent")
+		require.NoError(t, err)
+		none, err := repo.FindSubtasks(ctx, "other")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"a", "c", "b"}, taskIDs(children))
+		require.NotNil(t, children[0].ParentID)
+		assert.Equal(t, "parent", *children[0].ParentID)
+		assert.NotNil(t, none)
+		assert.Empty(t, none)
+	})
+
+	t.Run("FindWithFilter and FindAfter list top-level tasks only", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("parent", base)))
+		child := newTask("child", base.Add(time.Minute))
+		child.ParentID = utils.Ptr("parent")
+		require.NoError(t, repo.Create(ctx, child))
+
+		tasks, total, err := repo.FindWithFilter(ctx, &model.TaskFilter{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		page, err := repo.FindAfter(ctx, &model.TaskFilter{}, nil, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, total)
+		assert.Equal(t, []string{"parent"}, taskIDs(tasks))
+		assert.Equal(t, []string{"parent"}, taskIDs(page))
+	})
+
+	t.Run("CountSubtasks counts live children per parent", func(t *testing.T) {
+		repo := newRepo(t)
+		fixtures := []struct {
+			id, parent string
+			done       bool
+		}{
+			{"a1", "a", true},
+			{"a2", "a", false},
+			{"a3", "a", true},
+			{"b1", "b", false},
+			{"c1", "c", true},
+		}
+		for _, id := range []string{"a", "b", "c", "lonely"} {
+			require.NoError(t, repo.Create(ctx, newTask(id, base)))
+		}
+		for _, f := range fixtures {
+			child := newTask(f.id, base)
+			child.ParentID = utils.Ptr(f.parent)
+			child.IsCompleted = f.done
+			require.NoError(t, repo.Create(ctx, child))
+		}
+		require.NoError(t, repo.Delete(ctx, "a3", repository.AnyVersion))
+
+		progress, err := repo.CountSubtasks(ctx, []string{"a", "b", "lonely"})
+		require.NoError(t, err)
+		empty, err := repo.CountSubtasks(ctx, nil)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string]model.TaskProgress{
+			"a": {Done: 1, Total: 2},
+			"b": {Done: 0, Total: 1},
+		}, progress)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("writes keep the parent and Restore can clear it", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("parent", base)))
+		child := newTask("child", base)
+		child.ParentID = utils.Ptr("parent")
+		require.NoError(t, repo.Create(ctx, child))
+		child.ParentID = nil
+		require.NoError(t, repo.Update(ctx, child))
+		got, err := repo.FindByID(ctx, "child")
+		require.NoError(t, err)
+		require.NotNil(t, got.ParentID)
+		require.NoError(t, repo.Delete(ctx, "child", repository.AnyVersion))
+
+		deleted, err := repo.FindDeletedByID(ctx, "child")
+		require.NoError(t, err)
+		require.NotNil(t, deleted.ParentID)
+		deleted.ParentID = nil
+		require.NoError(t, repo.Restore(ctx, deleted))
+
+		got, err = repo.FindByID(ctx, "child")
+		require.NoError(t, err)
+		assert.Nil(t, got.ParentID)
+	})
+
+	t.Run("FindAll lists newest first", func(t *testing.T) {
+		repo := newRepo(t)
+		for i := 1; i <= 3; i++ {
+			require.NoError(t, repo.Create(ctx, newTask(fmt.Sprintf("t%d", i), base.Add(time.Duration(i)*time.Minute))))
+		}
+
+		tasks, err := repo.FindAll(ctx)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"t3", "t2", "t1"}, taskIDs(tasks))
+	})
+
+	t.Run("FindWithFilter filters and counts", func(t *testing.T) {
+		repo := newRepo(t)
+		for i, priority := range []model.TaskPriority{model.PriorityHigh, model.PriorityLow, model.PriorityHigh} {
+			task := newTask(fmt.Sprintf("t%d", i), base.Add(time.Duration(i)*time.Minute))
+			task.Priority = priority
+			require.NoError(t, repo.Create(ctx, task))
+		}
+
+		tasks, total, err := repo.FindWithFilter(ctx, &model.TaskFilter{Priority: "HIGH", Page: 1, PageSize: 1})
+
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.Equal(t, []string{"t2"}, taskIDs(tasks))
+	})
+
+	t.Run("FindWithFilter returns an empty page past the end", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("only", base)))
+
+		tasks, total, err := repo.FindWithFilter(ctx, &model.TaskFilter{Page: 2, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, total)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("FindWithFilter orders priority by rank", func(t *testing.T) {
+		repo := newRepo(t)
+		priorities := []model.TaskPriority{model.PriorityMedium, model.PriorityCritical, model.PriorityLow, model.PriorityHigh}
+		for i, priority := range priorities {
+			task := newTask(string(priority), base.Add(time.Duration(i)*time.Minute))
+			task.Priority = priority
+			require.NoError(t, repo.Create(ctx, task))
+		}
+
+		asc, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "priority", SortOrder: "asc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		desc, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "priority", SortOrder: "desc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"LOW", "MEDIUM", "HIGH", "CRITICAL"}, taskIDs(asc))
+		assert.Equal(t, []string{"CRITICAL", "HIGH", "MEDIUM", "LOW"}, taskIDs(desc))
+	})
+
+	t.Run("FindWithFilter puts missing deadlines last ascending and first descending", func(t *testing.T) {
+		repo := newRepo(t)
+		soon := newTask("soon", base)
+		soon.Deadline = utils.Ptr(base.Add(time.Hour))
+		later := newTask("later", base)
+		later.Deadline = utils.Ptr(base.Add(2 * time.Hour))
+		for _, task := range []*model.Task{later, newTask("none", base), soon} {
+			require.NoError(t, repo.Create(ctx, task))
+		}
+
+		asc, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "deadline", SortOrder: "asc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		desc, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "deadline", SortOrder: "desc", Page: 1, PageSize: 10})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"soon", "later", "none"}, taskIDs(asc))
+		assert.Equal(t, []string{"none", "later", "soon"}, taskIDs(desc))
+	})
+
+	t.Run("FindWithFilter breaks ties by id", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, id := range []string{"b", "c", "a"} {
+			require.NoError(t, repo.Create(ctx, newTask(id, base)))
+		}
+
+		tasks, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "created_at", SortOrder: "asc", Page: 1, PageSize: 10})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a", "b", "c"}, taskIDs(tasks))
+	})
+
+	t.Run("FindAfter continues after the cursor", func(t *testing.T) {
+		repo := newRepo(t)
+		ids := []string{"a", "b", "c", "d", "e"}
+		for i, id := range ids {
+			task := newTask(id, base)
+			if i%2 == 0 {
+				task.Deadline = utils.Ptr(base.Add(time.Duration(i) * time.Hour))
+			}
+			require.NoError(t, repo.Create(ctx, task))
+		}
+
+		for _, order := range []string{"asc", "desc"} {
+			filter := &model.TaskFilter{SortBy: "deadline", SortOrder: order}
+			var seen []string
+			var after *model.TaskCursor
+			for {
+				page, err := repo.FindAfter(ctx, filter, after, 2)
+				require.NoError(t, err)
+				if len(page) == 0 {
+					break
+				}
+				seen = append(seen, taskIDs(page)...)
+				last := page[len(page)-1]
+				after = &model.TaskCursor{ID: last.ID, Deadline: last.Deadline, CreatedAt: last.CreatedAt, Priority: last.Priority}
+			}
+
+			all, _, err := repo.FindWithFilter(ctx, &model.TaskFilter{SortBy: "deadline", SortOrder: order, Page: 1, PageSize: 10})
+			require.NoError(t, err)
+			assert.Equal(t, taskIDs(all), seen, "order %s", order)
+		}
+	})
+
+	t.Run("CreateMany stores new tasks and reports taken ids", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newTask("taken", base)))
+		tasks := []*model.Task{newTask("a", base), newTask("taken", base), newTask("b", base)}
+
+		errs, err := repo.CreateMany(ctx, tasks)
+
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, repository.ErrTaskExists, nil}, errs)
+		assert.Equal(t, int64(1), tasks[0].Version)
+		stored, err := repo.FindByIDs(ctx, []string{"a", "b", "missing"})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"a", "b"}, taskIDs(stored))
+	})
+
+	t.Run("UpdateMany writes current versions and reports the rest", func(t *testing.T) {
+		repo := newRepo(t)
+		fresh, stale := newTask("fresh", base), newTask("stale", base)
+		require.NoError(t, repo.Create(ctx, fresh))
+		require.NoError(t, repo.Create(ctx, stale))
+		fresh.Title = "Renamed"
+		fresh.Deadline = utils.Ptr(base.Add(time.Hour))
+		stale.Version = 5
+
+		errs, err := repo.UpdateMany(ctx, []*model.Task{fresh, stale, newTask("missing", base)})
+
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, repository.ErrVersionConflict, repository.ErrTaskNotFound}, errs)
+		assert.Equal(t, int64(2), fresh.Version)
+		got, err := repo.FindByID(ctx, "fresh")
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Title)
+		assert.True(t, got.Deadline.Equal(*fresh.Deadline))
+		assert.Equal(t, int64(2), got.Version)
+	})
+
+	t.Run("DeleteMany trashes matching tasks and reports the rest", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, id := range []string{"any", "exact", "stale"} {
+			require.NoError(t, repo.Create(ctx, newTask(id, base)))
+		}
+
+		errs, err := repo.DeleteMany(ctx, []model.TaskRef{
+			{ID: "any", Version: repository.AnyVersion},
+			{ID: "exact", Version: 1},
+			{ID: "stale", Version: 2},
+			{ID: "missing"},
+		})
+
+		require.NoError(t, err)
+		assert.Equal(t, []error{nil, nil, repository.ErrVersionConflict, repository.ErrTaskNotFound}, errs)
+		left, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"stale"}, taskIDs(left))
+		trashed, total, err := repo.FindDeleted(ctx, 0, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 2, total)
+		assert.ElementsMatch(t, []string{"any", "exact"}, taskIDs(trashed))
+	})
+
+	t.Run("CountByStatusAndPriority groups tasks", func(t *testing.T) {
+		repo := newRepo(t)
+		fixtures := []struct {
+			status   model.TaskStatus
+			priority model.TaskPriority
+		}{
+			{model.StatusActive, model.PriorityHigh},
+			{model.StatusActive, model.PriorityHigh},
+			{model.StatusActive, model.PriorityLow},
+			{model.StatusOverdue, model.PriorityHigh},
+		}
+		for i, f := range fixtures {
+			task := newTask(fmt.Sprintf("t%d", i), base)
+			task.Status = f.status
+			task.Priority = f.priority
+			require.NoError(t, repo.Create(ctx, task))
+		}
+
+		counts, err := repo.CountByStatusAndPriority(ctx)
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []model.TaskCount{
+			{Status: model.StatusActive, Priority: model.PriorityHigh, Count: 2},
+			{Status: model.StatusActive, Priority: model.PriorityLow, Count: 1},
+			{Status: model.StatusOverdue, Priority: model.PriorityHigh, Count: 1},
+		}, counts)
+	})
+}
+
+func taskIDs(tasks []*model.Task) []string {
+	ids := make([]string, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
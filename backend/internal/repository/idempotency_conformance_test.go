@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runIdempotencyRepositoryConformance is the behaviour every
+// repository.IdempotencyRepository implementation must provide. newRepo must
+// return an empty repository.
+func runIdempotencyRepositoryConformance(t *testing.T, newRepo func(t *testing.T) repository.IdempotencyRepository) {
+	// Database timestamps keep microseconds, so fixtures are truncated to match.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	ctx := context.Background()
+
+	newRecord := func(key string, createdAt time.Time) *model.IdempotencyRecord {
+		return &model.IdempotencyRecord{
+			Key:         key,
+			Fingerprint: "fingerprint-" + key,
+			CreatedAt:   createdAt,
+			ExpiresAt:   createdAt.Add(time.Minute),
+		}
+	}
+
+	t.Run("Find returns ErrIdempotencyKeyNotFound for unknown key", func(t *testing.T) {
+		repo := newRepo(t)
+
+		record, err := repo.Find(ctx, "missing", now)
+
+		assert.ErrorIs(t, err, repository.ErrIdempotencyKeyNotFound)
+		assert.Nil(t, record)
+	})
+
+	t.Run("Create claims a key and Update stores the response", func(t *testing.T) {
+		repo := newRepo(t)
+		record := newRecord("claimed", now)
+		require.NoError(t, repo.Create(ctx, record))
+
+		pending, err := repo.Find(ctx, "claimed", now)
+		require.NoError(t, err)
+		assert.Equal(t, 0, pending.StatusCode)
+
+		record.StatusCode = 201
+		record.Header = map[string]string{"Content-Type": "application/json", "ETag": `"1"`}
+		record.Body = []byte(`{"id":"1"}`)
+		record.ExpiresAt = now.Add(time.Hour)
+		require.NoError(t, repo.Update(ctx, record))
+		got, err := repo.Find(ctx, "claimed", now.Add(30*time.Minute))
+
+		require.NoError(t, err)
+		assert.Equal(t, "fingerprint-claimed", got.Fingerprint)
+		assert.Equal(t, 201, got.StatusCode)
+		assert.Equal(t, record.Header, got.Header)
+		assert.Equal(t, record.Body, got.Body)
+		assert.True(t, got.CreatedAt.Equal(now))
+		assert.True(t, got.ExpiresAt.Equal(now.Add(time.Hour)))
+	})
+
+	t.Run("Create returns ErrIdempotencyKeyExists while the key is live", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newRecord("live", now)))
+
+		err := repo.Create(ctx, newRecord("live", now.Add(time.Second)))
+
+		assert.ErrorIs(t, err, repository.ErrIdempotencyKeyExists)
+	})
+
+	t.Run("Create replaces an expired record", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newRecord("expired", now)))
+		replacement := newRecord("expired", now.Add(2*time.Minute))
+		replacement.Fingerprint = "other"
+
+		require.NoError(t, repo.Create(ctx, replacement))
+		got, err := repo.Find(ctx, "expired", now.Add(2*time.Minute))
+
+		require.NoError(t, err)
+		assert.Equal(t, "other", got.Fingerprint)
+	})
+
+	t.Run("Find ignores expired records", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newRecord("old", now)))
+
+		_, err := repo.Find(ctx, "old", now.Add(time.Minute))
+
+		assert.ErrorIs(t, err, repository.ErrIdempotencyKeyNotFound)
+	})
+
+	t.Run("Update returns ErrIdempotencyKeyNotFound for unknown key", func(t *testing.T) {
+		repo := newRepo(t)
+
+		err := repo.Update(ctx, newRecord("missing", now))
+
+		assert.ErrorIs(t, err, repository.ErrIdempotencyKeyNotFound)
+	})
+
+	t.Run("Delete releases the key", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newRecord("released", now)))
+
+		require.NoError(t, repo.Delete(ctx, "released", now))
+
+		assert.NoError(t, repo.Create(ctx, newRecord("released", now)))
+	})
+
+	t.Run("Update and Delete leave a newer claim alone", func(t *testing.T) {
+		repo := newRepo(t)
+		stale := newRecord("reclaimed", now)
+		require.NoError(t, repo.Create(ctx, stale))
+		require.NoError(t, repo.Create(ctx, newRecord("reclaimed", now.Add(2*time.Minute))))
+
+		stale.StatusCode = 201
+		stale.ExpiresAt = now.Add(time.Hour)
+		updateErr := repo.Update(ctx, stale)
+		deleteErr := repo.Delete(ctx, "reclaimed", now)
+
+		assert.ErrorIs(t, updateErr, repository.ErrIdempotencyKeyNotFound)
+		assert.ErrorIs(t, deleteErr, repository.ErrIdempotencyKeyNotFound)
+		got, err := repo.Find(ctx, "reclaimed", now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, 0, got.StatusCode)
+		assert.True(t, got.CreatedAt.Equal(now.Add(2*time.Minute)))
+	})
+
+	t.Run("DeleteExpired removes only expired records", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Create(ctx, newRecord("first", now.Add(-2*time.Minute))))
+		require.NoError(t, repo.Create(ctx, newRecord("second", now.Add(-time.Minute))))
+		require.NoError(t, repo.Create(ctx, newRecord("current", now)))
+
+		deleted, err := repo.DeleteExpired(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+		_, err = repo.Find(ctx, "current", now)
+		assert.NoError(t, err)
+	})
+}
+
+// TestIdempotencyMemoryRepository_Conformance runs the shared idempotency behaviour suite
+func TestIdempotencyMemoryRepository_Conformance(t *testing.T) {
+	runIdempotencyRepositoryConformance(t, func(t *testing.T) repository.IdempotencyRepository {
+		return NewIdempotencyMemoryRepository()
+	})
+}
+
+// TestIdempotencySQLiteRepository_Conformance runs the shared idempotency behaviour suite
+func TestIdempotencySQLiteRepository_Conformance(t *testing.T) {
+	runIdempotencyRepositoryConformance(t, func(t *testing.T) repository.IdempotencyRepository {
+		return NewIdempotencySQLiteRepository(newTestSQLiteDB(t))
+	})
+}
+
+// TestIdempotencyPgRepository_Conformance runs the shared idempotency behaviour suite against a
+// real PostgreSQL database. It needs an empty scratch database in TODO_TEST_POSTGRES_DSN.
+func TestIdempotencyPgRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TODO_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DROP TABLE IF EXISTS tasks, task_events, idempotency_keys, goose_db_version")
+	require.NoError(t, err)
+	applyMigrations(t, db, "postgres")
+
+	runIdempotencyRepositoryConformance(t, func(t *testing.T) repository.IdempotencyRepository {
+		_, err := db.Exec("TRUNCATE idempotency_keys")
+		require.NoError(t, err)
+		return NewIdempotencyPgRepository(db)
+	})
+}
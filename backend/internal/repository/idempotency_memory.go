@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// IdempotencyMemoryRepository keeps idempotency records in process memory. It
+// is safe for concurrent use.
+type IdempotencyMemoryRepository struct {
+	mu      sync.Mutex
+	records map[string]*model.IdempotencyRecord
+}
+
+func NewIdempotencyMemoryRepository() *IdempotencyMemoryRepository {
+	return &IdempotencyMemoryRepository{records: make(map[string]*model.IdempotencyRecord)}
+}
+
+func (r *IdempotencyMemoryRepository) Create(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if existing, exists := r.records[record.Key]; exists && existing.ExpiresAt.After(record.CreatedAt) {
+		return repository.ErrIdempotencyKeyExists
+	}
+	r.records[record.Key] = cloneIdempotencyRecord(record)
+	return nil
+}
+
+func (r *IdempotencyMemoryRepository) Find(ctx context.Context, key string, now time.Time) (*model.IdempotencyRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, exists := r.records[key]
+	if !exists || !record.ExpiresAt.After(now) {
+		return nil, repository.ErrIdempotencyKeyNotFound
+	}
+	return cloneIdempotencyRecord(record), nil
+}
+
+func (r *IdempotencyMemoryRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.records[record.Key]
+	if !exists || !existing.CreatedAt.Equal(record.CreatedAt) {
+		return repository.ErrIdempotencyKeyNotFound
+	}
+	updated := cloneIdempotencyRecord(record)
+	updated.Fingerprint = existing.Fingerprint
+	updated.CreatedAt = existing.CreatedAt
+	r.records[record.Key] = updated
+	return nil
+}
+
+func (r *IdempotencyMemoryRepository) Delete(ctx context.Context, key string, createdAt time.Time) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.records[key]
+	if !exists || !existing.CreatedAt.Equal(createdAt) {
+		return repository.ErrIdempotencyKeyNotFound
+	}
+	delete(r.records, key)
+	return nil
+}
+
+func (r *IdempotencyMemoryRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for key, record := range r.records {
+		if !record.ExpiresAt.After(now) {
+			delete(r.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func cloneIdempotencyRecord(record *model.IdempotencyRecord) *model.IdempotencyRecord {
+	clone := *record
+	clone.Header = maps.Clone(record.Header)
+	clone.Body = slices.Clone(record.Body)
+	return &clone
+}
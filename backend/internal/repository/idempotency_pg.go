@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+type IdempotencyPgRepository struct {
+	db *sql.DB
+}
+
+func NewIdempotencyPgRepository(db *sql.DB) *IdempotencyPgRepository {
+	return &IdempotencyPgRepository{db: db}
+}
+
+func (r *IdempotencyPgRepository) Create(ctx context.Context, record *model.IdempotencyRecord) error {
+	query := `
+		INSERT INTO idempotency_keys (key, fingerprint, status_code, response_header, response_body, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = EXCLUDED.status_code,
+		    response_header = EXCLUDED.response_header, response_body = EXCLUDED.response_body,
+		    created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= EXCLUDED.created_at
+	`
+	header, err := encodeResponseHeader(record.Header)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		record.Key,
+		record.Fingerprint,
+		record.StatusCode,
+		header,
+		record.Body,
+		record.CreatedAt,
+		record.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	claimed, err := keyClaimed(res)
+	if err != nil {
+		return err
+	}
+	if !claimed {
+		return repository.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+func (r *IdempotencyPgRepository) Find(ctx context.Context, key string, now time.Time) (*model.IdempotencyRecord, error) {
+	query := `
+		SELECT key, fingerprint, status_code, response_header, response_body, created_at, expires_at
+		FROM idempotency_keys WHERE key = $1 AND expires_at > $2
+	`
+	record, err := scanIdempotencyRecord(r.db.QueryRowContext(ctx, query, key, now))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *IdempotencyPgRepository) Update(ctx context.Context, record *model.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $1, response_header = $2, response_body = $3, expires_at = $4
+		WHERE key = $5 AND created_at = $6
+	`
+	header, err := encodeResponseHeader(record.Header)
+	if err != nil {
+		return err
+	}
+	res, err := r.db.ExecContext(ctx, query, record.StatusCode, header, record.Body, record.ExpiresAt, record.Key, record.CreatedAt)
+	if err != nil {
+		return err
+	}
+	updated, err := keyClaimed(res)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return repository.ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+func (r *IdempotencyPgRepository) Delete(ctx context.Context, key string, createdAt time.Time) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND created_at = $2`, key, createdAt)
+	if err != nil {
+		return err
+	}
+	deleted, err := keyClaimed(res)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return repository.ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+func (r *IdempotencyPgRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= $1`, now)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
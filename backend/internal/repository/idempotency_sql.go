@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"todo/internal/domain/model"
+)
+
+// encodeResponseHeader stores the header map as JSON text, or NULL when the
+// record has no headers yet.
+func encodeResponseHeader(header map[string]string) (interface{}, error) {
+	if len(header) == 0 {
+		return nil, nil
+	}
+	data, err := json.Marshal(header)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+func scanIdempotencyRecord(row rowScanner) (*model.IdempotencyRecord, error) {
+	var record model.IdempotencyRecord
+	var header sql.NullString
+
+	err := row.Scan(
+		&record.Key,
+		&record.Fingerprint,
+		&record.StatusCode,
+		&header,
+		&record.Body,
+		&record.CreatedAt,
+		&record.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if header.Valid {
+		if err := json.Unmarshal([]byte(header.String), &record.Header); err != nil {
+			return nil, err
+		}
+	}
+	return &record, nil
+}
+
+// keyClaimed reports whether a statement on an idempotency key affected a row.
+// An insert-or-replace affects none when a live record already holds the key,
+// an update or delete of a claim none when the claim is no longer held.
+func keyClaimed(res sql.Result) (bool, error) {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runTaskEventRepositoryConformance is the behaviour every
+// repository.TaskEventRepository implementation must provide. newRepo must
+// return an empty repository.
+func runTaskEventRepositoryConformance(t *testing.T, newRepo func(t *testing.T) repository.TaskEventRepository) {
+	// Database timestamps keep microseconds, so fixtures are truncated to match.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	ctx := context.Background()
+
+	newEvent := func(taskID string, eventType model.TaskEventType, version int64) *model.TaskEvent {
+		return &model.TaskEvent{
+			TaskID:    taskID,
+			Type:      eventType,
+			Origin:    model.OriginAPI,
+			Version:   version,
+			CreatedAt: now,
+		}
+	}
+
+	t.Run("FindByTaskID returns an empty history for unknown task", func(t *testing.T) {
+		repo := newRepo(t)
+
+		events, err := repo.FindByTaskID(ctx, "missing")
+
+		require.NoError(t, err)
+		assert.NotNil(t, events)
+		assert.Empty(t, events)
+	})
+
+	t.Run("Append stores events with ids and FindByTaskID lists them oldest first", func(t *testing.T) {
+		repo := newRepo(t)
+		created := newEvent("task", model.TaskEventCreated, 1)
+		created.RequestID = "req-1"
+		updated := newEvent("task", model.TaskEventUpdated, 2)
+		updated.Origin = model.OriginMacro
+		updated.Changes = []model.TaskFieldChange{
+			{Field: "title", Old: json.RawMessage(`"Old"`), New: json.RawMessage(`"New"`)},
+			{Field: "deadline", Old: json.RawMessage(`null`), New: json.RawMessage(`"2030-01-01T00:00:00Z"`)},
+		}
+		other := newEvent("other", model.TaskEventCreated, 1)
+
+		require.NoError(t, repo.Append(ctx, created, other))
+		require.NoError(t, repo.Append(ctx, updated))
+		events, err := repo.FindByTaskID(ctx, "task")
+
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.NotZero(t, created.ID)
+		assert.Less(t, created.ID, other.ID)
+		assert.Less(t, other.ID, updated.ID)
+		assert.Equal(t, created.ID, events[0].ID)
+		assert.Equal(t, model.TaskEventCreated, events[0].Type)
+		assert.Equal(t, "req-1", events[0].RequestID)
+		assert.Empty(t, events[0].Changes)
+		assert.Equal(t, updated.ID, events[1].ID)
+		assert.Equal(t, model.OriginMacro, events[1].Origin)
+		assert.Equal(t, int64(2), events[1].Version)
+		assert.Equal(t, updated.Changes, events[1].Changes)
+		assert.True(t, now.Equal(events[1].CreatedAt))
+	})
+
+	t.Run("DeleteByTaskIDs removes only the given histories", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.Append(ctx,
+			newEvent("gone", model.TaskEventCreated, 1),
+			newEvent("gone", model.TaskEventDeleted, 2),
+			newEvent("kept", model.TaskEventCreated, 1),
+		))
+
+		require.NoError(t, repo.DeleteByTaskIDs(ctx, []string{"gone", "missing"}))
+
+		gone, err := repo.FindByTaskID(ctx, "gone")
+		require.NoError(t, err)
+		kept, err := repo.FindByTaskID(ctx, "kept")
+		require.NoError(t, err)
+		assert.Empty(t, gone)
+		assert.Len(t, kept, 1)
+	})
+
+	t.Run("DeleteByTaskIDs accepts an empty list", func(t *testing.T) {
+		repo := newRepo(t)
+
+		assert.NoError(t, repo.DeleteByTaskIDs(ctx, nil))
+	})
+}
+
+// TestTaskEventMemoryRepository_Conformance runs the shared task history behaviour suite
+func TestTaskEventMemoryRepository_Conformance(t *testing.T) {
+	runTaskEventRepositoryConformance(t, func(t *testing.T) repository.TaskEventRepository {
+		return NewTaskEventMemoryRepository()
+	})
+}
+
+// TestTaskEventSQLiteRepository_Conformance runs the shared task history behaviour suite
+func TestTaskEventSQLiteRepository_Conformance(t *testing.T) {
+	runTaskEventRepositoryConformance(t, func(t *testing.T) repository.TaskEventRepository {
+		return NewTaskEventSQLiteRepository(newTestSQLiteDB(t))
+	})
+}
+
+// TestTaskEventPgRepository_Conformance runs the shared task history behaviour suite against a
+// real PostgreSQL database. It needs an empty scratch database in TODO_TEST_POSTGRES_DSN.
+func TestTaskEventPgRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TODO_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DROP TABLE IF EXISTS tasks, task_events, idempotency_keys, goose_db_version")
+	require.NoError(t, err)
+	applyMigrations(t, db, "postgres")
+
+	runTaskEventRepositoryConformance(t, func(t *testing.T) repository.TaskEventRepository {
+		_, err := db.Exec("TRUNCATE task_events")
+		require.NoError(t, err)
+		return NewTaskEventPgRepository(db)
+	})
+}
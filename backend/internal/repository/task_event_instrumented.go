@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// InstrumentedTaskEventRepository times and logs every call to the wrapped
+// repository like InstrumentedTaskRepository does. observer may be nil when
+// metrics are disabled.
+type InstrumentedTaskEventRepository struct {
+	next     repository.TaskEventRepository
+	observer QueryObserver
+	logger   *slog.Logger
+}
+
+func NewInstrumentedTaskEventRepository(next repository.TaskEventRepository, observer QueryObserver, logger *slog.Logger) *InstrumentedTaskEventRepository {
+	return &InstrumentedTaskEventRepository{next: next, observer: observer, logger: logger}
+}
+
+func (r *InstrumentedTaskEventRepository) Append(ctx context.Context, events ...*model.TaskEvent) error {
+	start := time.Now()
+	err := r.next.Append(ctx, events...)
+	observeCall(ctx, r.observer, r.logger, "Append", start, err)
+	return err
+}
+
+func (r *InstrumentedTaskEventRepository) FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error) {
+	start := time.Now()
+	events, err := r.next.FindByTaskID(ctx, taskID)
+	observeCall(ctx, r.observer, r.logger, "FindByTaskID", start, err)
+	return events, err
+}
+
+func (r *InstrumentedTaskEventRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []string) error {
+	start := time.Now()
+	err := r.next.DeleteByTaskIDs(ctx, taskIDs)
+	observeCall(ctx, r.observer, r.logger, "DeleteByTaskIDs", start, err)
+	return err
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestInstrumentedTaskEventRepository_Conformance checks that the decorator does not change behaviour
+func TestInstrumentedTaskEventRepository_Conformance(t *testing.T) {
+	runTaskEventRepositoryConformance(t, func(t *testing.T) repository.TaskEventRepository {
+		return NewInstrumentedTaskEventRepository(NewTaskEventMemoryRepository(), &recordingQueryObserver{}, slog.New(slog.DiscardHandler))
+	})
+}
+
+// TestInstrumentedTaskEventRepository_ObservesCalls checks that each call is reported with its outcome
+func TestInstrumentedTaskEventRepository_ObservesCalls(t *testing.T) {
+	// Arrange
+	observer := &recordingQueryObserver{}
+	repo := NewInstrumentedTaskEventRepository(NewTaskEventMemoryRepository(), observer, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// Act
+	_ = repo.Append(ctx, &model.TaskEvent{TaskID: "1", Type: model.TaskEventCreated, Version: 1, CreatedAt: time.Now()})
+	_, _ = repo.FindByTaskID(canceled, "1")
+
+	// Assert
+	assert.Equal(t, []observedQuery{
+		{method: "Append"},
+		{method: "FindByTaskID", err: context.Canceled},
+	}, observer.queries)
+}
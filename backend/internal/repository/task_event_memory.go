@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"todo/internal/domain/model"
+)
+
+// TaskEventMemoryRepository keeps task histories in process memory. It is
+// safe for concurrent use.
+type TaskEventMemoryRepository struct {
+	mu     sync.RWMutex
+	lastID int64
+	events map[string][]*model.TaskEvent
+	// undo is set inside a transaction and holds, for every task it wrote,
+	// the history stored before the first write, or nil if there was none.
+	undo map[string][]*model.TaskEvent
+}
+
+func NewTaskEventMemoryRepository() *TaskEventMemoryRepository {
+	return &TaskEventMemoryRepository{events: make(map[string][]*model.TaskEvent)}
+}
+
+func (r *TaskEventMemoryRepository) Append(ctx context.Context, events ...*model.TaskEvent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range events {
+		r.lastID++
+		event.ID = r.lastID
+		// Clip so that appending never writes into the backing array of a
+		// history a rollback may put back.
+		r.remember(event.TaskID)
+		r.events[event.TaskID] = append(slices.Clip(r.events[event.TaskID]), cloneTaskEvent(event))
+	}
+	return nil
+}
+
+func (r *TaskEventMemoryRepository) FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	events := make([]*model.TaskEvent, 0, len(r.events[taskID]))
+	for _, event := range r.events[taskID] {
+		events = append(events, cloneTaskEvent(event))
+	}
+	return events, nil
+}
+
+func (r *TaskEventMemoryRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, id := range taskIDs {
+		r.remember(id)
+		delete(r.events, id)
+	}
+	return nil
+}
+
+// Transaction lets fn write to the histories directly and remembers what
+// each write replaced; unless fn returns nil the replaced histories are put
+// back. Unlike TaskMemoryRepository.Transaction it returns every error of
+// fn, ErrRollback included, so it can be nested inside a task transaction.
+// fn must use tx, not r, or it deadlocks.
+func (r *TaskEventMemoryRepository) Transaction(ctx context.Context, fn func(tx *TaskEventMemoryRepository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &TaskEventMemoryRepository{lastID: r.lastID, events: r.events, undo: make(map[string][]*model.TaskEvent)}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+		}
+	}()
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	r.lastID = tx.lastID
+	return nil
+}
+
+func (r *TaskEventMemoryRepository) remember(taskID string) {
+	if r.undo == nil {
+		return
+	}
+	if _, seen := r.undo[taskID]; !seen {
+		r.undo[taskID] = r.events[taskID]
+	}
+}
+
+func (r *TaskEventMemoryRepository) rollback() {
+	for taskID, events := range r.undo {
+		if events == nil {
+			delete(r.events, taskID)
+		} else {
+			r.events[taskID] = events
+		}
+	}
+}
+
+func cloneTaskEvent(event *model.TaskEvent) *model.TaskEvent {
+	clone := *event
+	clone.Changes = slices.Clone(event.Changes)
+	return &clone
+}
@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"todo/internal/domain/model"
+)
+
+type TaskEventPgRepository struct {
+	db dbtx
+}
+
+func NewTaskEventPgRepository(db *sql.DB) *TaskEventPgRepository {
+	return &TaskEventPgRepository{db: db}
+}
+
+func (r *TaskEventPgRepository) Append(ctx context.Context, events ...*model.TaskEvent) error {
+	return insertTaskEvents(ctx, r.db, events, pgPlaceholder, func(t time.Time) interface{} { return t })
+}
+
+func (r *TaskEventPgRepository) FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error) {
+	query := `
+		SELECT id, task_id, type, origin, version, changes, request_id, created_at
+		FROM task_events WHERE task_id = $1
+		ORDER BY id
+	`
+	return queryTaskEvents(ctx, r.db, query, taskID)
+}
+
+func (r *TaskEventPgRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []string) error {
+	return deleteTaskEvents(ctx, r.db, taskIDs, pgPlaceholder)
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTaskEventPgRepository_Append checks that events are written by one multi-row INSERT and get
+// their ids in the order they were passed, whatever order RETURNING lists them in
+func TestTaskEventPgRepository_Append(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskEventPgRepository(db)
+	now := time.Now().UTC()
+	first := &model.TaskEvent{TaskID: "1", Type: model.TaskEventCreated, Origin: model.OriginAPI, Version: 1, CreatedAt: now}
+	second := &model.TaskEvent{TaskID: "2", Type: model.TaskEventCreated, Origin: model.OriginAPI, Version: 1, CreatedAt: now}
+
+	mock.ExpectQuery("INSERT INTO task_events \\(task_id, type, origin, version, changes, request_id, created_at\\) " +
+		"VALUES \\(\\$1, \\$2, \\$3, \\$4, \\$5, \\$6, \\$7\\), \\(\\$8, \\$9, \\$10, \\$11, \\$12, \\$13, \\$14\\) RETURNING id").
+		WithArgs("1", model.TaskEventCreated, model.OriginAPI, int64(1), "[]", nil, now,
+			"2", model.TaskEventCreated, model.OriginAPI, int64(1), "[]", nil, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(8).AddRow(7))
+
+	// Act
+	err := repo.Append(context.Background(), first, second)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), first.ID)
+	assert.Equal(t, int64(8), second.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"slices"
+	"time"
+	"todo/internal/domain/model"
+)
+
+// taskEventDeleteChunk bounds the IN list of one DELETE, well below the
+// parameter limits of both databases.
+const taskEventDeleteChunk = 500
+
+// taskEventInsertChunk bounds the rows of one INSERT. At seven parameters a
+// row it stays far below the parameter limits of both databases; it is kept
+// small because the SQLite driver binds parameters in quadratic time.
+const taskEventInsertChunk = 50
+
+// encodeTaskChanges stores the changes of an event as JSON text.
+func encodeTaskChanges(changes []model.TaskFieldChange) (string, error) {
+	if changes == nil {
+		changes = []model.TaskFieldChange{}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// nullableString stores an empty string as NULL.
+func nullableString(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+func scanTaskEvent(row rowScanner) (*model.TaskEvent, error) {
+	var event model.TaskEvent
+	var changes string
+	var requestID sql.NullString
+
+	err := row.Scan(
+		&event.ID,
+		&event.TaskID,
+		&event.Type,
+		&event.Origin,
+		&event.Version,
+		&changes,
+		&requestID,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(changes), &event.Changes); err != nil {
+		return nil, err
+	}
+	event.RequestID = requestID.String
+	return &event, nil
+}
+
+func queryTaskEvents(ctx context.Context, db dbtx, query string, args ...interface{}) ([]*model.TaskEvent, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []*model.TaskEvent{}
+	for rows.Next() {
+		event, err := scanTaskEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// insertTaskEvents writes the events with one multi-row INSERT per chunk and
+// sets their IDs. RETURNING lists the rows in no promised order, but the IDs
+// are handed out in the order of the VALUES list, so sorted they line up with
+// the chunk. encodeTime converts the creation times for the database.
+func insertTaskEvents(ctx context.Context, db dbtx, events []*model.TaskEvent, placeholder func(n int) string, encodeTime func(t time.Time) interface{}) error {
+	for start := 0; start < len(events); start += taskEventInsertChunk {
+		chunk := events[start:min(start+taskEventInsertChunk, len(events))]
+		args := make([]interface{}, 0, len(chunk)*7)
+		for _, event := range chunk {
+			changes, err := encodeTaskChanges(event.Changes)
+			if err != nil {
+				return err
+			}
+			args = append(args,
+				event.TaskID,
+				event.Type,
+				event.Origin,
+				event.Version,
+				changes,
+				nullableString(event.RequestID),
+				encodeTime(event.CreatedAt),
+			)
+		}
+		query := `
+			INSERT INTO task_events (task_id, type, origin, version, changes, request_id, created_at)
+			VALUES ` + valuesRows(len(chunk), 7, placeholder) + `
+			RETURNING id
+		`
+		ids, err := queryEventIDs(ctx, db, query, args...)
+		if err != nil {
+			return err
+		}
+		slices.Sort(ids)
+		for i, event := range chunk {
+			event.ID = ids[i]
+		}
+	}
+	return nil
+}
+
+func queryEventIDs(ctx context.Context, db dbtx, query string, args ...interface{}) ([]int64, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// deleteTaskEvents removes the history of taskIDs in chunks.
+func deleteTaskEvents(ctx context.Context, db dbtx, taskIDs []string, placeholder func(n int) string) error {
+	for start := 0; start < len(taskIDs); start += taskEventDeleteChunk {
+		chunk := taskIDs[start:min(start+taskEventDeleteChunk, len(taskIDs))]
+		args := make([]interface{}, len(chunk))
+		for i, id := range chunk {
+			args[i] = id
+		}
+		query := `DELETE FROM task_events WHERE task_id IN (` + placeholderList(len(chunk), placeholder) + `)`
+		if _, err := db.ExecContext(ctx, query, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
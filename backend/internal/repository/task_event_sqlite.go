@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"todo/internal/domain/model"
+)
+
+type TaskEventSQLiteRepository struct {
+	db dbtx
+}
+
+func NewTaskEventSQLiteRepository(db *sql.DB) *TaskEventSQLiteRepository {
+	return &TaskEventSQLiteRepository{db: db}
+}
+
+func (r *TaskEventSQLiteRepository) Append(ctx context.Context, events ...*model.TaskEvent) error {
+	return insertTaskEvents(ctx, r.db, events, sqlitePlaceholder, func(t time.Time) interface{} { return sqliteTime(t) })
+}
+
+func (r *TaskEventSQLiteRepository) FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error) {
+	query := `
+		SELECT id, task_id, type, origin, version, changes, request_id, created_at
+		FROM task_events WHERE task_id = ?1
+		ORDER BY id
+	`
+	return queryTaskEvents(ctx, r.db, query, taskID)
+}
+
+func (r *TaskEventSQLiteRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []string) error {
+	return deleteTaskEvents(ctx, r.db, taskIDs, sqlitePlaceholder)
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedTaskEventRepository records a span for every call to the wrapped
+// repository like TracedTaskRepository does.
+type TracedTaskEventRepository struct {
+	next   repository.TaskEventRepository
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+	kind   trace.SpanKind
+}
+
+// NewTracedTaskEventRepository wraps next. system is the database behind it,
+// as for NewTracedTaskRepository.
+func NewTracedTaskEventRepository(next repository.TaskEventRepository, tp trace.TracerProvider, system string) *TracedTaskEventRepository {
+	r := &TracedTaskEventRepository{next: next, tracer: tp.Tracer(tracerName), kind: trace.SpanKindInternal}
+	if system != "" {
+		r.attrs = append(r.attrs, semconv.DBSystemNameKey.String(system))
+		r.kind = trace.SpanKindClient
+	}
+	return r
+}
+
+func (r *TracedTaskEventRepository) Append(ctx context.Context, events ...*model.TaskEvent) error {
+	ctx, span := r.start(ctx, "Append", attribute.Int("task_event.count", len(events)))
+	err := r.next.Append(ctx, events...)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskEventRepository) FindByTaskID(ctx context.Context, taskID string) ([]*model.TaskEvent, error) {
+	ctx, span := r.start(ctx, "FindByTaskID", attribute.String("task.id", taskID))
+	events, err := r.next.FindByTaskID(ctx, taskID)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(events)))
+	endSpan(span, err)
+	return events, err
+}
+
+func (r *TracedTaskEventRepository) DeleteByTaskIDs(ctx context.Context, taskIDs []string) error {
+	ctx, span := r.start(ctx, "DeleteByTaskIDs", attribute.Int("task.count", len(taskIDs)))
+	err := r.next.DeleteByTaskIDs(ctx, taskIDs)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskEventRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBOperationName(method))
+	return r.tracer.Start(ctx, "TaskEventRepository."+method,
+		trace.WithSpanKind(r.kind),
+		trace.WithAttributes(r.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracedTaskEventRepository_Conformance checks that the decorator does not change behaviour
+func TestTracedTaskEventRepository_Conformance(t *testing.T) {
+	runTaskEventRepositoryConformance(t, func(t *testing.T) repository.TaskEventRepository {
+		tp := sdktrace.NewTracerProvider()
+		return NewTracedTaskEventRepository(NewTaskEventMemoryRepository(), tp, "")
+	})
+}
+
+// TestTracedUnitOfWork_TracesEvents checks that the history written in a transaction gets its own spans
+func TestTracedUnitOfWork_TracesEvents(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	uow := NewTracedUnitOfWork(NewMemoryUnitOfWork(NewTaskMemoryRepository(), NewTaskEventMemoryRepository()), tp, "")
+	ctx := context.Background()
+
+	// Act
+	err := uow.WithTx(ctx, func(repos repository.Repositories) error {
+		task := newTestTask()
+		if err := repos.Tasks.Create(ctx, task); err != nil {
+			return err
+		}
+		return repos.Events.Append(ctx, &model.TaskEvent{TaskID: task.ID, Type: model.TaskEventCreated, Version: 1, CreatedAt: time.Now()})
+	})
+
+	// Assert
+	require.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "TaskRepository.Create", spans[0].Name())
+	assert.Equal(t, "TaskEventRepository.Append", spans[1].Name())
+	assert.Equal(t, "UnitOfWork.WithTx", spans[2].Name())
+}
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// QueryObserver records how long one repository call took and whether it failed.
+type QueryObserver interface {
+	ObserveQuery(method string, elapsed time.Duration, err error)
+}
+
+// InstrumentedTaskRepository times and logs every call to the wrapped
+// repository. Calls are logged at debug level and failures at error level.
+// ErrTaskNotFound, ErrTaskExists and ErrVersionConflict are expected answers
+// rather than failed queries, so they are reported as a success. observer may be nil when metrics are disabled.
+type InstrumentedTaskRepository struct {
+	next     repository.TaskRepository
+	observer QueryObserver
+	logger   *slog.Logger
+}
+
+func NewInstrumentedTaskRepository(next repository.TaskRepository, observer QueryObserver, logger *slog.Logger) *InstrumentedTaskRepository {
+	return &InstrumentedTaskRepository{next: next, observer: observer, logger: logger}
+}
+
+func (r *InstrumentedTaskRepository) Create(ctx context.Context, task *model.Task) error {
+	start := time.Now()
+	err := r.next.Create(ctx, task)
+	r.observe(ctx, "Create", start, err)
+	return err
+}
+
+func (r *InstrumentedTaskRepository) Update(ctx context.Context, task *model.Task) error {
+	start := time.Now()
+	err := r.next.Update(ctx, task)
+	r.observe(ctx, "Update", start, err)
+	return err
+}
+
+func (r *InstrumentedTaskRepository) Delete(ctx context.Context, id string, version int64) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id, version)
+	r.observe(ctx, "Delete", start, err)
+	return err
+}
+
+func (r *InstrumentedTaskRepository) CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	start := time.Now()
+	errs, err := r.next.CreateMany(ctx, tasks)
+	r.observe(ctx, "CreateMany", start, err)
+	return errs, err
+}
+
+func (r *InstrumentedTaskRepository) UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	start := time.Now()
+	errs, err := r.next.UpdateMany(ctx, tasks)
+	r.observe(ctx, "UpdateMany", start, err)
+	return errs, err
+}
+
+func (r *InstrumentedTaskRepository) DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error) {
+	start := time.Now()
+	errs, err := r.next.DeleteMany(ctx, refs)
+	r.observe(ctx, "DeleteMany", start, err)
+	return errs, err
+}
+
+func (r *InstrumentedTaskRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	start := time.Now()
+	task, err := r.next.FindByID(ctx, id)
+	r.observe(ctx, "FindByID", start, err)
+	return task, err
+}
+
+func (r *InstrumentedTaskRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error) {
+	start := time.Now()
+	tasks, err := r.next.FindByIDs(ctx, ids)
+	r.observe(ctx, "FindByIDs", start, err)
+	return tasks, err
+}
+
+func (r *InstrumentedTaskRepository) FindAll(ctx context.Context) ([]*model.Task, error) {
+	start := time.Now()
+	tasks, err := r.next.FindAll(ctx)
+	r.observe(ctx, "FindAll", start, err)
+	return tasks, err
+}
+
+func (r *InstrumentedTaskRepository) FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	start := time.Now()
+	tasks, total, err := r.next.FindWithFilter(ctx, filter)
+	r.observe(ctx, "FindWithFilter", start, err)
+	return tasks, total, err
+}
+
+func (r *InstrumentedTaskRepository) FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error) {
+	start := time.Now()
+	tasks, err := r.next.FindAfter(ctx, filter, after, limit)
+	r.observe(ctx, "FindAfter", start, err)
+	return tasks, err
+}
+
+func (r *InstrumentedTaskRepository) CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error) {
+	start := time.Now()
+	counts, err := r.next.CountByStatusAndPriority(ctx)
+	r.observe(ctx, "CountByStatusAndPriority", start, err)
+	return counts, err
+}
+
+func (r *InstrumentedTaskRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	start := time.Now()
+	task, err := r.next.FindDeletedByID(ctx, id)
+	r.observe(ctx, "FindDeletedByID", start, err)
+	return task, err
+}
+
+func (r *InstrumentedTaskRepository) FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error) {
+	start := time.Now()
+	tasks, total, err := r.next.FindDeleted(ctx, offset, limit)
+	r.observe(ctx, "FindDeleted", start, err)
+	return tasks, total, err
+}
+
+func (r *InstrumentedTaskRepository) Restore(ctx context.Context, task *model.Task) error {
+	start := time.Now()
+	err := r.next.Restore(ctx, task)
+	r.observe(ctx, "Restore", start, err)
+	return err
+}
+
+func (r *InstrumentedTaskRepository) FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	start := time.Now()
+	tasks, err := r.next.FindSubtasks(ctx, parentID)
+	r.observe(ctx, "FindSubtasks", start, err)
+	return tasks, err
+}
+
+func (r *InstrumentedTaskRepository) CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error) {
+	start := time.Now()
+	progress, err := r.next.CountSubtasks(ctx, parentIDs)
+	r.observe(ctx, "CountSubtasks", start, err)
+	return progress, err
+}
+
+func (r *InstrumentedTaskRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	start := time.Now()
+	purged, err := r.next.PurgeDeleted(ctx, before)
+	r.observe(ctx, "PurgeDeleted", start, err)
+	return purged, err
+}
+
+func (r *InstrumentedTaskRepository) MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error) {
+	start := time.Now()
+	tasks, err := r.next.MarkOverdue(ctx, now)
+	r.observe(ctx, "MarkOverdue", start, err)
+	return tasks, err
+}
+
+func (r *InstrumentedTaskRepository) observe(ctx context.Context, method string, start time.Time, err error) {
+	observeCall(ctx, r.observer, r.logger, method, start, err)
+}
+
+func observeCall(ctx context.Context, observer QueryObserver, logger *slog.Logger, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	if isExpectedAnswer(err) {
+		err = nil
+	}
+	if observer != nil {
+		observer.ObserveQuery(method, elapsed, err)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "repository call failed", slog.String("method", method), slog.Duration("duration", elapsed), slog.Any("error", err))
+		return
+	}
+	logger.DebugContext(ctx, "repository call", slog.String("method", method), slog.Duration("duration", elapsed))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observedQuery struct {
+	method string
+	err    error
+}
+
+type recordingQueryObserver struct {
+	queries []observedQuery
+}
+
+func (o *recordingQueryObserver) ObserveQuery(method string, elapsed time.Duration, err error) {
+	o.queries = append(o.queries, observedQuery{method: method, err: err})
+}
+
+// TestInstrumentedTaskRepository_Conformance checks that the decorator does not change behaviour
+func TestInstrumentedTaskRepository_Conformance(t *testing.T) {
+	runTaskRepositoryConformance(t, func(t *testing.T) repository.TaskRepository {
+		return NewInstrumentedTaskRepository(NewTaskMemoryRepository(), &recordingQueryObserver{}, slog.New(slog.DiscardHandler))
+	})
+}
+
+// TestInstrumentedTaskRepository_ObservesCalls checks that each call is reported with its outcome
+func TestInstrumentedTaskRepository_ObservesCalls(t *testing.T) {
+	// Arrange
+	observer := &recordingQueryObserver{}
+	repo := NewInstrumentedTaskRepository(NewTaskMemoryRepository(), observer, slog.New(slog.DiscardHandler))
+	ctx := context.Background()
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// Act
+	_ = repo.Create(ctx, newTestTask())
+	_, _ = repo.FindByID(ctx, "missing")
+	_, _ = repo.FindAll(canceled)
+
+	// Assert
+	assert.Equal(t, []observedQuery{
+		{method: "Create"},
+		{method: "FindByID"},
+		{method: "FindAll", err: context.Canceled},
+	}, observer.queries)
+}
@@ -0,0 +1,573 @@
+package repository
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// TaskMemoryRepository keeps tasks in process memory. It is safe for
+// concurrent use and mirrors the ordering of TaskPgRepository, which makes it
+// suitable for demos, local development and tests.
+type TaskMemoryRepository struct {
+	mu    sync.RWMutex
+	tasks map[string]*model.Task
+	// undo is set inside a transaction and holds, for every ID it wrote, the
+	// task stored before the first write, or nil if there was none.
+	undo map[string]*model.Task
+}
+
+func NewTaskMemoryRepository() *TaskMemoryRepository {
+	return &TaskMemoryRepository{tasks: make(map[string]*model.Task)}
+}
+
+func (r *TaskMemoryRepository) Create(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(task)
+}
+
+func (r *TaskMemoryRepository) Update(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.update(task)
+}
+
+func (r *TaskMemoryRepository) Delete(ctx context.Context, id string, version int64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.delete(id, version)
+}
+
+func (r *TaskMemoryRepository) CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return eachTask(tasks, r.create), nil
+}
+
+func (r *TaskMemoryRepository) UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return eachTask(tasks, r.update), nil
+}
+
+func (r *TaskMemoryRepository) DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.deleteRefs(refs), nil
+}
+
+// Transaction lets fn write to the tasks directly and remembers what each
+// write replaced; unless fn returns nil the replaced tasks are put back.
+// Other callers wait until the transaction ends, so transactions are
+// serializable. fn must use tx, not r, or it deadlocks.
+func (r *TaskMemoryRepository) Transaction(ctx context.Context, fn func(tx *TaskMemoryRepository) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	tx := &TaskMemoryRepository{tasks: r.tasks, undo: make(map[string]*model.Task)}
+	committed := false
+	defer func() {
+		if !committed {
+			tx.rollback()
+		}
+	}()
+	if err := fn(tx); err != nil {
+		if errors.Is(err, repository.ErrRollback) {
+			return nil
+		}
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// put and remove are the only writes to r.tasks; inside a transaction they
+// remember the task they replace. Stored tasks are never modified in place,
+// so the replaced pointer is all rollback needs.
+
+func (r *TaskMemoryRepository) put(task *model.Task) {
+	r.remember(task.ID)
+	r.tasks[task.ID] = task
+}
+
+func (r *TaskMemoryRepository) remove(id string) {
+	r.remember(id)
+	delete(r.tasks, id)
+}
+
+func (r *TaskMemoryRepository) remember(id string) {
+	if r.undo == nil {
+		return
+	}
+	if _, seen := r.undo[id]; !seen {
+		r.undo[id] = r.tasks[id]
+	}
+}
+
+func (r *TaskMemoryRepository) rollback() {
+	for id, task := range r.undo {
+		if task == nil {
+			delete(r.tasks, id)
+		} else {
+			r.tasks[id] = task
+		}
+	}
+}
+
+// create, update and delete hold the write rules and expect r.mu to be held.
+
+func (r *TaskMemoryRepository) create(task *model.Task) error {
+	if _, exists := r.tasks[task.ID]; exists {
+		return repository.ErrTaskExists
+	}
+	task.Version = 1
+	r.put(cloneTask(task))
+	return nil
+}
+
+func (r *TaskMemoryRepository) update(task *model.Task) error {
+	existing, exists := r.live(task.ID)
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+	if existing.Version != task.Version {
+		return repository.ErrVersionConflict
+	}
+	task.Version++
+	updated := cloneTask(task)
+	updated.CreatedAt = existing.CreatedAt
+	updated.ParentID = existing.ParentID
+	updated.DeletedAt = nil
+	r.put(updated)
+	return nil
+}
+
+func (r *TaskMemoryRepository) delete(id string, version int64) error {
+	existing, exists := r.live(id)
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+	if version != repository.AnyVersion && existing.Version != version {
+		return repository.ErrVersionConflict
+	}
+	deleted := cloneTask(existing)
+	now := time.Now().UTC()
+	deleted.DeletedAt = &now
+	deleted.Version++
+	r.put(deleted)
+	return nil
+}
+
+func (r *TaskMemoryRepository) deleteRefs(refs []model.TaskRef) []error {
+	if len(refs) == 0 {
+		return nil
+	}
+	errs := make([]error, len(refs))
+	for i, ref := range refs {
+		errs[i] = r.delete(ref.ID, ref.Version)
+	}
+	return errs
+}
+
+// live returns the stored task unless it is missing or in the trash.
+func (r *TaskMemoryRepository) live(id string) (*model.Task, bool) {
+	task, exists := r.tasks[id]
+	if !exists || task.DeletedAt != nil {
+		return nil, false
+	}
+	return task, true
+}
+
+func eachTask(tasks []*model.Task, write func(task *model.Task) error) []error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		errs[i] = write(task)
+	}
+	return errs
+}
+
+func (r *TaskMemoryRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, exists := r.live(id)
+	if !exists {
+		return nil, repository.ErrTaskNotFound
+	}
+	return cloneTask(task), nil
+}
+
+func (r *TaskMemoryRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tasks := make([]*model.Task, 0, len(ids))
+	for _, id := range ids {
+		if task, exists := r.live(id); exists {
+			tasks = append(tasks, cloneTask(task))
+		}
+	}
+	return tasks, nil
+}
+
+func (r *TaskMemoryRepository) FindAll(ctx context.Context) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return r.sorted(&model.TaskFilter{}), nil
+}
+
+func (r *TaskMemoryRepository) FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	tasks := r.sorted(filter)
+	total := len(tasks)
+
+	start := (filter.Page - 1) * filter.PageSize
+	if start > total {
+		start = total
+	}
+	end := start + filter.PageSize
+	if end > total {
+		end = total
+	}
+	return tasks[start:end], total, nil
+}
+
+func (r *TaskMemoryRepository) FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	tasks := r.sorted(filter)
+
+	start := 0
+	if after != nil {
+		position := &model.Task{
+			ID:        after.ID,
+			Deadline:  after.Deadline,
+			CreatedAt: after.CreatedAt,
+			Priority:  after.Priority,
+		}
+		start = sort.Search(len(tasks), func(i int) bool {
+			return compareTasks(filter, tasks[i], position) > 0
+		})
+	}
+	end := start + limit
+	if end > len(tasks) {
+		end = len(tasks)
+	}
+	return tasks[start:end], nil
+}
+
+func (r *TaskMemoryRepository) CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	type key struct {
+		status   model.TaskStatus
+		priority model.TaskPriority
+	}
+	byKey := make(map[key]int)
+	for _, t := range r.tasks {
+		if t.DeletedAt == nil {
+			byKey[key{t.Status, t.Priority}]++
+		}
+	}
+	counts := make([]model.TaskCount, 0, len(byKey))
+	for k, n := range byKey {
+		counts = append(counts, model.TaskCount{Status: k.status, Priority: k.priority, Count: n})
+	}
+	return counts, nil
+}
+
+func (r *TaskMemoryRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	task, exists := r.tasks[id]
+	if !exists || task.DeletedAt == nil {
+		return nil, repository.ErrTaskNotFound
+	}
+	return cloneTask(task), nil
+}
+
+func (r *TaskMemoryRepository) FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, 0, err
+	}
+	r.mu.RLock()
+	tasks := make([]*model.Task, 0)
+	for _, t := range r.tasks {
+		if t.DeletedAt != nil {
+			tasks = append(tasks, cloneTask(t))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if c := tasks[i].DeletedAt.Compare(*tasks[j].DeletedAt); c != 0 {
+			return c > 0
+		}
+		return tasks[i].ID > tasks[j].ID
+	})
+	total := len(tasks)
+	start := min(offset, total)
+	end := min(start+limit, total)
+	return tasks[start:end], total, nil
+}
+
+func (r *TaskMemoryRepository) Restore(ctx context.Context, task *model.Task) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	existing, exists := r.tasks[task.ID]
+	if !exists || existing.DeletedAt == nil {
+		return repository.ErrTaskNotFound
+	}
+	if existing.Version != task.Version {
+		return repository.ErrVersionConflict
+	}
+	restored := cloneTask(existing)
+	restored.Status = task.Status
+	restored.UpdatedAt = task.UpdatedAt
+	restored.ParentID = task.ParentID
+	restored.DeletedAt = nil
+	restored.Version++
+	r.put(restored)
+	task.Version = restored.Version
+	task.DeletedAt = nil
+	return nil
+}
+
+func (r *TaskMemoryRepository) FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	tasks := make([]*model.Task, 0)
+	for _, t := range r.tasks {
+		if t.DeletedAt == nil && t.ParentID != nil && *t.ParentID == parentID {
+			tasks = append(tasks, cloneTask(t))
+		}
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		if c := tasks[i].CreatedAt.Compare(tasks[j].CreatedAt); c != 0 {
+			return c < 0
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+func (r *TaskMemoryRepository) CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	wanted := make(map[string]bool, len(parentIDs))
+	for _, id := range parentIDs {
+		wanted[id] = true
+	}
+	progress := make(map[string]model.TaskProgress)
+	for _, t := range r.tasks {
+		if t.DeletedAt != nil || t.ParentID == nil || !wanted[*t.ParentID] {
+			continue
+		}
+		p := progress[*t.ParentID]
+		p.Total++
+		if t.IsCompleted {
+			p.Done++
+		}
+		progress[*t.ParentID] = p
+	}
+	return progress, nil
+}
+
+func (r *TaskMemoryRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	purged := []string{}
+	for id, t := range r.tasks {
+		if t.DeletedAt != nil && t.DeletedAt.Before(before) {
+			r.remove(id)
+			purged = append(purged, id)
+		}
+	}
+	// Like the parent_id foreign key, a purge detaches the subtasks left
+	// behind.
+	for _, t := range r.tasks {
+		if t.ParentID != nil && slices.Contains(purged, *t.ParentID) {
+			detached := cloneTask(t)
+			detached.ParentID = nil
+			r.put(detached)
+		}
+	}
+	slices.Sort(purged)
+	return purged, nil
+}
+
+func (r *TaskMemoryRepository) MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	marked := []*model.Task{}
+	for _, t := range r.tasks {
+		if t.DeletedAt != nil || t.IsCompleted || t.Status != model.StatusActive || t.Deadline == nil || !t.Deadline.Before(now) {
+			continue
+		}
+		overdue := cloneTask(t)
+		overdue.Status = model.StatusOverdue
+		overdue.UpdatedAt = &now
+		overdue.Version++
+		r.put(overdue)
+		marked = append(marked, cloneTask(overdue))
+	}
+	return marked, nil
+}
+
+// sorted returns copies of the tasks matching the filter in the order the
+// filter asks for.
+func (r *TaskMemoryRepository) sorted(filter *model.TaskFilter) []*model.Task {
+	r.mu.RLock()
+	tasks := make([]*model.Task, 0, len(r.tasks))
+	for _, t := range r.tasks {
+		if t.DeletedAt != nil || t.ParentID != nil {
+			continue
+		}
+		if filter.Status != "" && string(t.Status) != filter.Status {
+			continue
+		}
+		if filter.Priority != "" && string(t.Priority) != filter.Priority {
+			continue
+		}
+		tasks = append(tasks, cloneTask(t))
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return compareTasks(filter, tasks[i], tasks[j]) < 0
+	})
+	return tasks
+}
+
+// compareTasks orders tasks the same way buildTaskOrderBy does: a missing
+// deadline sorts after every deadline, and the id breaks ties in the same
+// direction as the sort key.
+func compareTasks(filter *model.TaskFilter, a, b *model.Task) int {
+	var c int
+	switch filter.SortBy {
+	case "deadline":
+		c = compareDeadlines(a.Deadline, b.Deadline)
+	case "priority":
+		c = cmp.Compare(priorityRanks[a.Priority], priorityRanks[b.Priority])
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if filter.IsDescending() {
+		return -c
+	}
+	return c
+}
+
+func compareDeadlines(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	default:
+		return a.Compare(*b)
+	}
+}
+
+// cloneTask copies a task for storage or for a caller. Progress is computed
+// on every read, so it is never stored.
+func cloneTask(task *model.Task) *model.Task {
+	clone := *task
+	clone.Progress = nil
+	if task.Description != nil {
+		description := *task.Description
+		clone.Description = &description
+	}
+	if task.Deadline != nil {
+		deadline := *task.Deadline
+		clone.Deadline = &deadline
+	}
+	if task.UpdatedAt != nil {
+		updatedAt := *task.UpdatedAt
+		clone.UpdatedAt = &updatedAt
+	}
+	if task.DeletedAt != nil {
+		deletedAt := *task.DeletedAt
+		clone.DeletedAt = &deletedAt
+	}
+	if task.ParentID != nil {
+		parentID := *task.ParentID
+		clone.ParentID = &parentID
+	}
+	return &clone
+}
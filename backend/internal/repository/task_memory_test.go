@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTaskMemoryRepository_Conformance runs the shared repository behaviour suite
+func TestTaskMemoryRepository_Conformance(t *testing.T) {
+	runTaskRepositoryConformance(t, func(t *testing.T) repository.TaskRepository {
+		return NewTaskMemoryRepository()
+	})
+}
+
+// TestTaskMemoryRepository_ReturnsCopies checks that callers cannot change stored tasks without calling Update
+func TestTaskMemoryRepository_ReturnsCopies(t *testing.T) {
+	// Arrange
+	repo := NewTaskMemoryRepository()
+	task := newTestTask()
+	_ = repo.Create(context.Background(), task)
+
+	// Act
+	task.Title = "Changed after create"
+	found, _ := repo.FindByID(context.Background(), task.ID)
+	found.Title = "Changed after find"
+	again, err := repo.FindByID(context.Background(), task.ID)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Task", again.Title)
+}
+
+// TestTaskMemoryRepository_ConcurrentAccess checks that concurrent writers and readers do not race
+func TestTaskMemoryRepository_ConcurrentAccess(t *testing.T) {
+	// Arrange
+	repo := NewTaskMemoryRepository()
+	ctx := context.Background()
+	var wg sync.WaitGroup
+
+	// Act
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			task := &model.Task{ID: string(rune('A' + i)), Title: "Task", CreatedAt: time.Now()}
+			_ = repo.Create(ctx, task)
+			_, _, _ = repo.FindWithFilter(ctx, &model.TaskFilter{Page: 1, PageSize: 10})
+			_ = repo.Update(ctx, task)
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	tasks, err := repo.FindAll(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 50)
+}
+
+// TestTaskMemoryRepository_TransactionRollback checks that a failed transaction puts back every task
+// it wrote, including one it wrote twice, and drops the tasks it created
+func TestTaskMemoryRepository_TransactionRollback(t *testing.T) {
+	// Arrange
+	repo := NewTaskMemoryRepository()
+	ctx := context.Background()
+	kept := newTestTask()
+	_ = repo.Create(ctx, kept)
+	failed := errors.New("failed")
+
+	// Act
+	err := repo.Transaction(ctx, func(tx *TaskMemoryRepository) error {
+		edited := *kept
+		edited.Title = "Changed in the transaction"
+		if err := tx.Update(ctx, &edited); err != nil {
+			return err
+		}
+		if err := tx.Delete(ctx, kept.ID, repository.AnyVersion); err != nil {
+			return err
+		}
+		if err := tx.Create(ctx, &model.Task{ID: "created", Title: "Created", CreatedAt: time.Now()}); err != nil {
+			return err
+		}
+		return failed
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, failed)
+	found, err := repo.FindByID(ctx, kept.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Task", found.Title)
+	assert.Equal(t, int64(1), found.Version)
+	_, err = repo.FindByID(ctx, "created")
+	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+}
@@ -1,26 +1,38 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
 	"todo/internal/domain/model"
 	"todo/internal/domain/repository"
 )
 
 type TaskPgRepository struct {
-	db *sql.DB
+	db dbtx
 }
 
+const (
+	pgTaskExistsQuery        = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NULL)`
+	pgDeletedTaskExistsQuery = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL)`
+)
+
 func NewTaskPgRepository(db *sql.DB) *TaskPgRepository {
 	return &TaskPgRepository{db: db}
 }
 
-func (r *TaskPgRepository) Create(task *model.Task) error {
+func (r *TaskPgRepository) Create(ctx context.Context, task *model.Task) error {
 	query := `
-		INSERT INTO tasks (id, title, description, deadline, status, priority, created_at, updated_at, is_completed)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO tasks (id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, parent_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, 1, $10)
+		ON CONFLICT (id) DO NOTHING
 	`
-	_, err := r.db.Exec(
+	res, err := r.db.ExecContext(
+		ctx,
 		query,
 		task.ID,
 		task.Title,
@@ -31,17 +43,26 @@ func (r *TaskPgRepository) Create(task *model.Task) error {
 		task.CreatedAt,
 		task.UpdatedAt,
 		task.IsCompleted,
+		task.ParentID,
 	)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := insertResult(res); err != nil {
+		return err
+	}
+	task.Version = 1
+	return nil
 }
 
-func (r *TaskPgRepository) Update(task *model.Task) error {
+func (r *TaskPgRepository) Update(ctx context.Context, task *model.Task) error {
 	query := `
 		UPDATE tasks
-		SET title = $1, description = $2, deadline = $3, status = $4, priority = $5, updated_at = $6, is_completed = $7
-		WHERE id = $8
+		SET title = $1, description = $2, deadline = $3, status = $4, priority = $5, updated_at = $6, is_completed = $7, version = version + 1
+		WHERE id = $8 AND version = $9 AND deleted_at IS NULL
 	`
-	res, err := r.db.Exec(
+	res, err := r.db.ExecContext(
+		ctx,
 		query,
 		task.Title,
 		task.Description,
@@ -51,84 +72,169 @@ func (r *TaskPgRepository) Update(task *model.Task) error {
 		task.UpdatedAt,
 		task.IsCompleted,
 		task.ID,
+		task.Version,
 	)
 	if err != nil {
 		return err
 	}
-	rows, err := res.RowsAffected()
-	if err != nil {
+	if err := versionedWriteResult(ctx, r.db, res, pgTaskExistsQuery, task.ID); err != nil {
 		return err
 	}
-	if rows == 0 {
-		return repository.ErrTaskNotFound
-	}
+	task.Version++
 	return nil
 }
 
-func (r *TaskPgRepository) Delete(id string) error {
-	query := `DELETE FROM tasks WHERE id = $1`
-	res, err := r.db.Exec(query, id)
+func (r *TaskPgRepository) Delete(ctx context.Context, id string, version int64) error {
+	query := `UPDATE tasks SET deleted_at = $1, version = version + 1 WHERE id = $2 AND deleted_at IS NULL`
+	args := []interface{}{time.Now().UTC(), id}
+	if version != repository.AnyVersion {
+		query += ` AND version = $3`
+		args = append(args, version)
+	}
+	res, err := r.db.ExecContext(ctx, query, args...)
 	if err != nil {
 		return err
 	}
-	rows, err := res.RowsAffected()
+	return versionedWriteResult(ctx, r.db, res, pgTaskExistsQuery, id)
+}
+
+func (r *TaskPgRepository) CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, len(tasks)*11)
+	for _, task := range tasks {
+		args = append(args,
+			task.ID,
+			task.Title,
+			task.Description,
+			task.Deadline,
+			task.Status,
+			task.Priority,
+			task.CreatedAt,
+			task.UpdatedAt,
+			task.IsCompleted,
+			1,
+			task.ParentID,
+		)
+	}
+	inserted, err := queryIDs(ctx, r.db, buildCreateManyQuery(len(tasks), pgPlaceholder), args...)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if rows == 0 {
-		return repository.ErrTaskNotFound
+	return createManyResult(tasks, inserted), nil
+}
+
+func (r *TaskPgRepository) UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if len(tasks) == 0 {
+		return nil, nil
 	}
-	return nil
+	ids := make([]string, len(tasks))
+	args := make([]interface{}, 0, len(tasks)*9)
+	for i, task := range tasks {
+		ids[i] = task.ID
+		args = append(args,
+			task.ID,
+			task.Title,
+			task.Description,
+			task.Deadline,
+			task.Status,
+			task.Priority,
+			task.UpdatedAt,
+			task.IsCompleted,
+			task.Version,
+		)
+	}
+	updated, err := queryIDs(ctx, r.db, buildUpdateManyQuery(len(tasks), pgTypedPlaceholder(pgTaskUpdateTypes)), args...)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := versionedWriteManyResult(ctx, r.db, ids, updated, pgPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	for i, task := range tasks {
+		if errs[i] == nil {
+			task.Version++
+		}
+	}
+	return errs, nil
+}
+
+func (r *TaskPgRepository) DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(refs))
+	args := make([]interface{}, 0, len(refs)*2+1)
+	for i, ref := range refs {
+		ids[i] = ref.ID
+		args = append(args, ref.ID, ref.Version)
+	}
+	args = append(args, time.Now().UTC())
+	query := buildDeleteManyQuery(len(refs), pgTypedPlaceholder(pgTaskRefTypes), pgPlaceholder(len(args)))
+	deleted, err := queryIDs(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return versionedWriteManyResult(ctx, r.db, ids, deleted, pgPlaceholder)
 }
 
-func (r *TaskPgRepository) FindByID(id string) (*model.Task, error) {
+func (r *TaskPgRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
 	query := `
-		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed
-		FROM tasks WHERE id = $1
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE id = $1 AND deleted_at IS NULL
 	`
-	row := r.db.QueryRow(query, id)
-	var task model.Task
-	var description sql.NullString
-	var deadline sql.NullTime
-	var updatedAt sql.NullTime
-
-	err := row.Scan(
-		&task.ID,
-		&task.Title,
-		&description,
-		&deadline,
-		&task.Status,
-		&task.Priority,
-		&task.CreatedAt,
-		&updatedAt,
-		&task.IsCompleted,
-	)
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, repository.ErrTaskNotFound
 	}
 	if err != nil {
 		return nil, err
 	}
-	if description.Valid {
-		task.Description = &description.String
+	return task, nil
+}
+
+func (r *TaskPgRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error) {
+	if len(ids) == 0 {
+		return []*model.Task{}, nil
 	}
-	if deadline.Valid {
-		task.Deadline = &deadline.Time
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE deleted_at IS NULL AND id IN (` + placeholderList(len(ids), pgPlaceholder) + `)
+	`
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
 	}
-	if updatedAt.Valid {
-		task.UpdatedAt = &updatedAt.Time
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return &task, nil
+	tasks := make([]*model.Task, 0, len(ids))
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
 }
 
-func (r *TaskPgRepository) FindAll() ([]*model.Task, error) {
+func (r *TaskPgRepository) FindAll(ctx context.Context) ([]*model.Task, error) {
 	query := `
-		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
 		FROM tasks
+		WHERE deleted_at IS NULL
 		ORDER BY created_at DESC
 	`
-	rows, err := r.db.Query(query)
+	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
 		return nil, err
 	}
@@ -136,36 +242,193 @@ func (r *TaskPgRepository) FindAll() ([]*model.Task, error) {
 
 	var tasks []*model.Task
 	for rows.Next() {
-		var task model.Task
-		var description sql.NullString
-		var deadline sql.NullTime
-		var updatedAt sql.NullTime
-
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&description,
-			&deadline,
-			&task.Status,
-			&task.Priority,
-			&task.CreatedAt,
-			&updatedAt,
-			&task.IsCompleted,
-		)
-
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, err
 		}
-		if description.Valid {
-			task.Description = &description.String
-		}
-		if deadline.Valid {
-			task.Deadline = &deadline.Time
+		tasks = append(tasks, task)
+	}
+	return tasks, nil
+}
+
+func (r *TaskPgRepository) FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	conds, args := buildTaskFilterConditions(filter, pgPlaceholder)
+	where := whereClause(conds)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM tasks` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks` + where + `
+		ORDER BY ` + buildTaskOrderBy(filter) + `
+		LIMIT ` + pgPlaceholder(len(args)+1) + ` OFFSET ` + pgPlaceholder(len(args)+2)
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0, filter.PageSize)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, 0, err
 		}
-		if updatedAt.Valid {
-			task.UpdatedAt = &updatedAt.Time
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *TaskPgRepository) FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error) {
+	conds, args := buildTaskFilterConditions(filter, pgPlaceholder)
+	if after != nil {
+		cond, keyArgs := buildTaskKeysetCondition(filter, after, len(args), pgPlaceholder)
+		conds = append(conds, cond)
+		args = append(args, keyArgs...)
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks` + whereClause(conds) + `
+		ORDER BY ` + buildTaskOrderBy(filter) + `
+		LIMIT ` + pgPlaceholder(len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0, limit)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
 		}
-		tasks = append(tasks, &task)
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 	return tasks, nil
 }
+
+func (r *TaskPgRepository) CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error) {
+	return queryTaskCounts(ctx, r.db)
+}
+
+func (r *TaskPgRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *TaskPgRepository) FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE deleted_at IS NOT NULL`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT $1 OFFSET $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0, limit)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *TaskPgRepository) Restore(ctx context.Context, task *model.Task) error {
+	query := `
+		UPDATE tasks
+		SET status = $1, updated_at = $2, parent_id = $3, deleted_at = NULL, version = version + 1
+		WHERE id = $4 AND version = $5 AND deleted_at IS NOT NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, task.Status, task.UpdatedAt, task.ParentID, task.ID, task.Version)
+	if err != nil {
+		return err
+	}
+	if err := versionedWriteResult(ctx, r.db, res, pgDeletedTaskExistsQuery, task.ID); err != nil {
+		return err
+	}
+	task.Version++
+	task.DeletedAt = nil
+	return nil
+}
+
+func (r *TaskPgRepository) FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	return querySubtasks(ctx, r.db, parentID, pgPlaceholder)
+}
+
+func (r *TaskPgRepository) CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error) {
+	return querySubtaskProgress(ctx, r.db, parentIDs, pgPlaceholder)
+}
+
+func (r *TaskPgRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	purged, err := queryIDs(ctx, r.db, `DELETE FROM tasks WHERE deleted_at < $1 RETURNING id`, before)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(purged)), nil
+}
+
+func (r *TaskPgRepository) MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error) {
+	return queryMarkOverdue(ctx, r.db, now, pgPlaceholder)
+}
+
+func pgPlaceholder(n int) string {
+	return fmt.Sprintf("$%d", n)
+}
+
+// pgTaskUpdateTypes and pgTaskRefTypes are the column types of the VALUES
+// lists in the bulk update and delete, which Postgres cannot infer from bare
+// parameters.
+var (
+	pgTaskUpdateTypes = []string{"varchar", "varchar", "text", "timestamp", "varchar", "varchar", "timestamp", "boolean", "bigint"}
+	pgTaskRefTypes    = []string{"varchar", "bigint"}
+)
+
+// pgTypedPlaceholder numbers placeholders like pgPlaceholder and casts each
+// to the type of its column in a VALUES row.
+func pgTypedPlaceholder(types []string) func(n int) string {
+	return func(n int) string {
+		return fmt.Sprintf("$%d::%s", n, types[(n-1)%len(types)])
+	}
+}
@@ -1,14 +1,21 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
+	"log/slog"
+	"os"
 	"testing"
 	"time"
 	"todo/internal/domain/model"
 	"todo/internal/domain/repository"
+	"todo/internal/migrate"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	_ "github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // newTestTask creates a test task with default values for testing
@@ -24,9 +31,38 @@ func newTestTask() *model.Task {
 		CreatedAt:   now,
 		UpdatedAt:   &now,
 		IsCompleted: false,
+		Version:     1,
 	}
 }
 
+// TestTaskPgRepository_Conformance runs the shared repository behaviour suite against a real
+// PostgreSQL database. It needs an empty scratch database in TODO_TEST_POSTGRES_DSN.
+func TestTaskPgRepository_Conformance(t *testing.T) {
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TODO_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DROP TABLE IF EXISTS tasks, task_events, idempotency_keys, goose_db_version")
+	require.NoError(t, err)
+	applyMigrations(t, db, "postgres")
+
+	runTaskRepositoryConformance(t, func(t *testing.T) repository.TaskRepository {
+		_, err := db.Exec("TRUNCATE tasks")
+		require.NoError(t, err)
+		return NewTaskPgRepository(db)
+	})
+}
+
+// applyMigrations brings db to the latest schema with the migrations embedded in the binary
+func applyMigrations(t *testing.T, db *sql.DB, driver string) {
+	migrator, err := migrate.New(db, driver, slog.New(slog.DiscardHandler))
+	require.NoError(t, err)
+	require.NoError(t, migrator.Up(context.Background()))
+}
+
 // TestTaskPgRepository_Create checks that a task is successfully created in the database
 func TestTaskPgRepository_Create(t *testing.T) {
 	// Arrange
@@ -38,18 +74,36 @@ func TestTaskPgRepository_Create(t *testing.T) {
 	mock.ExpectExec("INSERT INTO tasks").
 		WithArgs(
 			task.ID, task.Title, task.Description, task.Deadline, task.Status,
-			task.Priority, task.CreatedAt, task.UpdatedAt, task.IsCompleted,
+			task.Priority, task.CreatedAt, task.UpdatedAt, task.IsCompleted, task.ParentID,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Act
-	err := repo.Create(task)
+	err := repo.Create(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// TestTaskPgRepository_Create_Exists checks that an id conflict is reported as ErrTaskExists
+func TestTaskPgRepository_Create_Exists(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	task := newTestTask()
+
+	mock.ExpectExec("INSERT INTO tasks").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// Act
+	err := repo.Create(context.Background(), task)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrTaskExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // TestTaskPgRepository_Update checks that a task is successfully updated in the database
 func TestTaskPgRepository_Update(t *testing.T) {
 	// Arrange
@@ -61,15 +115,16 @@ func TestTaskPgRepository_Update(t *testing.T) {
 	mock.ExpectExec("UPDATE tasks").
 		WithArgs(
 			task.Title, task.Description, task.Deadline, task.Status,
-			task.Priority, task.UpdatedAt, task.IsCompleted, task.ID,
+			task.Priority, task.UpdatedAt, task.IsCompleted, task.ID, task.Version,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Act
-	err := repo.Update(task)
+	err := repo.Update(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
+	assert.Equal(t, int64(2), task.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -84,31 +139,57 @@ func TestTaskPgRepository_Update_NotFound(t *testing.T) {
 	mock.ExpectExec("UPDATE tasks").
 		WithArgs(
 			task.Title, task.Description, task.Deadline, task.Status,
-			task.Priority, task.UpdatedAt, task.IsCompleted, task.ID,
+			task.Priority, task.UpdatedAt, task.IsCompleted, task.ID, task.Version,
 		).
 		WillReturnResult(sqlmock.NewResult(1, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(task.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	// Act
-	err := repo.Update(task)
+	err := repo.Update(context.Background(), task)
 
 	// Assert
 	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-// TestTaskPgRepository_Delete checks that a task is successfully deleted from the database
+// TestTaskPgRepository_Update_VersionConflict checks that a stale version on an existing task returns ErrVersionConflict
+func TestTaskPgRepository_Update_VersionConflict(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	task := newTestTask()
+
+	mock.ExpectExec("UPDATE tasks .* WHERE id = \\$8 AND version = \\$9").
+		WillReturnResult(sqlmock.NewResult(1, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(task.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Act
+	err := repo.Update(context.Background(), task)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, int64(1), task.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_Delete checks that a task is moved to the trash rather than removed
 func TestTaskPgRepository_Delete(t *testing.T) {
 	// Arrange
 	db, mock, _ := sqlmock.New()
 	defer db.Close()
 	repo := NewTaskPgRepository(db)
 
-	mock.ExpectExec("DELETE FROM tasks WHERE id = \\$1").
-		WithArgs("test-id").
+	mock.ExpectExec("UPDATE tasks SET deleted_at = \\$1, version = version \\+ 1 WHERE id = \\$2 AND deleted_at IS NULL AND version = \\$3").
+		WithArgs(sqlmock.AnyArg(), "test-id", int64(2)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	// Act
-	err := repo.Delete("test-id")
+	err := repo.Delete(context.Background(), "test-id", 2)
 
 	// Assert
 	assert.NoError(t, err)
@@ -122,18 +203,124 @@ func TestTaskPgRepository_Delete_NotFound(t *testing.T) {
 	defer db.Close()
 	repo := NewTaskPgRepository(db)
 
-	mock.ExpectExec("DELETE FROM tasks WHERE id = \\$1").
-		WithArgs("not-exist").
+	mock.ExpectExec("UPDATE tasks SET deleted_at").
+		WithArgs(sqlmock.AnyArg(), "not-exist").
 		WillReturnResult(sqlmock.NewResult(1, 0))
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs("not-exist").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	// Act
-	err := repo.Delete("not-exist")
+	err := repo.Delete(context.Background(), "not-exist", repository.AnyVersion)
 
 	// Assert
 	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+// TestTaskPgRepository_Restore_VersionConflict checks that a stale version of a trashed task returns ErrVersionConflict
+func TestTaskPgRepository_Restore_VersionConflict(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	task := newTestTask()
+
+	mock.ExpectExec("UPDATE tasks .* parent_id = \\$3, deleted_at = NULL, .* WHERE id = \\$4 AND version = \\$5 AND deleted_at IS NOT NULL").
+		WithArgs(task.Status, task.UpdatedAt, task.ParentID, task.ID, task.Version).
+		WillReturnResult(sqlmock.NewResult(1, 0))
+	mock.ExpectQuery("SELECT EXISTS \\(SELECT 1 FROM tasks WHERE id = \\$1 AND deleted_at IS NOT NULL\\)").
+		WithArgs(task.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	// Act
+	err := repo.Restore(context.Background(), task)
+
+	// Assert
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+	assert.Equal(t, int64(1), task.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_PurgeDeleted checks that trashed tasks older than the cutoff are removed for good
+// and their ids come back sorted
+func TestTaskPgRepository_PurgeDeleted(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	before := time.Now().UTC()
+
+	mock.ExpectQuery("DELETE FROM tasks WHERE deleted_at < \\$1 RETURNING id").
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("b").AddRow("a"))
+
+	// Act
+	purged, err := repo.PurgeDeleted(context.Background(), before)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, purged)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_MarkOverdue checks that the overdue tasks are moved by one UPDATE and read back
+// from RETURNING
+func TestTaskPgRepository_MarkOverdue(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	now := time.Now().UTC()
+	deadline := now.Add(-time.Hour)
+
+	mock.ExpectQuery("UPDATE tasks SET status = 'OVERDUE', updated_at = \\$1, version = version \\+ 1 " +
+		"WHERE status = 'ACTIVE' AND is_completed = FALSE AND deadline < \\$1 AND deleted_at IS NULL RETURNING id, title").
+		WithArgs(now).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+		}).AddRow(
+			"test-id", "Test Task", nil, deadline, model.StatusOverdue, model.PriorityMedium, deadline, now, false, int64(2), nil, nil,
+		))
+
+	// Act
+	tasks, err := repo.MarkOverdue(context.Background(), now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, model.StatusOverdue, tasks[0].Status)
+	assert.Equal(t, int64(2), tasks[0].Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_UpdateMany checks that tasks are written by one typed multi-row UPDATE and that
+// skipped ids are told apart with one lookup
+func TestTaskPgRepository_UpdateMany(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	fresh, stale := newTestTask(), newTestTask()
+	stale.ID = "stale-id"
+
+	mock.ExpectQuery("VALUES \\(\\$1::varchar, .*\\$9::bigint\\), \\(\\$10::varchar, .*\\$18::bigint\\) \\) UPDATE tasks .* RETURNING tasks.id").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("test-id"))
+	mock.ExpectQuery("SELECT id FROM tasks WHERE deleted_at IS NULL AND id IN \\(\\$1\\)").
+		WithArgs("stale-id").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow("stale-id"))
+
+	// Act
+	errs, err := repo.UpdateMany(context.Background(), []*model.Task{fresh, stale})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, repository.ErrVersionConflict}, errs)
+	assert.Equal(t, int64(2), fresh.Version)
+	assert.Equal(t, int64(1), stale.Version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 // TestTaskPgRepository_FindByID checks that a task is successfully retrieved by ID with all fields populated correctly
 func TestTaskPgRepository_FindByID(t *testing.T) {
 	// Arrange
@@ -145,16 +332,16 @@ func TestTaskPgRepository_FindByID(t *testing.T) {
 	description := "desc"
 	deadline := now.Add(24 * time.Hour)
 
-	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed FROM tasks WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id FROM tasks WHERE id = \\$1 AND deleted_at IS NULL").
 		WithArgs("test-id").
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed",
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
 		}).AddRow(
-			"test-id", "Test Task", description, deadline, model.StatusActive, model.PriorityMedium, now, updatedAt, false,
+			"test-id", "Test Task", description, deadline, model.StatusActive, model.PriorityMedium, now, updatedAt, false, int64(3), nil, nil,
 		))
 
 	// Act
-	task, err := repo.FindByID("test-id")
+	task, err := repo.FindByID(context.Background(), "test-id")
 
 	// Assert
 	assert.NoError(t, err)
@@ -171,6 +358,7 @@ func TestTaskPgRepository_FindByID(t *testing.T) {
 	assert.NotNil(t, task.UpdatedAt)
 	assert.WithinDuration(t, updatedAt, *task.UpdatedAt, time.Second)
 	assert.False(t, task.IsCompleted)
+	assert.Equal(t, int64(3), task.Version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -181,12 +369,12 @@ func TestTaskPgRepository_FindByID_NotFound(t *testing.T) {
 	defer db.Close()
 	repo := NewTaskPgRepository(db)
 
-	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed FROM tasks WHERE id = \\$1").
+	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id FROM tasks WHERE id = \\$1 AND deleted_at IS NULL").
 		WithArgs("not-exist").
 		WillReturnError(sql.ErrNoRows)
 
 	// Act
-	task, err := repo.FindByID("not-exist")
+	task, err := repo.FindByID(context.Background(), "not-exist")
 
 	// Assert
 	assert.ErrorIs(t, err, repository.ErrTaskNotFound)
@@ -205,15 +393,15 @@ func TestTaskPgRepository_FindAll(t *testing.T) {
 	description := "desc"
 	deadline := now.Add(24 * time.Hour)
 
-	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed FROM tasks").
+	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id FROM tasks WHERE deleted_at IS NULL").
 		WillReturnRows(sqlmock.NewRows([]string{
-			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed",
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
 		}).AddRow(
-			"test-id", "Test Task", description, deadline, model.StatusActive, model.PriorityMedium, now, updatedAt, false,
+			"test-id", "Test Task", description, deadline, model.StatusActive, model.PriorityMedium, now, updatedAt, false, int64(1), nil, nil,
 		))
 
 	// Act
-	tasks, err := repo.FindAll()
+	tasks, err := repo.FindAll(context.Background())
 
 	// Assert
 	assert.NoError(t, err)
@@ -233,3 +421,264 @@ func TestTaskPgRepository_FindAll(t *testing.T) {
 	assert.False(t, task.IsCompleted)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestTaskPgRepository_CountByStatusAndPriority checks that counts are grouped in SQL
+func TestTaskPgRepository_CountByStatusAndPriority(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+
+	mock.ExpectQuery(`SELECT status, priority, COUNT\(\*\) FROM tasks WHERE deleted_at IS NULL GROUP BY status, priority`).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "priority", "count"}).
+			AddRow(model.StatusActive, model.PriorityHigh, 3).
+			AddRow(model.StatusOverdue, model.PriorityLow, 1))
+
+	// Act
+	counts, err := repo.CountByStatusAndPriority(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []model.TaskCount{
+		{Status: model.StatusActive, Priority: model.PriorityHigh, Count: 3},
+		{Status: model.StatusOverdue, Priority: model.PriorityLow, Count: 1},
+	}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindWithFilter checks that filters, sorting and pagination are pushed down into SQL
+func TestTaskPgRepository_FindWithFilter(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	now := time.Now().UTC()
+	filter := &model.TaskFilter{
+		Status:    string(model.StatusActive),
+		Priority:  string(model.PriorityHigh),
+		SortBy:    "deadline",
+		SortOrder: "desc",
+		Page:      3,
+		PageSize:  5,
+	}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tasks WHERE deleted_at IS NULL AND parent_id IS NULL AND status = \\$1 AND priority = \\$2").
+		WithArgs("ACTIVE", "HIGH").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+	mock.ExpectQuery("FROM tasks WHERE deleted_at IS NULL AND parent_id IS NULL AND status = \\$1 AND priority = \\$2 ORDER BY deadline DESC NULLS FIRST, id DESC LIMIT \\$3 OFFSET \\$4").
+		WithArgs("ACTIVE", "HIGH", 5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+		}).AddRow(
+			"test-id", "Test Task", nil, nil, model.StatusActive, model.PriorityHigh, now, nil, false, int64(1), nil, nil,
+		))
+
+	// Act
+	tasks, total, err := repo.FindWithFilter(context.Background(), filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 11, total)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "test-id", tasks[0].ID)
+	assert.Nil(t, tasks[0].Description)
+	assert.Nil(t, tasks[0].Deadline)
+	assert.Nil(t, tasks[0].UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindWithFilter_Defaults checks that an empty filter sorts by newest first without a WHERE clause
+func TestTaskPgRepository_FindWithFilter_Defaults(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	filter := &model.TaskFilter{Page: 1, PageSize: 10}
+
+	mock.ExpectQuery("SELECT COUNT\\(\\*\\) FROM tasks WHERE deleted_at IS NULL AND parent_id IS NULL$").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("FROM tasks WHERE deleted_at IS NULL AND parent_id IS NULL ORDER BY created_at DESC, id DESC LIMIT \\$1 OFFSET \\$2").
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+		}))
+
+	// Act
+	tasks, total, err := repo.FindWithFilter(context.Background(), filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, total)
+	assert.Empty(t, tasks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindWithFilter_PriorityOrder checks that priority is sorted by rank rather than alphabetically
+func TestTaskPgRepository_FindWithFilter_PriorityOrder(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	filter := &model.TaskFilter{SortBy: "priority", Page: 1, PageSize: 10}
+
+	mock.ExpectQuery("SELECT COUNT").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery("ORDER BY CASE priority WHEN 'CRITICAL' THEN 4 .* END ASC NULLS LAST, id ASC").
+		WithArgs(10, 0).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+		}))
+
+	// Act
+	_, _, err := repo.FindWithFilter(context.Background(), filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindAfter checks the keyset condition for the default newest-first ordering
+func TestTaskPgRepository_FindAfter(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	now := time.Now().UTC()
+	filter := &model.TaskFilter{Status: string(model.StatusActive), PageSize: 2}
+	after := &model.TaskCursor{CreatedAt: now, ID: "last-id"}
+
+	mock.ExpectQuery("FROM tasks WHERE deleted_at IS NULL AND parent_id IS NULL AND status = \\$1 AND \\(created_at, id\\) < \\(\\$2, \\$3\\) ORDER BY created_at DESC, id DESC LIMIT \\$4").
+		WithArgs("ACTIVE", now, "last-id", 3).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+		}).AddRow(
+			"next-id", "Test Task", nil, nil, model.StatusActive, model.PriorityMedium, now.Add(-time.Minute), nil, false, int64(1), nil, nil,
+		))
+
+	// Act
+	tasks, err := repo.FindAfter(context.Background(), filter, after, 3)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	assert.Equal(t, "next-id", tasks[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindAfter_DeadlineNulls checks that the keyset condition keeps tasks without a deadline reachable
+func TestTaskPgRepository_FindAfter_DeadlineNulls(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	deadline := time.Now().UTC().Add(time.Hour)
+	columns := []string{
+		"id", "title", "description", "deadline", "status", "priority", "created_at", "updated_at", "is_completed", "version", "deleted_at", "parent_id",
+	}
+
+	tests := []struct {
+		name    string
+		filter  *model.TaskFilter
+		after   *model.TaskCursor
+		pattern string
+		args    []driver.Value
+	}{
+		{
+			name:    "ascending from a dated task",
+			filter:  &model.TaskFilter{SortBy: "deadline", SortOrder: "asc"},
+			after:   &model.TaskCursor{Deadline: &deadline, ID: "a"},
+			pattern: "WHERE deleted_at IS NULL AND parent_id IS NULL AND \\(\\(deadline, id\\) > \\(\\$1, \\$2\\) OR deadline IS NULL\\) ORDER BY deadline ASC NULLS LAST, id ASC LIMIT \\$3",
+			args:    []driver.Value{deadline, "a", 10},
+		},
+		{
+			name:    "ascending within undated tasks",
+			filter:  &model.TaskFilter{SortBy: "deadline", SortOrder: "asc"},
+			after:   &model.TaskCursor{ID: "b"},
+			pattern: "WHERE deleted_at IS NULL AND parent_id IS NULL AND \\(deadline IS NULL AND id > \\$1\\) ORDER BY deadline ASC NULLS LAST",
+			args:    []driver.Value{"b", 10},
+		},
+		{
+			name:    "descending past undated tasks",
+			filter:  &model.TaskFilter{SortBy: "deadline", SortOrder: "desc"},
+			after:   &model.TaskCursor{ID: "c"},
+			pattern: "WHERE deleted_at IS NULL AND parent_id IS NULL AND \\(\\(deadline IS NULL AND id < \\$1\\) OR deadline IS NOT NULL\\) ORDER BY deadline DESC NULLS FIRST",
+			args:    []driver.Value{"c", 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectQuery(tt.pattern).
+				WithArgs(tt.args...).
+				WillReturnRows(sqlmock.NewRows(columns))
+
+			_, err := repo.FindAfter(context.Background(), tt.filter, tt.after, 10)
+
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// TestTaskPgRepository_FindByID_ContextCanceled checks that a cancelled context aborts the query before it reaches the database
+func TestTaskPgRepository_FindByID_ContextCanceled(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	task, err := repo.FindByID(ctx, "test-id")
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, task)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestTaskPgRepository_FindAll_DeadlineExceeded checks that a slow query is interrupted when the context deadline passes
+func TestTaskPgRepository_FindAll_DeadlineExceeded(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	mock.ExpectQuery("SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id FROM tasks WHERE deleted_at IS NULL").
+		WillDelayFor(time.Second).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Act
+	start := time.Now()
+	tasks, err := repo.FindAll(ctx)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, tasks)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+// TestTaskPgRepository_Update_ContextCanceled checks that a cancelled context aborts an update
+func TestTaskPgRepository_Update_ContextCanceled(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	repo := NewTaskPgRepository(db)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mock.ExpectExec("UPDATE tasks").
+		WillDelayFor(time.Second).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	// Act
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	err := repo.Update(ctx, newTestTask())
+
+	// Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// The helpers below build the task queries shared by the SQL repositories.
+// Each repository passes its own placeholder style. Tasks in the trash have
+// deleted_at set; every query except the trash ones leaves them out.
+
+// priorityRankExpr maps priorities to their rank so that sorting by priority
+// follows LOW < MEDIUM < HIGH < CRITICAL instead of alphabetical order.
+const priorityRankExpr = `CASE priority WHEN 'CRITICAL' THEN 4 WHEN 'HIGH' THEN 3 WHEN 'MEDIUM' THEN 2 WHEN 'LOW' THEN 1 ELSE 0 END`
+
+var priorityRanks = map[model.TaskPriority]int{
+	model.PriorityCritical: 4,
+	model.PriorityHigh:     3,
+	model.PriorityMedium:   2,
+	model.PriorityLow:      1,
+}
+
+var taskSortColumns = map[string]string{
+	"deadline":   "deadline",
+	"created_at": "created_at",
+	"priority":   priorityRankExpr,
+}
+
+func buildTaskFilterConditions(filter *model.TaskFilter, placeholder func(n int) string) ([]string, []interface{}) {
+	conds := []string{"deleted_at IS NULL", "parent_id IS NULL"}
+	var args []interface{}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conds = append(conds, "status = "+placeholder(len(args)))
+	}
+	if filter.Priority != "" {
+		args = append(args, filter.Priority)
+		conds = append(conds, "priority = "+placeholder(len(args)))
+	}
+	return conds, args
+}
+
+func whereClause(conds []string) string {
+	if len(conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conds, " AND ")
+}
+
+// buildTaskOrderBy only ever emits column expressions from taskSortColumns, so
+// user input never reaches the query text. Tasks without a deadline go last in
+// ascending order and first in descending order; id breaks ties for stable pages.
+func buildTaskOrderBy(filter *model.TaskFilter) string {
+	column, ok := taskSortColumns[filter.SortBy]
+	if !ok {
+		return "created_at DESC, id DESC"
+	}
+	direction := "ASC"
+	nulls := "NULLS LAST"
+	if filter.IsDescending() {
+		direction = "DESC"
+		nulls = "NULLS FIRST"
+	}
+	return fmt.Sprintf("%s %s %s, id %s", column, direction, nulls, direction)
+}
+
+// buildTaskKeysetCondition selects the rows that follow the cursor in the order
+// produced by buildTaskOrderBy. Placeholders are numbered after the offset.
+func buildTaskKeysetCondition(filter *model.TaskFilter, after *model.TaskCursor, offset int, placeholder func(n int) string) (string, []interface{}) {
+	desc := filter.IsDescending()
+	cmp := ">"
+	if desc {
+		cmp = "<"
+	}
+	first := placeholder(offset + 1)
+	second := placeholder(offset + 2)
+
+	switch filter.SortBy {
+	case "deadline":
+		if after.Deadline == nil {
+			if desc {
+				return fmt.Sprintf("((deadline IS NULL AND id < %s) OR deadline IS NOT NULL)", first), []interface{}{after.ID}
+			}
+			return fmt.Sprintf("(deadline IS NULL AND id > %s)", first), []interface{}{after.ID}
+		}
+		cond := fmt.Sprintf("(deadline, id) %s (%s, %s)", cmp, first, second)
+		if !desc {
+			cond += " OR deadline IS NULL"
+		}
+		return "(" + cond + ")", []interface{}{*after.Deadline, after.ID}
+	case "priority":
+		return fmt.Sprintf("((%s), id) %s (%s, %s)", priorityRankExpr, cmp, first, second),
+			[]interface{}{priorityRanks[after.Priority], after.ID}
+	default:
+		return fmt.Sprintf("(created_at, id) %s (%s, %s)", cmp, first, second), []interface{}{after.CreatedAt, after.ID}
+	}
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanTask(row rowScanner) (*model.Task, error) {
+	var task model.Task
+	var description sql.NullString
+	var deadline sql.NullTime
+	var updatedAt sql.NullTime
+	var deletedAt sql.NullTime
+	var parentID sql.NullString
+
+	err := row.Scan(
+		&task.ID,
+		&task.Title,
+		&description,
+		&deadline,
+		&task.Status,
+		&task.Priority,
+		&task.CreatedAt,
+		&updatedAt,
+		&task.IsCompleted,
+		&task.Version,
+		&deletedAt,
+		&parentID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if description.Valid {
+		task.Description = &description.String
+	}
+	if deadline.Valid {
+		task.Deadline = &deadline.Time
+	}
+	if updatedAt.Valid {
+		task.UpdatedAt = &updatedAt.Time
+	}
+	if deletedAt.Valid {
+		task.DeletedAt = &deletedAt.Time
+	}
+	if parentID.Valid {
+		task.ParentID = &parentID.String
+	}
+	return &task, nil
+}
+
+// versionedWriteResult turns the outcome of an UPDATE or DELETE guarded by
+// "id = ? AND version = ?" into an error. When no row matched it asks the
+// database whether the task still exists to tell a missing task from a stale
+// version.
+func versionedWriteResult(ctx context.Context, db dbtx, res sql.Result, existsQuery, id string) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows > 0 {
+		return nil
+	}
+	var exists bool
+	if err := db.QueryRowContext(ctx, existsQuery, id).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return repository.ErrTaskNotFound
+	}
+	return repository.ErrVersionConflict
+}
+
+// insertResult interprets an INSERT ... ON CONFLICT (id) DO NOTHING: no
+// affected row means the ID was already taken.
+func insertResult(res sql.Result) error {
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrTaskExists
+	}
+	return nil
+}
+
+// dbtx is what the repositories need from a connection pool or a
+// transaction, so a unit of work can hand them either.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// valuesRows renders rows tuples of columns placeholders each, numbered from 1,
+// for a multi-row VALUES list.
+func valuesRows(rows, columns int, placeholder func(n int) string) string {
+	var b strings.Builder
+	for i := 0; i < rows; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString("(")
+		for j := 1; j <= columns; j++ {
+			if j > 1 {
+				b.WriteString(", ")
+			}
+			b.WriteString(placeholder(i*columns + j))
+		}
+		b.WriteString(")")
+	}
+	return b.String()
+}
+
+// placeholderList renders n placeholders numbered from 1 for an IN list.
+func placeholderList(n int, placeholder func(n int) string) string {
+	list := make([]string, n)
+	for i := range list {
+		list[i] = placeholder(i + 1)
+	}
+	return strings.Join(list, ", ")
+}
+
+// The bulk statements below report the rows they touched with RETURNING, so
+// per-task outcomes need no extra query when everything succeeds.
+
+func buildCreateManyQuery(n int, placeholder func(n int) string) string {
+	return `
+		INSERT INTO tasks (id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, parent_id)
+		VALUES ` + valuesRows(n, 11, placeholder) + `
+		ON CONFLICT (id) DO NOTHING
+		RETURNING id
+	`
+}
+
+// buildUpdateManyQuery joins the new values as a VALUES list. The placeholder
+// may add casts, which Postgres needs to type the columns of the list.
+func buildUpdateManyQuery(n int, placeholder func(n int) string) string {
+	return `
+		WITH v (id, title, description, deadline, status, priority, updated_at, is_completed, version) AS (
+			VALUES ` + valuesRows(n, 9, placeholder) + `
+		)
+		UPDATE tasks
+		SET title = v.title, description = v.description, deadline = v.deadline, status = v.status, priority = v.priority,
+			updated_at = v.updated_at, is_completed = v.is_completed, version = tasks.version + 1
+		FROM v
+		WHERE tasks.id = v.id AND tasks.version = v.version AND tasks.deleted_at IS NULL
+		RETURNING tasks.id
+	`
+}
+
+// buildDeleteManyQuery moves the listed tasks to the trash. deletedAt is the
+// placeholder of the deletion time, numbered after the VALUES list.
+func buildDeleteManyQuery(n int, placeholder func(n int) string, deletedAt string) string {
+	return fmt.Sprintf(`
+		WITH v (id, version) AS (
+			VALUES %s
+		)
+		UPDATE tasks
+		SET deleted_at = %s, version = tasks.version + 1
+		FROM v
+		WHERE tasks.id = v.id AND tasks.deleted_at IS NULL AND (v.version = %d OR v.version = tasks.version)
+		RETURNING tasks.id
+	`, valuesRows(n, 2, placeholder), deletedAt, repository.AnyVersion)
+}
+
+// queryIDs runs a statement that returns task ids and collects them.
+func queryIDs(ctx context.Context, db dbtx, query string, args ...interface{}) (map[string]bool, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// createManyResult reports ErrTaskExists for every task the insert skipped.
+func createManyResult(tasks []*model.Task, inserted map[string]bool) []error {
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		if !inserted[task.ID] {
+			errs[i] = repository.ErrTaskExists
+			continue
+		}
+		task.Version = 1
+	}
+	return errs
+}
+
+// versionedWriteManyResult is versionedWriteResult for bulk statements: ids
+// the statement did not touch are looked up with one query to tell missing
+// tasks from stale versions.
+func versionedWriteManyResult(ctx context.Context, db dbtx, ids []string, written map[string]bool, placeholder func(n int) string) ([]error, error) {
+	var missed []interface{}
+	for _, id := range ids {
+		if !written[id] {
+			missed = append(missed, id)
+		}
+	}
+	errs := make([]error, len(ids))
+	if len(missed) == 0 {
+		return errs, nil
+	}
+	existing, err := queryIDs(ctx, db, `SELECT id FROM tasks WHERE deleted_at IS NULL AND id IN (`+placeholderList(len(missed), placeholder)+`)`, missed...)
+	if err != nil {
+		return nil, err
+	}
+	for i, id := range ids {
+		switch {
+		case written[id]:
+		case existing[id]:
+			errs[i] = repository.ErrVersionConflict
+		default:
+			errs[i] = repository.ErrTaskNotFound
+		}
+	}
+	return errs, nil
+}
+
+const countByStatusAndPriorityQuery = `
+	SELECT status, priority, COUNT(*)
+	FROM tasks
+	WHERE deleted_at IS NULL
+	GROUP BY status, priority
+`
+
+func queryTaskCounts(ctx context.Context, db dbtx) ([]model.TaskCount, error) {
+	rows, err := db.QueryContext(ctx, countByStatusAndPriorityQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]model.TaskCount, 0)
+	for rows.Next() {
+		var count model.TaskCount
+		if err := rows.Scan(&count.Status, &count.Priority, &count.Count); err != nil {
+			return nil, err
+		}
+		counts = append(counts, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// querySubtasks lists the live subtasks of a task, oldest first.
+// queryMarkOverdue moves the overdue tasks to OVERDUE and reads them back
+// from RETURNING. now is bound once, as the first parameter.
+func queryMarkOverdue(ctx context.Context, db dbtx, now interface{}, placeholder func(n int) string) ([]*model.Task, error) {
+	query := `
+		UPDATE tasks
+		SET status = 'OVERDUE', updated_at = ` + placeholder(1) + `, version = version + 1
+		WHERE status = 'ACTIVE' AND is_completed = FALSE AND deadline < ` + placeholder(1) + ` AND deleted_at IS NULL
+		RETURNING id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+	`
+	rows, err := db.QueryContext(ctx, query, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func querySubtasks(ctx context.Context, db dbtx, parentID string, placeholder func(n int) string) ([]*model.Task, error) {
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks
+		WHERE parent_id = ` + placeholder(1) + ` AND deleted_at IS NULL
+		ORDER BY created_at, id
+	`
+	rows, err := db.QueryContext(ctx, query, parentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// querySubtaskProgress counts the live subtasks of the listed tasks with one
+// grouped query. Tasks without subtasks are left out.
+func querySubtaskProgress(ctx context.Context, db dbtx, parentIDs []string, placeholder func(n int) string) (map[string]model.TaskProgress, error) {
+	progress := make(map[string]model.TaskProgress)
+	if len(parentIDs) == 0 {
+		return progress, nil
+	}
+	query := `
+		SELECT parent_id, COUNT(*), SUM(CASE WHEN is_completed THEN 1 ELSE 0 END)
+		FROM tasks
+		WHERE deleted_at IS NULL AND parent_id IN (` + placeholderList(len(parentIDs), placeholder) + `)
+		GROUP BY parent_id
+	`
+	args := make([]interface{}, len(parentIDs))
+	for i, id := range parentIDs {
+		args[i] = id
+	}
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parentID string
+			p        model.TaskProgress
+		)
+		if err := rows.Scan(&parentID, &p.Total, &p.Done); err != nil {
+			return nil, err
+		}
+		progress[parentID] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
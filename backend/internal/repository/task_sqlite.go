@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+)
+
+// sqliteTimeLayout is fixed-width and always UTC, so timestamps stored as text
+// compare in the same order as the instants they represent.
+const sqliteTimeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type TaskSQLiteRepository struct {
+	db dbtx
+}
+
+const (
+	sqliteTaskExistsQuery        = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?1 AND deleted_at IS NULL)`
+	sqliteDeletedTaskExistsQuery = `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = ?1 AND deleted_at IS NOT NULL)`
+)
+
+func NewTaskSQLiteRepository(db *sql.DB) *TaskSQLiteRepository {
+	return &TaskSQLiteRepository{db: db}
+}
+
+func (r *TaskSQLiteRepository) Create(ctx context.Context, task *model.Task) error {
+	query := `
+		INSERT INTO tasks (id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, parent_id)
+		VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, 1, ?10)
+		ON CONFLICT (id) DO NOTHING
+	`
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		task.ID,
+		task.Title,
+		task.Description,
+		sqliteNullableTime(task.Deadline),
+		task.Status,
+		task.Priority,
+		sqliteTime(task.CreatedAt),
+		sqliteNullableTime(task.UpdatedAt),
+		task.IsCompleted,
+		task.ParentID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := insertResult(res); err != nil {
+		return err
+	}
+	task.Version = 1
+	return nil
+}
+
+func (r *TaskSQLiteRepository) Update(ctx context.Context, task *model.Task) error {
+	query := `
+		UPDATE tasks
+		SET title = ?1, description = ?2, deadline = ?3, status = ?4, priority = ?5, updated_at = ?6, is_completed = ?7, version = version + 1
+		WHERE id = ?8 AND version = ?9 AND deleted_at IS NULL
+	`
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		task.Title,
+		task.Description,
+		sqliteNullableTime(task.Deadline),
+		task.Status,
+		task.Priority,
+		sqliteNullableTime(task.UpdatedAt),
+		task.IsCompleted,
+		task.ID,
+		task.Version,
+	)
+	if err != nil {
+		return err
+	}
+	if err := versionedWriteResult(ctx, r.db, res, sqliteTaskExistsQuery, task.ID); err != nil {
+		return err
+	}
+	task.Version++
+	return nil
+}
+
+func (r *TaskSQLiteRepository) Delete(ctx context.Context, id string, version int64) error {
+	query := `UPDATE tasks SET deleted_at = ?1, version = version + 1 WHERE id = ?2 AND deleted_at IS NULL`
+	args := []interface{}{sqliteTime(time.Now()), id}
+	if version != repository.AnyVersion {
+		query += ` AND version = ?3`
+		args = append(args, version)
+	}
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	return versionedWriteResult(ctx, r.db, res, sqliteTaskExistsQuery, id)
+}
+
+func (r *TaskSQLiteRepository) CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	args := make([]interface{}, 0, len(tasks)*11)
+	for _, task := range tasks {
+		args = append(args,
+			task.ID,
+			task.Title,
+			task.Description,
+			sqliteNullableTime(task.Deadline),
+			task.Status,
+			task.Priority,
+			sqliteTime(task.CreatedAt),
+			sqliteNullableTime(task.UpdatedAt),
+			task.IsCompleted,
+			1,
+			task.ParentID,
+		)
+	}
+	inserted, err := queryIDs(ctx, r.db, buildCreateManyQuery(len(tasks), sqlitePlaceholder), args...)
+	if err != nil {
+		return nil, err
+	}
+	return createManyResult(tasks, inserted), nil
+}
+
+func (r *TaskSQLiteRepository) UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	if len(tasks) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(tasks))
+	args := make([]interface{}, 0, len(tasks)*9)
+	for i, task := range tasks {
+		ids[i] = task.ID
+		args = append(args,
+			task.ID,
+			task.Title,
+			task.Description,
+			sqliteNullableTime(task.Deadline),
+			task.Status,
+			task.Priority,
+			sqliteNullableTime(task.UpdatedAt),
+			task.IsCompleted,
+			task.Version,
+		)
+	}
+	updated, err := queryIDs(ctx, r.db, buildUpdateManyQuery(len(tasks), sqlitePlaceholder), args...)
+	if err != nil {
+		return nil, err
+	}
+	errs, err := versionedWriteManyResult(ctx, r.db, ids, updated, sqlitePlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	for i, task := range tasks {
+		if errs[i] == nil {
+			task.Version++
+		}
+	}
+	return errs, nil
+}
+
+func (r *TaskSQLiteRepository) DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	ids := make([]string, len(refs))
+	args := make([]interface{}, 0, len(refs)*2+1)
+	for i, ref := range refs {
+		ids[i] = ref.ID
+		args = append(args, ref.ID, ref.Version)
+	}
+	args = append(args, sqliteTime(time.Now()))
+	query := buildDeleteManyQuery(len(refs), sqlitePlaceholder, sqlitePlaceholder(len(args)))
+	deleted, err := queryIDs(ctx, r.db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return versionedWriteManyResult(ctx, r.db, ids, deleted, sqlitePlaceholder)
+}
+
+func (r *TaskSQLiteRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE id = ?1 AND deleted_at IS NULL
+	`
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *TaskSQLiteRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error) {
+	if len(ids) == 0 {
+		return []*model.Task{}, nil
+	}
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE deleted_at IS NULL AND id IN (` + placeholderList(len(ids), sqlitePlaceholder) + `)
+	`
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return r.queryTasks(ctx, query, args...)
+}
+
+func (r *TaskSQLiteRepository) FindAll(ctx context.Context) ([]*model.Task, error) {
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks
+		WHERE deleted_at IS NULL
+		ORDER BY created_at DESC
+	`
+	return r.queryTasks(ctx, query)
+}
+
+func (r *TaskSQLiteRepository) FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	conds, args := buildTaskFilterConditions(filter, sqlitePlaceholder)
+	where := whereClause(conds)
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM tasks` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks` + where + `
+		ORDER BY ` + buildTaskOrderBy(filter) + `
+		LIMIT ` + sqlitePlaceholder(len(args)+1) + ` OFFSET ` + sqlitePlaceholder(len(args)+2)
+	args = append(args, filter.PageSize, (filter.Page-1)*filter.PageSize)
+
+	tasks, err := r.queryTasks(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *TaskSQLiteRepository) FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error) {
+	conds, args := buildTaskFilterConditions(filter, sqlitePlaceholder)
+	if after != nil {
+		cond, keyArgs := buildTaskKeysetCondition(filter, after, len(args), sqlitePlaceholder)
+		conds = append(conds, cond)
+		args = append(args, keyArgs...)
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks` + whereClause(conds) + `
+		ORDER BY ` + buildTaskOrderBy(filter) + `
+		LIMIT ` + sqlitePlaceholder(len(args))
+
+	return r.queryTasks(ctx, query, args...)
+}
+
+func (r *TaskSQLiteRepository) CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error) {
+	return queryTaskCounts(ctx, r.db)
+}
+
+func (r *TaskSQLiteRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks WHERE id = ?1 AND deleted_at IS NOT NULL
+	`
+	task, err := scanTask(r.db.QueryRowContext(ctx, query, id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, repository.ErrTaskNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func (r *TaskSQLiteRepository) FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM tasks WHERE deleted_at IS NOT NULL`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := `
+		SELECT id, title, description, deadline, status, priority, created_at, updated_at, is_completed, version, deleted_at, parent_id
+		FROM tasks
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id DESC
+		LIMIT ?1 OFFSET ?2
+	`
+	tasks, err := r.queryTasks(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *TaskSQLiteRepository) Restore(ctx context.Context, task *model.Task) error {
+	query := `
+		UPDATE tasks
+		SET status = ?1, updated_at = ?2, parent_id = ?3, deleted_at = NULL, version = version + 1
+		WHERE id = ?4 AND version = ?5 AND deleted_at IS NOT NULL
+	`
+	res, err := r.db.ExecContext(ctx, query, task.Status, sqliteNullableTime(task.UpdatedAt), task.ParentID, task.ID, task.Version)
+	if err != nil {
+		return err
+	}
+	if err := versionedWriteResult(ctx, r.db, res, sqliteDeletedTaskExistsQuery, task.ID); err != nil {
+		return err
+	}
+	task.Version++
+	task.DeletedAt = nil
+	return nil
+}
+
+func (r *TaskSQLiteRepository) FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	return querySubtasks(ctx, r.db, parentID, sqlitePlaceholder)
+}
+
+func (r *TaskSQLiteRepository) CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error) {
+	return querySubtaskProgress(ctx, r.db, parentIDs, sqlitePlaceholder)
+}
+
+func (r *TaskSQLiteRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	purged, err := queryIDs(ctx, r.db, `DELETE FROM tasks WHERE deleted_at < ?1 RETURNING id`, sqliteTime(before))
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(purged)), nil
+}
+
+func (r *TaskSQLiteRepository) MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error) {
+	return queryMarkOverdue(ctx, r.db, sqliteTime(now), sqlitePlaceholder)
+}
+
+func (r *TaskSQLiteRepository) queryTasks(ctx context.Context, query string, args ...interface{}) ([]*model.Task, error) {
+	for i, arg := range args {
+		if t, ok := arg.(time.Time); ok {
+			args[i] = sqliteTime(t)
+		}
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := make([]*model.Task, 0)
+	for rows.Next() {
+		task, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func sqlitePlaceholder(n int) string {
+	return fmt.Sprintf("?%d", n)
+}
+
+func sqliteTime(t time.Time) string {
+	return t.UTC().Format(sqliteTimeLayout)
+}
+
+func sqliteNullableTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return sqliteTime(*t)
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+// newTestSQLiteDB opens a private in-memory database with the SQLite migrations applied
+func newTestSQLiteDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", "file::memory:?_pragma=foreign_keys(1)")
+	require.NoError(t, err)
+	// Every connection to :memory: is a separate database.
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	applyMigrations(t, db, "sqlite")
+	return db
+}
+
+// TestTaskSQLiteRepository_Conformance runs the shared repository behaviour suite
+func TestTaskSQLiteRepository_Conformance(t *testing.T) {
+	runTaskRepositoryConformance(t, func(t *testing.T) repository.TaskRepository {
+		return NewTaskSQLiteRepository(newTestSQLiteDB(t))
+	})
+}
+
+// TestTaskSQLiteRepository_TimeZones checks that timestamps in different zones are stored and ordered as instants
+func TestTaskSQLiteRepository_TimeZones(t *testing.T) {
+	// Arrange
+	repo := NewTaskSQLiteRepository(newTestSQLiteDB(t))
+	ctx := context.Background()
+	moscow := time.FixedZone("MSK", 3*60*60)
+	base := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	early := newTestTask()
+	early.ID = "early"
+	early.CreatedAt = base.In(moscow)
+	late := newTestTask()
+	late.ID = "late"
+	late.CreatedAt = base.Add(time.Hour)
+	require.NoError(t, repo.Create(ctx, early))
+	require.NoError(t, repo.Create(ctx, late))
+
+	// Act
+	tasks, err := repo.FindAll(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"late", "early"}, taskIDs(tasks))
+	assert.True(t, base.Equal(tasks[1].CreatedAt))
+}
+
+// TestTaskSQLiteRepository_MarkOverdue_ManyTasks checks that the overdue job's writes hold more tasks
+// than SQLite accepts parameters in one statement
+func TestTaskSQLiteRepository_MarkOverdue_ManyTasks(t *testing.T) {
+	// Arrange
+	db := newTestSQLiteDB(t)
+	ctx := context.Background()
+	now := time.Now().UTC()
+	past := now.Add(-time.Hour)
+	const count = 4000
+	uow := NewSQLiteUnitOfWork(db)
+	require.NoError(t, uow.WithTx(ctx, func(repos repository.Repositories) error {
+		for i := 0; i < count; i++ {
+			task := &model.Task{ID: fmt.Sprintf("task-%04d", i), Title: "Overdue", Deadline: &past, Status: model.StatusActive, Priority: model.PriorityMedium, CreatedAt: past}
+			if err := repos.Tasks.Create(ctx, task); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+
+	// Act
+	var (
+		marked []*model.Task
+		events []*model.TaskEvent
+	)
+	err := uow.WithTx(ctx, func(repos repository.Repositories) error {
+		var err error
+		if marked, err = repos.Tasks.MarkOverdue(ctx, now); err != nil {
+			return err
+		}
+		events = make([]*model.TaskEvent, len(marked))
+		for i, task := range marked {
+			events[i] = &model.TaskEvent{TaskID: task.ID, Type: model.TaskEventStatusChanged, Origin: model.OriginCron, Version: task.Version, CreatedAt: now}
+		}
+		return repos.Events.Append(ctx, events...)
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, marked, count)
+	last := events[len(events)-1]
+	stored, err := NewTaskEventSQLiteRepository(db).FindByTaskID(ctx, last.TaskID)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	assert.Equal(t, last.ID, stored[0].ID)
+	assert.Equal(t, int64(2), stored[0].Version)
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "todo/internal/repository"
+
+// TracedTaskRepository records a span for every call to the wrapped
+// repository, so a slow query shows up under the request that issued it.
+// ErrTaskNotFound, ErrTaskExists and ErrVersionConflict are expected answers
+// and do not mark the span as failed.
+type TracedTaskRepository struct {
+	next   repository.TaskRepository
+	tracer trace.Tracer
+	attrs  []attribute.KeyValue
+	kind   trace.SpanKind
+}
+
+// NewTracedTaskRepository wraps next. system is the database behind it, such
+// as "postgresql" or "sqlite", and is empty for in-process storage.
+func NewTracedTaskRepository(next repository.TaskRepository, tp trace.TracerProvider, system string) *TracedTaskRepository {
+	r := &TracedTaskRepository{next: next, tracer: tp.Tracer(tracerName), kind: trace.SpanKindInternal}
+	if system != "" {
+		r.attrs = append(r.attrs, semconv.DBSystemNameKey.String(system))
+		r.kind = trace.SpanKindClient
+	}
+	return r
+}
+
+func (r *TracedTaskRepository) Create(ctx context.Context, task *model.Task) error {
+	ctx, span := r.start(ctx, "Create", attribute.String("task.id", task.ID))
+	err := r.next.Create(ctx, task)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskRepository) Update(ctx context.Context, task *model.Task) error {
+	ctx, span := r.start(ctx, "Update", attribute.String("task.id", task.ID), attribute.Int64("task.version", task.Version))
+	err := r.next.Update(ctx, task)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskRepository) Delete(ctx context.Context, id string, version int64) error {
+	ctx, span := r.start(ctx, "Delete", attribute.String("task.id", id), attribute.Int64("task.version", version))
+	err := r.next.Delete(ctx, id, version)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskRepository) CreateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	ctx, span := r.start(ctx, "CreateMany", attribute.Int("task.count", len(tasks)))
+	errs, err := r.next.CreateMany(ctx, tasks)
+	endSpan(span, err)
+	return errs, err
+}
+
+func (r *TracedTaskRepository) UpdateMany(ctx context.Context, tasks []*model.Task) ([]error, error) {
+	ctx, span := r.start(ctx, "UpdateMany", attribute.Int("task.count", len(tasks)))
+	errs, err := r.next.UpdateMany(ctx, tasks)
+	endSpan(span, err)
+	return errs, err
+}
+
+func (r *TracedTaskRepository) DeleteMany(ctx context.Context, refs []model.TaskRef) ([]error, error) {
+	ctx, span := r.start(ctx, "DeleteMany", attribute.Int("task.count", len(refs)))
+	errs, err := r.next.DeleteMany(ctx, refs)
+	endSpan(span, err)
+	return errs, err
+}
+
+func (r *TracedTaskRepository) FindByID(ctx context.Context, id string) (*model.Task, error) {
+	ctx, span := r.start(ctx, "FindByID", attribute.String("task.id", id))
+	task, err := r.next.FindByID(ctx, id)
+	endSpan(span, err)
+	return task, err
+}
+
+func (r *TracedTaskRepository) FindByIDs(ctx context.Context, ids []string) ([]*model.Task, error) {
+	ctx, span := r.start(ctx, "FindByIDs", attribute.Int("task.count", len(ids)))
+	tasks, err := r.next.FindByIDs(ctx, ids)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (r *TracedTaskRepository) FindAll(ctx context.Context) ([]*model.Task, error) {
+	ctx, span := r.start(ctx, "FindAll")
+	tasks, err := r.next.FindAll(ctx)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (r *TracedTaskRepository) FindWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	ctx, span := r.start(ctx, "FindWithFilter")
+	tasks, total, err := r.next.FindWithFilter(ctx, filter)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)), attribute.Int("task.total", total))
+	endSpan(span, err)
+	return tasks, total, err
+}
+
+func (r *TracedTaskRepository) FindAfter(ctx context.Context, filter *model.TaskFilter, after *model.TaskCursor, limit int) ([]*model.Task, error) {
+	ctx, span := r.start(ctx, "FindAfter", attribute.Int("limit", limit))
+	tasks, err := r.next.FindAfter(ctx, filter, after, limit)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (r *TracedTaskRepository) CountByStatusAndPriority(ctx context.Context) ([]model.TaskCount, error) {
+	ctx, span := r.start(ctx, "CountByStatusAndPriority")
+	counts, err := r.next.CountByStatusAndPriority(ctx)
+	endSpan(span, err)
+	return counts, err
+}
+
+func (r *TracedTaskRepository) FindDeletedByID(ctx context.Context, id string) (*model.Task, error) {
+	ctx, span := r.start(ctx, "FindDeletedByID", attribute.String("task.id", id))
+	task, err := r.next.FindDeletedByID(ctx, id)
+	endSpan(span, err)
+	return task, err
+}
+
+func (r *TracedTaskRepository) FindDeleted(ctx context.Context, offset, limit int) ([]*model.Task, int, error) {
+	ctx, span := r.start(ctx, "FindDeleted", attribute.Int("offset", offset), attribute.Int("limit", limit))
+	tasks, total, err := r.next.FindDeleted(ctx, offset, limit)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)), attribute.Int("task.total", total))
+	endSpan(span, err)
+	return tasks, total, err
+}
+
+func (r *TracedTaskRepository) Restore(ctx context.Context, task *model.Task) error {
+	ctx, span := r.start(ctx, "Restore", attribute.String("task.id", task.ID), attribute.Int64("task.version", task.Version))
+	err := r.next.Restore(ctx, task)
+	endSpan(span, err)
+	return err
+}
+
+func (r *TracedTaskRepository) FindSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	ctx, span := r.start(ctx, "FindSubtasks", attribute.String("task.parent_id", parentID))
+	tasks, err := r.next.FindSubtasks(ctx, parentID)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (r *TracedTaskRepository) CountSubtasks(ctx context.Context, parentIDs []string) (map[string]model.TaskProgress, error) {
+	ctx, span := r.start(ctx, "CountSubtasks", attribute.Int("task.count", len(parentIDs)))
+	progress, err := r.next.CountSubtasks(ctx, parentIDs)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(progress)))
+	endSpan(span, err)
+	return progress, err
+}
+
+func (r *TracedTaskRepository) PurgeDeleted(ctx context.Context, before time.Time) ([]string, error) {
+	ctx, span := r.start(ctx, "PurgeDeleted")
+	purged, err := r.next.PurgeDeleted(ctx, before)
+	span.SetAttributes(attribute.Int("task.purged", len(purged)))
+	endSpan(span, err)
+	return purged, err
+}
+
+func (r *TracedTaskRepository) MarkOverdue(ctx context.Context, now time.Time) ([]*model.Task, error) {
+	ctx, span := r.start(ctx, "MarkOverdue")
+	tasks, err := r.next.MarkOverdue(ctx, now)
+	span.SetAttributes(semconv.DBResponseReturnedRows(len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (r *TracedTaskRepository) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBOperationName(method))
+	return r.tracer.Start(ctx, "TaskRepository."+method,
+		trace.WithSpanKind(r.kind),
+		trace.WithAttributes(r.attrs...),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// isExpectedAnswer reports whether err describes the stored data rather than
+// a failed call.
+func isExpectedAnswer(err error) bool {
+	return errors.Is(err, repository.ErrTaskNotFound) ||
+		errors.Is(err, repository.ErrTaskExists) ||
+		errors.Is(err, repository.ErrVersionConflict)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil && !isExpectedAnswer(err) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
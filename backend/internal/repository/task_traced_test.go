@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestTracedTaskRepository_Conformance checks that the decorator does not change behaviour
+func TestTracedTaskRepository_Conformance(t *testing.T) {
+	runTaskRepositoryConformance(t, func(t *testing.T) repository.TaskRepository {
+		tp := sdktrace.NewTracerProvider()
+		return NewTracedTaskRepository(NewTaskMemoryRepository(), tp, "")
+	})
+}
+
+// TestTracedTaskRepository_RecordsSpans checks that each call becomes a child span with its outcome
+func TestTracedTaskRepository_RecordsSpans(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := NewTracedTaskRepository(NewTaskMemoryRepository(), tp, "postgresql")
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	// Act
+	_ = repo.Create(ctx, newTestTask())
+	_, _ = repo.FindByID(ctx, "missing")
+	_, _ = repo.FindAll(canceled)
+	parent.End()
+
+	// Assert
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	for _, span := range spans[:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+		assert.Contains(t, span.Attributes(), semconv.DBSystemNamePostgreSQL)
+	}
+	assert.Equal(t, "TaskRepository.Create", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "TaskRepository.FindByID", spans[1].Name())
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, "TaskRepository.FindAll", spans[2].Name())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runUnitOfWorkConformance is the behaviour every repository.UnitOfWork
+// implementation must provide. newUoW must return a unit of work over empty
+// storage together with repositories that read the same storage outside any
+// transaction.
+func runUnitOfWorkConformance(t *testing.T, newUoW func(t *testing.T) (repository.UnitOfWork, repository.Repositories)) {
+	ctx := context.Background()
+	newTask := func(id string) *model.Task {
+		return &model.Task{
+			ID:        id,
+			Title:     "Task " + id,
+			Status:    model.StatusActive,
+			Priority:  model.PriorityMedium,
+			CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+		}
+	}
+
+	t.Run("WithTx commits when fn succeeds", func(t *testing.T) {
+		uow, outside := newUoW(t)
+		repo := outside.Tasks
+		require.NoError(t, repo.Create(ctx, newTask("kept")))
+
+		err := uow.WithTx(ctx, func(repos repository.Repositories) error {
+			if err := repos.Tasks.Create(ctx, newTask("created")); err != nil {
+				return err
+			}
+			// Writes are visible inside the transaction.
+			created, err := repos.Tasks.FindByID(ctx, "created")
+			if err != nil {
+				return err
+			}
+			created.IsCompleted = true
+			return repos.Tasks.Update(ctx, created)
+		})
+
+		require.NoError(t, err)
+		left, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"created", "kept"}, taskIDs(left))
+		created, err := repo.FindByID(ctx, "created")
+		require.NoError(t, err)
+		assert.True(t, created.IsCompleted)
+	})
+
+	t.Run("WithTx rolls back and returns the error of fn", func(t *testing.T) {
+		uow, outside := newUoW(t)
+		repo := outside.Tasks
+		require.NoError(t, repo.Create(ctx, newTask("kept")))
+		failure := errors.New("failure")
+
+		err := uow.WithTx(ctx, func(repos repository.Repositories) error {
+			if err := repos.Tasks.Create(ctx, newTask("created")); err != nil {
+				return err
+			}
+			if err := repos.Tasks.Delete(ctx, "kept", repository.AnyVersion); err != nil {
+				return err
+			}
+			return failure
+		})
+
+		assert.ErrorIs(t, err, failure)
+		left, err := repo.FindAll(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"kept"}, taskIDs(left))
+	})
+
+	t.Run("WithTx rolls back quietly on ErrRollback", func(t *testing.T) {
+		uow, outside := newUoW(t)
+		repo := outside.Tasks
+
+		err := uow.WithTx(ctx, func(repos repository.Repositories) error {
+			if err := repos.Tasks.Create(ctx, newTask("created")); err != nil {
+				return err
+			}
+			return repository.ErrRollback
+		})
+
+		assert.NoError(t, err)
+		_, err = repo.FindByID(ctx, "created")
+		assert.ErrorIs(t, err, repository.ErrTaskNotFound)
+	})
+
+	t.Run("WithTx keeps events only with their tasks", func(t *testing.T) {
+		uow, outside := newUoW(t)
+		newEvent := func(taskID string) *model.TaskEvent {
+			return &model.TaskEvent{
+				TaskID:    taskID,
+				Type:      model.TaskEventCreated,
+				Origin:    model.OriginAPI,
+				Version:   1,
+				CreatedAt: time.Now().UTC().Truncate(time.Microsecond),
+			}
+		}
+		write := func(id string, rollback bool) error {
+			return uow.WithTx(ctx, func(repos repository.Repositories) error {
+				if err := repos.Tasks.Create(ctx, newTask(id)); err != nil {
+					return err
+				}
+				if err := repos.Events.Append(ctx, newEvent(id)); err != nil {
+					return err
+				}
+				if rollback {
+					return repository.ErrRollback
+				}
+				return nil
+			})
+		}
+
+		require.NoError(t, write("kept", false))
+		require.NoError(t, write("dropped", true))
+		require.NoError(t, uow.WithTx(ctx, func(repos repository.Repositories) error {
+			if err := repos.Events.Append(ctx, newEvent("kept")); err != nil {
+				return err
+			}
+			return repository.ErrRollback
+		}))
+
+		kept, err := outside.Events.FindByTaskID(ctx, "kept")
+		require.NoError(t, err)
+		dropped, err := outside.Events.FindByTaskID(ctx, "dropped")
+		require.NoError(t, err)
+		assert.Len(t, kept, 1)
+		assert.Empty(t, dropped)
+	})
+}
+
+// TestMemoryUnitOfWork_Conformance runs the shared unit of work behaviour suite
+func TestMemoryUnitOfWork_Conformance(t *testing.T) {
+	runUnitOfWorkConformance(t, func(t *testing.T) (repository.UnitOfWork, repository.Repositories) {
+		tasks, events := NewTaskMemoryRepository(), NewTaskEventMemoryRepository()
+		return NewMemoryUnitOfWork(tasks, events), repository.Repositories{Tasks: tasks, Events: events}
+	})
+}
+
+// TestSQLiteUnitOfWork_Conformance runs the shared unit of work behaviour suite
+func TestSQLiteUnitOfWork_Conformance(t *testing.T) {
+	runUnitOfWorkConformance(t, func(t *testing.T) (repository.UnitOfWork, repository.Repositories) {
+		db := newTestSQLiteDB(t)
+		return NewSQLiteUnitOfWork(db), repository.Repositories{Tasks: NewTaskSQLiteRepository(db), Events: NewTaskEventSQLiteRepository(db)}
+	})
+}
+
+// TestInstrumentedUnitOfWork_Conformance checks that the decorator does not change behaviour
+func TestInstrumentedUnitOfWork_Conformance(t *testing.T) {
+	runUnitOfWorkConformance(t, func(t *testing.T) (repository.UnitOfWork, repository.Repositories) {
+		tasks, events := NewTaskMemoryRepository(), NewTaskEventMemoryRepository()
+		uow := NewInstrumentedUnitOfWork(NewMemoryUnitOfWork(tasks, events), &recordingQueryObserver{}, slog.New(slog.DiscardHandler))
+		return uow, repository.Repositories{Tasks: tasks, Events: events}
+	})
+}
+
+// TestPgUnitOfWork_Conformance runs the shared unit of work behaviour suite against a real
+// PostgreSQL database. It needs an empty scratch database in TODO_TEST_POSTGRES_DSN.
+func TestPgUnitOfWork_Conformance(t *testing.T) {
+	dsn := os.Getenv("TODO_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TODO_TEST_POSTGRES_DSN is not set")
+	}
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DROP TABLE IF EXISTS tasks, task_events, idempotency_keys, goose_db_version")
+	require.NoError(t, err)
+	applyMigrations(t, db, "postgres")
+
+	runUnitOfWorkConformance(t, func(t *testing.T) (repository.UnitOfWork, repository.Repositories) {
+		_, err := db.Exec("TRUNCATE tasks, task_events")
+		require.NoError(t, err)
+		uow := NewPgUnitOfWork(db, TxOptions{Isolation: sql.LevelSerializable, MaxRetries: 3})
+		return uow, repository.Repositories{Tasks: NewTaskPgRepository(db), Events: NewTaskEventPgRepository(db)}
+	})
+}
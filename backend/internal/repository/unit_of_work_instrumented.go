@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"todo/internal/domain/repository"
+)
+
+// InstrumentedUnitOfWork times every transaction of the wrapped unit of work
+// under the method name WithTx and instruments the repositories it hands out
+// like InstrumentedTaskRepository and InstrumentedTaskEventRepository.
+type InstrumentedUnitOfWork struct {
+	next     repository.UnitOfWork
+	observer QueryObserver
+	logger   *slog.Logger
+}
+
+func NewInstrumentedUnitOfWork(next repository.UnitOfWork, observer QueryObserver, logger *slog.Logger) *InstrumentedUnitOfWork {
+	return &InstrumentedUnitOfWork{next: next, observer: observer, logger: logger}
+}
+
+func (u *InstrumentedUnitOfWork) WithTx(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	start := time.Now()
+	err := u.next.WithTx(ctx, func(repos repository.Repositories) error {
+		repos.Tasks = NewInstrumentedTaskRepository(repos.Tasks, u.observer, u.logger)
+		repos.Events = NewInstrumentedTaskEventRepository(repos.Events, u.observer, u.logger)
+		return fn(repos)
+	})
+	observeCall(ctx, u.observer, u.logger, "WithTx", start, err)
+	return err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"todo/internal/domain/repository"
+)
+
+// MemoryUnitOfWork runs units of work as transactions of a
+// TaskMemoryRepository and a TaskEventMemoryRepository. It backs the memory
+// driver and stands in for a database in tests.
+type MemoryUnitOfWork struct {
+	tasks  *TaskMemoryRepository
+	events *TaskEventMemoryRepository
+}
+
+func NewMemoryUnitOfWork(tasks *TaskMemoryRepository, events *TaskEventMemoryRepository) *MemoryUnitOfWork {
+	return &MemoryUnitOfWork{tasks: tasks, events: events}
+}
+
+// WithTx nests a history transaction inside a task transaction. The history
+// is kept only if fn returns nil, and the task transaction then cannot fail,
+// so both are kept or discarded together.
+func (u *MemoryUnitOfWork) WithTx(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	return u.tasks.Transaction(ctx, func(tasks *TaskMemoryRepository) error {
+		return u.events.Transaction(ctx, func(events *TaskEventMemoryRepository) error {
+			return fn(repository.Repositories{Tasks: tasks, Events: events})
+		})
+	})
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math/rand/v2"
+	"time"
+	"todo/internal/domain/repository"
+
+	"github.com/lib/pq"
+)
+
+// IsolationLevels maps the isolation level names accepted in configuration
+// to their database/sql counterparts.
+var IsolationLevels = map[string]sql.IsolationLevel{
+	"read_committed":  sql.LevelReadCommitted,
+	"repeatable_read": sql.LevelRepeatableRead,
+	"serializable":    sql.LevelSerializable,
+}
+
+// txRetryDelay is the pause before the first retry of a transaction. It
+// doubles with every further retry and is jittered so that the transactions
+// that collided do not collide again.
+const txRetryDelay = 10 * time.Millisecond
+
+// TxOptions configure the transactions of a SQLUnitOfWork.
+type TxOptions struct {
+	// Isolation is the isolation level; sql.LevelDefault keeps the
+	// database's default.
+	Isolation sql.IsolationLevel
+	// MaxRetries is how many more times a transaction is run after failing
+	// because of a concurrent one.
+	MaxRetries int
+}
+
+// SQLUnitOfWork runs units of work in database/sql transactions and hands
+// them repositories bound to the transaction.
+type SQLUnitOfWork struct {
+	db        *sql.DB
+	opts      TxOptions
+	repos     func(tx dbtx) repository.Repositories
+	retryable func(err error) bool
+}
+
+// NewPgUnitOfWork runs transactions on Postgres. Transactions that fail with
+// a serialization failure or a deadlock are retried up to opts.MaxRetries
+// times; both are expected under repeatable_read and serializable.
+func NewPgUnitOfWork(db *sql.DB, opts TxOptions) *SQLUnitOfWork {
+	return &SQLUnitOfWork{
+		db:   db,
+		opts: opts,
+		repos: func(tx dbtx) repository.Repositories {
+			return repository.Repositories{Tasks: &TaskPgRepository{db: tx}, Events: &TaskEventPgRepository{db: tx}}
+		},
+		retryable: isPgRetryable,
+	}
+}
+
+// NewSQLiteUnitOfWork runs transactions on SQLite. SQLite lets one writer in
+// at a time and its transactions are always serializable, so there is no
+// isolation level to choose and nothing to retry.
+func NewSQLiteUnitOfWork(db *sql.DB) *SQLUnitOfWork {
+	return &SQLUnitOfWork{
+		db: db,
+		repos: func(tx dbtx) repository.Repositories {
+			return repository.Repositories{Tasks: &TaskSQLiteRepository{db: tx}, Events: &TaskEventSQLiteRepository{db: tx}}
+		},
+		retryable: func(error) bool { return false },
+	}
+}
+
+func (u *SQLUnitOfWork) WithTx(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	for attempt := 0; ; attempt++ {
+		err := u.run(ctx, fn)
+		if errors.Is(err, repository.ErrRollback) {
+			return nil
+		}
+		if err == nil || attempt >= u.opts.MaxRetries || !u.retryable(err) {
+			return err
+		}
+		delay := txRetryDelay << attempt
+		timer := time.NewTimer(delay/2 + rand.N(delay/2))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// run makes one attempt at the unit of work.
+func (u *SQLUnitOfWork) run(ctx context.Context, fn func(repos repository.Repositories) error) (err error) {
+	tx, err := u.db.BeginTx(ctx, &sql.TxOptions{Isolation: u.opts.Isolation})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			_ = tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err := fn(u.repos(tx)); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// isPgRetryable reports a serialization_failure or deadlock_detected error,
+// after which Postgres expects the whole transaction to be run again.
+func isPgRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
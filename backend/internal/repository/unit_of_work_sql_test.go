@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"todo/internal/domain/repository"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// deleteInTx deletes the task with the given id inside a unit of work.
+func deleteInTx(uow repository.UnitOfWork, id string) error {
+	return uow.WithTx(context.Background(), func(repos repository.Repositories) error {
+		return repos.Tasks.Delete(context.Background(), id, repository.AnyVersion)
+	})
+}
+
+// TestPgUnitOfWork_RetriesSerializationFailure checks that a transaction that hit a serialization
+// failure is run again in a new transaction, and that every attempt shows up in its span
+func TestPgUnitOfWork_RetriesSerializationFailure(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	uow := NewTracedUnitOfWork(NewPgUnitOfWork(db, TxOptions{Isolation: sql.LevelSerializable, MaxRetries: 1}), tp, "postgresql")
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tasks SET deleted_at").WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tasks SET deleted_at").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// Act
+	err := deleteInTx(uow, "test-id")
+
+	// Assert
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "TaskRepository.Delete", spans[0].Name())
+	assert.Equal(t, "UnitOfWork.WithTx", spans[2].Name())
+	assert.Contains(t, spans[2].Attributes(), attribute.Int("tx.attempts", 2))
+}
+
+// TestPgUnitOfWork_GivesUpAfterMaxRetries checks that the serialization failure of the last
+// attempt is returned
+func TestPgUnitOfWork_GivesUpAfterMaxRetries(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	uow := NewPgUnitOfWork(db, TxOptions{MaxRetries: 1})
+
+	for range 2 {
+		mock.ExpectBegin()
+		mock.ExpectExec("UPDATE tasks SET deleted_at").WillReturnError(&pq.Error{Code: "40P01"})
+		mock.ExpectRollback()
+	}
+
+	// Act
+	err := deleteInTx(uow, "test-id")
+
+	// Assert
+	var pqErr *pq.Error
+	require.ErrorAs(t, err, &pqErr)
+	assert.Equal(t, pq.ErrorCode("40P01"), pqErr.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestPgUnitOfWork_DoesNotRetryOtherErrors checks that only concurrency failures are retried
+func TestPgUnitOfWork_DoesNotRetryOtherErrors(t *testing.T) {
+	// Arrange
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+	uow := NewPgUnitOfWork(db, TxOptions{MaxRetries: 3})
+
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE tasks SET deleted_at").WillReturnError(&pq.Error{Code: "23505"})
+	mock.ExpectRollback()
+
+	// Act
+	err := deleteInTx(uow, "test-id")
+
+	// Assert
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
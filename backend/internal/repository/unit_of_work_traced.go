@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"todo/internal/domain/repository"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracedUnitOfWork records a span for every transaction of the wrapped unit
+// of work and traces the repositories it hands out like
+// TracedTaskRepository and TracedTaskEventRepository. The number of attempts goes to tx.attempts, so
+// retried transactions stand out.
+type TracedUnitOfWork struct {
+	next   repository.UnitOfWork
+	tp     trace.TracerProvider
+	tracer trace.Tracer
+	system string
+}
+
+// NewTracedUnitOfWork wraps next; system is passed on to the traced
+// repositories of every transaction.
+func NewTracedUnitOfWork(next repository.UnitOfWork, tp trace.TracerProvider, system string) *TracedUnitOfWork {
+	return &TracedUnitOfWork{next: next, tp: tp, tracer: tp.Tracer(tracerName), system: system}
+}
+
+func (u *TracedUnitOfWork) WithTx(ctx context.Context, fn func(repos repository.Repositories) error) error {
+	ctx, span := u.tracer.Start(ctx, "UnitOfWork.WithTx")
+	attempts := 0
+	err := u.next.WithTx(ctx, func(repos repository.Repositories) error {
+		attempts++
+		repos.Tasks = NewTracedTaskRepository(repos.Tasks, u.tp, u.system)
+		repos.Events = NewTracedTaskEventRepository(repos.Events, u.tp, u.system)
+		return fn(repos)
+	})
+	span.SetAttributes(attribute.Int("tx.attempts", attempts))
+	endSpan(span, err)
+	return err
+}
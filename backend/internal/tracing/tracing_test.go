@@ -0,0 +1,79 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"todo/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestNew_NoneDisablesTracing checks that no provider is built when tracing is off
+func TestNew_NoneDisablesTracing(t *testing.T) {
+	// Act
+	tp, err := New(context.Background(), config.TracingConfig{Exporter: "none", SampleRatio: 1}, "test")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, tp)
+}
+
+// TestNew_FileExporterWritesSpans checks that finished spans reach the trace file on shutdown
+func TestNew_FileExporterWritesSpans(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "spans.json")
+	ctx := context.Background()
+	tp, err := New(ctx, config.TracingConfig{Exporter: "file", File: path, SampleRatio: 1}, "abc123")
+	require.NoError(t, err)
+
+	// Act
+	_, span := tp.Tracer("test").Start(ctx, "TaskUsecase.GetTask")
+	span.End()
+	require.NoError(t, tp.Shutdown(ctx))
+
+	// Assert
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"Name":"TaskUsecase.GetTask"`)
+	assert.Contains(t, string(content), `"Value":"abc123"`)
+}
+
+// TestNew_SampleRatioZero checks that new traces are dropped when sampling is off
+func TestNew_SampleRatioZero(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "spans.json")
+	ctx := context.Background()
+	tp, err := New(ctx, config.TracingConfig{Exporter: "file", File: path, SampleRatio: 0}, "test")
+	require.NoError(t, err)
+	defer tp.Shutdown(ctx)
+
+	// Act
+	_, span := tp.Tracer("test").Start(ctx, "dropped")
+	span.End()
+
+	// Assert
+	assert.False(t, span.SpanContext().IsSampled())
+}
+
+// TestPropagator_ExtractsTraceparent checks that incoming W3C headers continue the caller's trace
+func TestPropagator_ExtractsTraceparent(t *testing.T) {
+	// Arrange
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+	// Act
+	ctx := Propagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+
+	// Assert
+	sc := trace.SpanContextFromContext(ctx)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID().String())
+	assert.True(t, sc.IsRemote())
+	assert.True(t, sc.IsSampled())
+}
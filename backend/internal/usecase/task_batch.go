@@ -0,0 +1,331 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
+	"todo/internal/validation"
+)
+
+// ApplyBatch reads every task the operations target with one query, prepares
+// each operation the way the single-task methods do and hands the writes to
+// the repository grouped by kind. A task may appear in one operation only,
+// so the order of the operations does not matter. The batch runs as one
+// transaction; an atomic one is rolled back when any operation fails, while
+// in best-effort mode the successful operations are kept.
+func (u *taskUsecase) ApplyBatch(ctx context.Context, ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+	var results []model.TaskOperationResult
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		var err error
+		results, err = applyBatch(ctx, repos, ops, atomic, u.settings.CompletionRule)
+		if err == nil && atomic && countFailed(results) > 0 {
+			return repository.ErrRollback
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	u.logger.InfoContext(ctx, "task batch applied",
+		slog.Int("operations", len(ops)),
+		slog.Int("failed", countFailed(results)),
+		slog.Bool("atomic", atomic),
+	)
+	return results, nil
+}
+
+// applyBatch does the work of ApplyBatch against repos and records the
+// successful operations in the history. In atomic mode it stops at the first
+// failed step and marks the remaining operations aborted.
+func applyBatch(ctx context.Context, repos repository.Repositories, ops []model.TaskOperation, atomic bool, rule model.CompletionRule) ([]model.TaskOperationResult, error) {
+	stored, err := findBatchTargets(ctx, repos.Tasks, ops)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	results := make([]model.TaskOperationResult, len(ops))
+	prepared := make([]*model.Task, len(ops))
+	origins := make([]model.TaskEventOrigin, len(ops))
+	seen := make(map[string]bool, len(ops))
+	for i, op := range ops {
+		id := op.ID
+		if op.Kind == model.OperationCreate {
+			id = op.Task.ID
+		}
+		if id != "" && seen[id] {
+			results[i].Err = validation.NewFieldError("id", validation.CodeDuplicate, i18n.BatchDuplicateTask, id)
+			continue
+		}
+		seen[id] = true
+
+		prepared[i], origins[i], results[i].Err = prepareOperation(op, stored, now)
+	}
+	if err := checkBatchSubtasks(ctx, repos.Tasks, ops, stored, prepared, results, rule); err != nil {
+		return nil, err
+	}
+	if atomic && countFailed(results) > 0 {
+		abortBatch(results)
+		return results, nil
+	}
+
+	var (
+		batch                     model.TaskBatch
+		creates, updates, deletes []int // positions in ops
+	)
+	for i, op := range ops {
+		if results[i].Err != nil {
+			continue
+		}
+		switch op.Kind {
+		case model.OperationCreate:
+			batch.Creates = append(batch.Creates, prepared[i])
+			creates = append(creates, i)
+		case model.OperationDelete:
+			batch.Deletes = append(batch.Deletes, model.TaskRef{ID: op.ID, Version: op.Version})
+			deletes = append(deletes, i)
+		default:
+			batch.Updates = append(batch.Updates, prepared[i])
+			updates = append(updates, i)
+		}
+	}
+
+	errs, err := writeBatch(ctx, repos.Tasks, &batch)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range creates {
+		results[i] = operationResult(batch.Creates[j], errs.Creates[j])
+	}
+	for j, i := range updates {
+		results[i] = operationResult(batch.Updates[j], errs.Updates[j])
+	}
+	for j, i := range deletes {
+		results[i].Err = errs.Deletes[j]
+	}
+	if atomic && errs.Failed() {
+		abortBatch(results)
+		return results, nil
+	}
+
+	// Events follow the order of the operations.
+	events := make([]*model.TaskEvent, len(ops))
+	for j, i := range creates {
+		if errs.Creates[j] == nil {
+			events[i] = newTaskEvent(ctx, model.TaskEventCreated, origins[i], batch.Creates[j], diffTask(nil, batch.Creates[j]), now)
+		}
+	}
+	for j, i := range updates {
+		if errs.Updates[j] == nil {
+			eventType := model.TaskEventUpdated
+			if ops[i].Kind == model.OperationSetCompletion {
+				eventType = model.TaskEventStatusChanged
+			}
+			events[i] = newTaskEvent(ctx, eventType, origins[i], batch.Updates[j], diffTask(stored[ops[i].ID], batch.Updates[j]), now)
+		}
+	}
+	for j, i := range deletes {
+		if errs.Deletes[j] == nil {
+			// Moving a task to the trash bumps its version.
+			deleted := *stored[ops[i].ID]
+			deleted.Version++
+			events[i] = newTaskEvent(ctx, model.TaskEventDeleted, model.OriginAPI, &deleted, nil, now)
+		}
+	}
+	if err := repos.Events.Append(ctx, slices.DeleteFunc(events, func(event *model.TaskEvent) bool { return event == nil })...); err != nil {
+		return nil, err
+	}
+	if err := attachProgress(ctx, repos.Tasks, batch.Updates...); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// checkBatchSubtasks fails the deletes of tasks that have subtasks and, under
+// CompletionRequireSubtasks, the completions of tasks with open subtasks. The
+// other operations of the batch on those subtasks count as if they had
+// already succeeded, so one batch can close or delete a task together with
+// its subtasks.
+func checkBatchSubtasks(ctx context.Context, repo repository.TaskRepository, ops []model.TaskOperation, stored map[string]*model.Task, prepared []*model.Task, results []model.TaskOperationResult, rule model.CompletionRule) error {
+	// Positions of the operations whose target may not have subtasks, or
+	// open ones, keyed by the target's ID.
+	checked := make(map[string]int)
+	for i, op := range ops {
+		if results[i].Err != nil || op.Kind == model.OperationCreate {
+			continue
+		}
+		completes := rule == model.CompletionRequireSubtasks && prepared[i].IsCompleted && !stored[op.ID].IsCompleted
+		if op.Kind == model.OperationDelete || completes {
+			checked[op.ID] = i
+		}
+	}
+	if len(checked) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(checked))
+	for id := range checked {
+		ids = append(ids, id)
+	}
+	counted, err := repo.CountSubtasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// Refusing one operation can refuse the one on its parent in turn, so the
+	// counts are taken again until nothing changes.
+	for changed := true; changed; {
+		changed = false
+		progress := maps.Clone(counted)
+		for i, op := range ops {
+			if results[i].Err != nil || op.Kind == model.OperationCreate || stored[op.ID].ParentID == nil {
+				continue
+			}
+			parentID := *stored[op.ID].ParentID
+			p, ok := progress[parentID]
+			if !ok {
+				continue
+			}
+			if stored[op.ID].IsCompleted {
+				p.Done--
+			}
+			if op.Kind == model.OperationDelete {
+				p.Total--
+			} else if prepared[i].IsCompleted {
+				p.Done++
+			}
+			progress[parentID] = p
+		}
+		for id, i := range checked {
+			p := progress[id]
+			switch {
+			case results[i].Err != nil:
+			case ops[i].Kind == model.OperationDelete && p.Total > 0:
+				results[i].Err = usecase.ErrTaskHasSubtasks
+				changed = true
+			case ops[i].Kind != model.OperationDelete && p.Done < p.Total:
+				results[i].Err = usecase.ErrSubtasksOpen
+				changed = true
+			}
+		}
+	}
+	return nil
+}
+
+// findBatchTargets loads the tasks that updates, completion changes and
+// deletes refer to, keyed by ID. Missing tasks are simply absent.
+func findBatchTargets(ctx context.Context, repo repository.TaskRepository, ops []model.TaskOperation) (map[string]*model.Task, error) {
+	var ids []string
+	for _, op := range ops {
+		if op.Kind != model.OperationCreate && op.ID != "" {
+			ids = append(ids, op.ID)
+		}
+	}
+	stored := make(map[string]*model.Task, len(ids))
+	if len(ids) == 0 {
+		return stored, nil
+	}
+	tasks, err := repo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		stored[task.ID] = task
+	}
+	return stored, nil
+}
+
+// prepareOperation returns the task an operation writes and the origin of
+// its values. It works on copies, so stored keeps the tasks as they were read
+// and a retried transaction starts over from the client's input. Updates and
+// completion changes keep the version that was read, so the write fails if
+// the task changes in the meantime.
+func prepareOperation(op model.TaskOperation, stored map[string]*model.Task, now time.Time) (*model.Task, model.TaskEventOrigin, error) {
+	if op.Kind == model.OperationCreate {
+		task := *op.Task
+		origin, err := prepareNewTask(&task, now)
+		if err != nil {
+			return nil, "", err
+		}
+		return &task, origin, nil
+	}
+	switch op.Kind {
+	case model.OperationUpdate, model.OperationSetCompletion, model.OperationDelete:
+	default:
+		return nil, "", validation.NewFieldError("op", validation.CodeOneOf, i18n.BatchOpUnsupported, string(op.Kind))
+	}
+
+	current, ok := stored[op.ID]
+	if !ok {
+		return nil, "", repository.ErrTaskNotFound
+	}
+	if op.Version != repository.AnyVersion && op.Version != current.Version {
+		return nil, "", repository.ErrVersionConflict
+	}
+	task := *current
+	origin := model.OriginAPI
+	switch op.Kind {
+	case model.OperationUpdate:
+		op.Apply(&task)
+		var err error
+		if origin, err = prepareTaskUpdate(&task, now); err != nil {
+			return nil, "", err
+		}
+	case model.OperationSetCompletion:
+		task.IsCompleted = op.IsCompleted
+		applyCompletion(&task, now)
+	}
+	return &task, origin, nil
+}
+
+// writeBatch runs the bulk statements one kind after another. Failed writes
+// do not affect the others; rolling them back is up to the caller.
+func writeBatch(ctx context.Context, repo repository.TaskRepository, batch *model.TaskBatch) (model.TaskBatchErrors, error) {
+	var (
+		errs model.TaskBatchErrors
+		err  error
+	)
+	if errs.Creates, err = repo.CreateMany(ctx, batch.Creates); err != nil {
+		return errs, err
+	}
+	if errs.Updates, err = repo.UpdateMany(ctx, batch.Updates); err != nil {
+		return errs, err
+	}
+	if errs.Deletes, err = repo.DeleteMany(ctx, batch.Deletes); err != nil {
+		return errs, err
+	}
+	return errs, nil
+}
+
+func operationResult(task *model.Task, err error) model.TaskOperationResult {
+	if err != nil {
+		return model.TaskOperationResult{Err: err}
+	}
+	return model.TaskOperationResult{Task: task}
+}
+
+// abortBatch marks every operation that did not fail by itself as aborted.
+func abortBatch(results []model.TaskOperationResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = model.TaskOperationResult{Err: usecase.ErrBatchAborted}
+		}
+	}
+}
+
+func countFailed(results []model.TaskOperationResult) int {
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
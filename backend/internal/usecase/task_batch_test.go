@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedTasks creates one active task per id.
+func seedTasks(t *testing.T, repo *mockTaskRepo, ids ...string) {
+	t.Helper()
+	for _, id := range ids {
+		require.NoError(t, repo.Create(context.Background(), &model.Task{
+			ID:        id,
+			Title:     "Task " + id,
+			Status:    model.StatusActive,
+			Priority:  model.PriorityMedium,
+			CreatedAt: time.Now().UTC(),
+		}))
+	}
+}
+
+// TestApplyBatch_BestEffort checks that every operation is applied on its own and failures are
+// reported next to the successes
+func TestApplyBatch_BestEffort(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "done", "renamed", "removed")
+
+	// Act
+	results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+		{Kind: model.OperationCreate, Task: &model.Task{Title: "New task !2"}},
+		{Kind: model.OperationSetCompletion, ID: "done", IsCompleted: true},
+		{Kind: model.OperationUpdate, ID: "renamed", Apply: func(task *model.Task) { task.Title = "Renamed task" }},
+		{Kind: model.OperationDelete, ID: "removed", Version: 1},
+		{Kind: model.OperationDelete, ID: "missing"},
+	}, false)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, model.PriorityHigh, results[0].Task.Priority)
+	assert.Equal(t, model.StatusCompleted, results[1].Task.Status)
+	assert.Equal(t, int64(2), results[1].Task.Version)
+	assert.Equal(t, "Renamed task", results[2].Task.Title)
+	assert.NoError(t, results[3].Err)
+	assert.Nil(t, results[3].Task)
+	assert.ErrorIs(t, results[4].Err, domainrepository.ErrTaskNotFound)
+	_, err = repo.FindByID(context.Background(), "removed")
+	assert.ErrorIs(t, err, domainrepository.ErrTaskNotFound)
+}
+
+// TestApplyBatch_AtomicAborts checks that one failing operation leaves every task untouched in atomic mode
+func TestApplyBatch_AtomicAborts(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "done", "stale")
+
+	// Act
+	results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+		{Kind: model.OperationSetCompletion, ID: "done", IsCompleted: true},
+		{Kind: model.OperationDelete, ID: "stale", Version: 3},
+	}, true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, usecase.ErrBatchAborted)
+	assert.ErrorIs(t, results[1].Err, domainrepository.ErrVersionConflict)
+	stored, err := repo.FindByID(context.Background(), "done")
+	require.NoError(t, err)
+	assert.False(t, stored.IsCompleted)
+	assert.Equal(t, int64(1), stored.Version)
+}
+
+// TestApplyBatch_AtomicWriteFails checks that a write rejected by the repository rolls back the others
+func TestApplyBatch_AtomicWriteFails(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	const id = "0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"
+	seedTasks(t, repo, id, "done")
+
+	// Act: the create passes validation but its id is taken
+	results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+		{Kind: model.OperationSetCompletion, ID: "done", IsCompleted: true},
+		{Kind: model.OperationCreate, Task: &model.Task{ID: id, Title: "Offline task"}},
+	}, true)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ErrorIs(t, results[0].Err, usecase.ErrBatchAborted)
+	assert.ErrorIs(t, results[1].Err, domainrepository.ErrTaskExists)
+	stored, err := repo.FindByID(context.Background(), "done")
+	require.NoError(t, err)
+	assert.False(t, stored.IsCompleted)
+}
+
+// TestApplyBatch_DuplicateTask checks that a task may be the target of one operation only
+func TestApplyBatch_DuplicateTask(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "twice")
+
+	// Act
+	results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+		{Kind: model.OperationSetCompletion, ID: "twice", IsCompleted: true},
+		{Kind: model.OperationDelete, ID: "twice"},
+	}, false)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	var vErr *validation.ValidationError
+	require.ErrorAs(t, results[1].Err, &vErr)
+	assert.Equal(t, validation.CodeDuplicate, vErr.Fields[0].Code)
+	_, err = repo.FindByID(context.Background(), "twice")
+	assert.NoError(t, err)
+}
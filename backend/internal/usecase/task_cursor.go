@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"todo/internal/domain/model"
+)
+
+// taskCursorPayload is the JSON document behind the opaque cursor string. It
+// records the sort it was issued for so that a cursor cannot be replayed
+// against a different ordering.
+type taskCursorPayload struct {
+	SortBy    string             `json:"s,omitempty"`
+	Desc      bool               `json:"d,omitempty"`
+	Deadline  *time.Time         `json:"dl,omitempty"`
+	CreatedAt *time.Time         `json:"ca,omitempty"`
+	Priority  model.TaskPriority `json:"p,omitempty"`
+	ID        string             `json:"id"`
+}
+
+func encodeTaskCursor(filter *model.TaskFilter, last *model.Task) string {
+	payload := taskCursorPayload{
+		SortBy: filter.SortBy,
+		Desc:   filter.IsDescending(),
+		ID:     last.ID,
+	}
+	switch filter.SortBy {
+	case "deadline":
+		payload.Deadline = last.Deadline
+	case "priority":
+		payload.Priority = last.Priority
+	default:
+		createdAt := last.CreatedAt
+		payload.CreatedAt = &createdAt
+	}
+	raw, _ := json.Marshal(payload)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeTaskCursor(filter *model.TaskFilter, cursor string) (*model.TaskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, err
+	}
+	var payload taskCursorPayload
+	if err := json.Unmarshal(raw, &payload); err != nil {
+		return nil, err
+	}
+	if payload.ID == "" {
+		return nil, errors.New("cursor has no id")
+	}
+	if payload.SortBy != filter.SortBy || payload.Desc != filter.IsDescending() {
+		return nil, errCursorSortMismatch
+	}
+
+	result := &model.TaskCursor{ID: payload.ID, Deadline: payload.Deadline, Priority: payload.Priority}
+	switch filter.SortBy {
+	case "deadline":
+		// A nil deadline is a valid position among tasks without one.
+	case "priority":
+		if !payload.Priority.IsValid() {
+			return nil, errors.New("cursor has no known priority")
+		}
+	default:
+		if payload.CreatedAt == nil {
+			return nil, errors.New("cursor has no created_at")
+		}
+		result.CreatedAt = *payload.CreatedAt
+	}
+	return result, nil
+}
+
+var errCursorSortMismatch = errors.New("cursor does not match sort parameters")
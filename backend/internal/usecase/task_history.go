@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/logging"
+)
+
+// trackedTaskFields are the task fields whose changes go into the history,
+// under their names in the API. set writes a recorded value back for undo; it
+// is nil for status, which is always recalculated instead, and for the parent,
+// which only a restore changes.
+var trackedTaskFields = []struct {
+	name  string
+	value func(task *model.Task) any
+	set   func(task *model.Task, data json.RawMessage) error
+}{
+	{"title", func(task *model.Task) any { return task.Title }, func(task *model.Task, data json.RawMessage) error {
+		return decodeValue(data, &task.Title)
+	}},
+	{"description", func(task *model.Task) any { return task.Description }, func(task *model.Task, data json.RawMessage) error {
+		return decodeValue(data, &task.Description)
+	}},
+	{"deadline", func(task *model.Task) any {
+		if task.Deadline == nil {
+			return nil
+		}
+		return task.Deadline.UTC()
+	}, func(task *model.Task, data json.RawMessage) error {
+		return decodeValue(data, &task.Deadline)
+	}},
+	{"priority", func(task *model.Task) any { return task.Priority }, func(task *model.Task, data json.RawMessage) error {
+		return decodeValue(data, &task.Priority)
+	}},
+	{"status", func(task *model.Task) any { return task.Status }, nil},
+	{"parent_id", func(task *model.Task) any { return task.ParentID }, nil},
+	{"is_completed", func(task *model.Task) any { return task.IsCompleted }, func(task *model.Task, data json.RawMessage) error {
+		return decodeValue(data, &task.IsCompleted)
+	}},
+}
+
+// diffTask returns the tracked fields that differ between before and after.
+// A nil before stands for a task that did not exist yet.
+func diffTask(before, after *model.Task) []model.TaskFieldChange {
+	changes := []model.TaskFieldChange{}
+	for _, field := range trackedTaskFields {
+		old := json.RawMessage("null")
+		if before != nil {
+			old = jsonValue(field.value(before))
+		}
+		updated := jsonValue(field.value(after))
+		if !bytes.Equal(old, updated) {
+			changes = append(changes, model.TaskFieldChange{Field: field.name, Old: old, New: updated})
+		}
+	}
+	return changes
+}
+
+// jsonValue encodes a field value. The tracked fields are strings, times and
+// booleans, which always encode.
+func jsonValue(v any) json.RawMessage {
+	data, _ := json.Marshal(v)
+	return data
+}
+
+// decodeValue decodes a recorded value into a fresh T before storing it in
+// dst, so that pointer fields never write through to a value shared with
+// another copy of the task.
+func decodeValue[T any](data json.RawMessage, dst *T) error {
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*dst = value
+	return nil
+}
+
+// revertChanges sets the fields of changes back to their old values.
+func revertChanges(task *model.Task, changes []model.TaskFieldChange) error {
+	for _, change := range changes {
+		for _, field := range trackedTaskFields {
+			if field.name != change.Field || field.set == nil {
+				continue
+			}
+			if err := field.set(task, change.Old); err != nil {
+				return fmt.Errorf("revert %s: %w", change.Field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// newTaskEvent records a change that brought task to its current version.
+func newTaskEvent(ctx context.Context, eventType model.TaskEventType, origin model.TaskEventOrigin, task *model.Task, changes []model.TaskFieldChange, now time.Time) *model.TaskEvent {
+	return &model.TaskEvent{
+		TaskID:    task.ID,
+		Type:      eventType,
+		Origin:    origin,
+		Version:   task.Version,
+		Changes:   changes,
+		RequestID: logging.RequestID(ctx),
+		CreatedAt: now,
+	}
+}
+
+func (u *taskUsecase) GetTaskHistory(ctx context.Context, id string) ([]*model.TaskEvent, error) {
+	events, err := u.events.FindByTaskID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) > 0 {
+		return events, nil
+	}
+
+	// Tasks created before the history was kept have none; only unknown
+	// tasks are an error.
+	_, err = u.repo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrTaskNotFound) {
+		_, err = u.repo.FindDeletedByID(ctx, id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventTypes returns the types of events in order.
+func eventTypes(events []*model.TaskEvent) []model.TaskEventType {
+	types := make([]model.TaskEventType, 0, len(events))
+	for _, event := range events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+// changedFields returns the fields of changes in order.
+func changedFields(changes []model.TaskFieldChange) []string {
+	fields := make([]string, 0, len(changes))
+	for _, change := range changes {
+		fields = append(fields, change.Field)
+	}
+	return fields
+}
+
+// TestGetTaskHistory_RecordsLifecycle checks that every change of a task is recorded with the version
+// it produced, the changed fields and its origin
+func TestGetTaskHistory_RecordsLifecycle(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	ctx := context.Background()
+
+	// Act
+	created, err := uc.CreateTask(ctx, &model.Task{Title: "Write report !1"})
+	require.NoError(t, err)
+	edited := *created
+	edited.Title = "Write the report"
+	updated, err := uc.UpdateTask(ctx, &edited)
+	require.NoError(t, err)
+	completed := *updated
+	completed.IsCompleted = true
+	_, err = uc.SetTaskCompletion(ctx, &completed)
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteTask(ctx, created.ID, 3))
+	_, err = uc.RestoreTask(ctx, created.ID, 4)
+	require.NoError(t, err)
+	events, err := uc.GetTaskHistory(ctx, created.ID)
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, []model.TaskEventType{
+		model.TaskEventCreated,
+		model.TaskEventUpdated,
+		model.TaskEventStatusChanged,
+		model.TaskEventDeleted,
+		model.TaskEventRestored,
+	}, eventTypes(events))
+	for i, event := range events {
+		assert.Equal(t, int64(i+1), event.Version)
+	}
+	assert.Equal(t, model.OriginMacro, events[0].Origin)
+	assert.Contains(t, changedFields(events[0].Changes), "priority")
+	assert.Equal(t, model.OriginAPI, events[1].Origin)
+	assert.Equal(t, []string{"title"}, changedFields(events[1].Changes))
+	assert.JSONEq(t, `"Write report"`, string(events[1].Changes[0].Old))
+	assert.JSONEq(t, `"Write the report"`, string(events[1].Changes[0].New))
+	assert.Equal(t, []string{"status", "is_completed"}, changedFields(events[2].Changes))
+	assert.Empty(t, events[3].Changes)
+	assert.Empty(t, events[4].Changes)
+}
+
+// TestGetTaskHistory_RecordsOverdueTransition checks that the overdue job records its status changes
+// as coming from cron
+func TestGetTaskHistory_RecordsOverdueTransition(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Create(context.Background(), &model.Task{
+		ID:       "overdue",
+		Title:    "Overdue task",
+		Deadline: &past,
+		Status:   model.StatusActive,
+		Priority: model.PriorityMedium,
+	}))
+
+	// Act
+	transitioned, err := uc.UpdateOverdueTasks(context.Background())
+	require.NoError(t, err)
+	events, err := uc.GetTaskHistory(context.Background(), "overdue")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, transitioned)
+	require.Len(t, events, 1)
+	assert.Equal(t, model.TaskEventStatusChanged, events[0].Type)
+	assert.Equal(t, model.OriginCron, events[0].Origin)
+	assert.Equal(t, int64(2), events[0].Version)
+	assert.Equal(t, []model.TaskFieldChange{
+		{Field: "status", Old: json.RawMessage(`"ACTIVE"`), New: json.RawMessage(`"OVERDUE"`)},
+	}, events[0].Changes)
+}
+
+// TestGetTaskHistory_InvalidUpdateLeavesNoEvent checks that a rejected update is not recorded
+func TestGetTaskHistory_InvalidUpdateLeavesNoEvent(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "task")
+
+	// Act
+	invalid, err := repo.FindByID(context.Background(), "task")
+	require.NoError(t, err)
+	invalid.Priority = "URGENT"
+	_, updateErr := uc.UpdateTask(context.Background(), invalid)
+	events, err := uc.GetTaskHistory(context.Background(), "task")
+
+	// Assert
+	assert.Error(t, updateErr)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+// TestGetTaskHistory_UnknownTask checks that only a task that exists nowhere has no history to return
+func TestGetTaskHistory_UnknownTask(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "untracked")
+
+	// Act
+	untracked, untrackedErr := uc.GetTaskHistory(context.Background(), "untracked")
+	_, missingErr := uc.GetTaskHistory(context.Background(), "missing")
+
+	// Assert
+	require.NoError(t, untrackedErr)
+	assert.Empty(t, untracked)
+	assert.ErrorIs(t, missingErr, domainrepository.ErrTaskNotFound)
+}
+
+// TestGetTaskHistory_RecordsBatch checks that a batch records one event per applied operation and
+// that an aborted atomic batch records none
+func TestGetTaskHistory_RecordsBatch(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "done", "renamed", "removed", "untouched")
+	ctx := context.Background()
+
+	// Act
+	results, err := uc.ApplyBatch(ctx, []model.TaskOperation{
+		{Kind: model.OperationCreate, Task: &model.Task{ID: "0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60", Title: "New task !2"}},
+		{Kind: model.OperationSetCompletion, ID: "done", IsCompleted: true},
+		{Kind: model.OperationUpdate, ID: "renamed", Apply: func(task *model.Task) { task.Title = "Renamed task" }},
+		{Kind: model.OperationDelete, ID: "removed"},
+		{Kind: model.OperationDelete, ID: "missing"},
+	}, false)
+	require.NoError(t, err)
+	_, err = uc.ApplyBatch(ctx, []model.TaskOperation{
+		{Kind: model.OperationUpdate, ID: "untouched", Apply: func(task *model.Task) { task.Title = "Changed task" }},
+		{Kind: model.OperationDelete, ID: "missing"},
+	}, true)
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, results, 5)
+	expected := map[string]struct {
+		eventType model.TaskEventType
+		version   int64
+	}{
+		results[0].Task.ID: {model.TaskEventCreated, 1},
+		"done":             {model.TaskEventStatusChanged, 2},
+		"renamed":          {model.TaskEventUpdated, 2},
+		"removed":          {model.TaskEventDeleted, 2},
+	}
+	for id, want := range expected {
+		events, err := uc.GetTaskHistory(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, []model.TaskEventType{want.eventType}, eventTypes(events), id)
+		assert.Equal(t, want.version, events[0].Version, id)
+	}
+	created, err := uc.GetTaskHistory(ctx, results[0].Task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.OriginMacro, created[0].Origin)
+	untouched, err := uc.GetTaskHistory(ctx, "untouched")
+	require.NoError(t, err)
+	assert.Empty(t, untouched)
+}
+
+// TestPurgeDeletedTasks_DropsHistory checks that a purged task takes its history with it
+func TestPurgeDeletedTasks_DropsHistory(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	created, err := uc.CreateTask(context.Background(), &model.Task{Title: "Short-lived task"})
+	require.NoError(t, err)
+	require.NoError(t, uc.DeleteTask(context.Background(), created.ID, domainrepository.AnyVersion))
+
+	// Act
+	purged, err := uc.PurgeDeletedTasks(context.Background(), -time.Hour)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	events, err := repo.events.FindByTaskID(context.Background(), created.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
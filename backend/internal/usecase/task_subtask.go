@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+)
+
+// CreateSubtask creates the subtask like CreateTask does and checks the depth
+// in the same transaction, so the parent cannot be deleted in the meantime.
+func (u *taskUsecase) CreateSubtask(ctx context.Context, parentID string, task *model.Task) (*model.Task, error) {
+	origin, err := prepareNewTask(task, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	task.ParentID = &parentID
+
+	var refused error
+	err = u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		parent, err := repos.Tasks.FindByID(ctx, parentID)
+		if err != nil {
+			return err
+		}
+		depth, err := taskDepth(ctx, repos.Tasks, parent)
+		if err != nil {
+			return err
+		}
+		if depth+1 > u.settings.MaxSubtaskDepth {
+			refused = usecase.ErrSubtaskDepthExceeded
+			return repository.ErrRollback
+		}
+		if err := repos.Tasks.Create(ctx, task); err != nil {
+			return err
+		}
+		return repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventCreated, origin, task, diffTask(nil, task), task.CreatedAt))
+	})
+	if err == nil {
+		err = refused
+	}
+	if err != nil {
+		return nil, err
+	}
+	u.logger.InfoContext(ctx, "subtask created", slog.String("task_id", task.ID), slog.String("parent_id", parentID))
+
+	return task, nil
+}
+
+func (u *taskUsecase) ListSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	if _, err := u.repo.FindByID(ctx, parentID); err != nil {
+		return nil, err
+	}
+	tasks, err := u.repo.FindSubtasks(ctx, parentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := attachProgress(ctx, u.repo, tasks...); err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// taskDepth returns how many ancestors task has. A live task's parent is
+// always live, so every ancestor is found.
+func taskDepth(ctx context.Context, repo repository.TaskRepository, task *model.Task) (int, error) {
+	depth := 0
+	for task.ParentID != nil {
+		parent, err := repo.FindByID(ctx, *task.ParentID)
+		if err != nil {
+			return 0, err
+		}
+		task = parent
+		depth++
+	}
+	return depth, nil
+}
+
+// checkCompletion refuses to complete a task that has open subtasks when the
+// completion rule asks for it. Only completing is checked: a subtask may
+// still be added to or reopened under a completed task.
+func (u *taskUsecase) checkCompletion(ctx context.Context, repo repository.TaskRepository, before, after *model.Task) error {
+	if u.settings.CompletionRule != model.CompletionRequireSubtasks || before.IsCompleted || !after.IsCompleted {
+		return nil
+	}
+	progress, err := repo.CountSubtasks(ctx, []string{after.ID})
+	if err != nil {
+		return err
+	}
+	if p := progress[after.ID]; p.Done < p.Total {
+		return usecase.ErrSubtasksOpen
+	}
+	return nil
+}
+
+// attachProgress sets the progress of the tasks that have subtasks with one
+// query.
+func attachProgress(ctx context.Context, repo repository.TaskRepository, tasks ...*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, len(tasks))
+	for i, task := range tasks {
+		ids[i] = task.ID
+	}
+	progress, err := repo.CountSubtasks(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if p, ok := progress[task.ID]; ok {
+			task.Progress = &p
+		}
+	}
+	return nil
+}
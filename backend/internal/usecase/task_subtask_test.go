@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createTree creates a task through uc together with subtasks of the given titles.
+func createTree(t *testing.T, uc *taskUsecase, titles ...string) (*model.Task, []*model.Task) {
+	t.Helper()
+	parent, err := uc.CreateTask(context.Background(), &model.Task{Title: "Ship release"})
+	require.NoError(t, err)
+	subtasks := make([]*model.Task, 0, len(titles))
+	for _, title := range titles {
+		subtask, err := uc.CreateSubtask(context.Background(), parent.ID, &model.Task{Title: title})
+		require.NoError(t, err)
+		subtasks = append(subtasks, subtask)
+	}
+	return parent, subtasks
+}
+
+// completeTask marks a task stored through uc completed.
+func completeTask(t *testing.T, uc *taskUsecase, id string) {
+	t.Helper()
+	task, err := uc.GetTask(context.Background(), id)
+	require.NoError(t, err)
+	task.IsCompleted = true
+	_, err = uc.SetTaskCompletion(context.Background(), task)
+	require.NoError(t, err)
+}
+
+// TestCreateSubtask checks that a subtask is linked to its parent, listed under it and counted in
+// its progress
+func TestCreateSubtask(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	parent, subtasks := createTree(t, uc, "Tag the build", "Write notes !1")
+
+	// Act
+	completeTask(t, uc, subtasks[0].ID)
+	listed, err := uc.ListSubtasks(context.Background(), parent.ID)
+	require.NoError(t, err)
+	stored, err := uc.GetTask(context.Background(), parent.ID)
+	require.NoError(t, err)
+
+	// Assert
+	require.NotNil(t, subtasks[1].ParentID)
+	assert.Equal(t, parent.ID, *subtasks[1].ParentID)
+	assert.Equal(t, model.PriorityCritical, subtasks[1].Priority)
+	assert.Equal(t, []string{"Tag the build", "Write notes"}, []string{listed[0].Title, listed[1].Title})
+	assert.Nil(t, listed[0].Progress)
+	assert.Equal(t, &model.TaskProgress{Done: 1, Total: 2}, stored.Progress)
+	events, err := uc.GetTaskHistory(context.Background(), subtasks[0].ID)
+	require.NoError(t, err)
+	assert.Contains(t, changedFields(events[0].Changes), "parent_id")
+}
+
+// TestCreateSubtask_Refused checks that subtasks need a live parent within the depth limit
+func TestCreateSubtask_Refused(t *testing.T) {
+	t.Run("unknown parent", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+		_, err := uc.CreateSubtask(context.Background(), "missing", &model.Task{Title: "Orphan"})
+
+		assert.ErrorIs(t, err, domainrepository.ErrTaskNotFound)
+	})
+
+	t.Run("too deep", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		_, subtasks := createTree(t, uc, "Level 1")
+		nested, err := uc.CreateSubtask(context.Background(), subtasks[0].ID, &model.Task{Title: "Level 2"})
+		require.NoError(t, err)
+
+		_, err = uc.CreateSubtask(context.Background(), nested.ID, &model.Task{Title: "Level 3"})
+
+		assert.ErrorIs(t, err, usecase.ErrSubtaskDepthExceeded)
+		listed, err := uc.ListSubtasks(context.Background(), nested.ID)
+		require.NoError(t, err)
+		assert.Empty(t, listed)
+	})
+}
+
+// TestUpdateTask_KeepsParent checks that a full update cannot move a subtask
+func TestUpdateTask_KeepsParent(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	parent, subtasks := createTree(t, uc, "Tag the build")
+	edited := *subtasks[0]
+	edited.Title = "Tag the release build"
+	edited.ParentID = nil
+
+	// Act
+	updated, err := uc.UpdateTask(context.Background(), &edited)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, updated.ParentID)
+	assert.Equal(t, parent.ID, *updated.ParentID)
+}
+
+// TestCompletionRule checks that under require_subtasks a task can only be completed once its
+// subtasks are
+func TestCompletionRule(t *testing.T) {
+	settings := testSettings
+	settings.CompletionRule = model.CompletionRequireSubtasks
+
+	t.Run("refused while a subtask is open", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, settings, discardLogger)
+		parent, subtasks := createTree(t, uc, "Tag the build", "Write notes")
+		completeTask(t, uc, subtasks[0].ID)
+
+		parent.IsCompleted = true
+		_, err := uc.SetTaskCompletion(context.Background(), parent)
+		assert.ErrorIs(t, err, usecase.ErrSubtasksOpen)
+		edited := *parent
+		_, err = uc.UpdateTask(context.Background(), &edited)
+		assert.ErrorIs(t, err, usecase.ErrSubtasksOpen)
+
+		stored, err := uc.GetTask(context.Background(), parent.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.IsCompleted)
+		assert.Equal(t, int64(1), stored.Version)
+	})
+
+	t.Run("allowed once every subtask is done", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, settings, discardLogger)
+		parent, subtasks := createTree(t, uc, "Tag the build", "Write notes")
+		for _, subtask := range subtasks {
+			completeTask(t, uc, subtask.ID)
+		}
+
+		parent.IsCompleted = true
+		completed, err := uc.SetTaskCompletion(context.Background(), parent)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.StatusCompleted, completed.Status)
+		assert.Equal(t, &model.TaskProgress{Done: 2, Total: 2}, completed.Progress)
+	})
+
+	t.Run("independent by default", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		parent, _ := createTree(t, uc, "Tag the build")
+
+		parent.IsCompleted = true
+		_, err := uc.SetTaskCompletion(context.Background(), parent)
+
+		assert.NoError(t, err)
+	})
+}
+
+// TestDeleteTask_WithSubtasks checks that a task is only deleted after its subtasks, and that a
+// subtask restored without its parent comes back on its own
+func TestDeleteTask_WithSubtasks(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	parent, subtasks := createTree(t, uc, "Tag the build")
+
+	// Act
+	refused := uc.DeleteTask(context.Background(), parent.ID, domainrepository.AnyVersion)
+	require.NoError(t, uc.DeleteTask(context.Background(), subtasks[0].ID, domainrepository.AnyVersion))
+	require.NoError(t, uc.DeleteTask(context.Background(), parent.ID, domainrepository.AnyVersion))
+	restored, err := uc.RestoreTask(context.Background(), subtasks[0].ID, domainrepository.AnyVersion)
+
+	// Assert
+	assert.ErrorIs(t, refused, usecase.ErrTaskHasSubtasks)
+	require.NoError(t, err)
+	assert.Nil(t, restored.ParentID)
+	events, err := uc.GetTaskHistory(context.Background(), subtasks[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"parent_id"}, changedFields(events[len(events)-1].Changes))
+}
+
+// TestApplyBatch_Subtasks checks that a batch may close or delete a task together with its
+// subtasks but not without them
+func TestApplyBatch_Subtasks(t *testing.T) {
+	settings := testSettings
+	settings.CompletionRule = model.CompletionRequireSubtasks
+
+	t.Run("together", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, settings, discardLogger)
+		parent, subtasks := createTree(t, uc, "Tag the build", "Write notes")
+
+		results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+			{Kind: model.OperationSetCompletion, ID: parent.ID, IsCompleted: true},
+			{Kind: model.OperationSetCompletion, ID: subtasks[0].ID, IsCompleted: true},
+			{Kind: model.OperationDelete, ID: subtasks[1].ID},
+		}, true)
+
+		require.NoError(t, err)
+		assert.Zero(t, countFailed(results))
+		assert.Equal(t, &model.TaskProgress{Done: 1, Total: 1}, results[0].Task.Progress)
+	})
+
+	t.Run("without them", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, settings, discardLogger)
+		parent, subtasks := createTree(t, uc, "Tag the build")
+		nested, err := uc.CreateSubtask(context.Background(), subtasks[0].ID, &model.Task{Title: "Sign the build"})
+		require.NoError(t, err)
+
+		results, err := uc.ApplyBatch(context.Background(), []model.TaskOperation{
+			{Kind: model.OperationSetCompletion, ID: parent.ID, IsCompleted: true},
+			{Kind: model.OperationDelete, ID: subtasks[0].ID},
+			{Kind: model.OperationSetCompletion, ID: nested.ID, IsCompleted: true},
+		}, false)
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, usecase.ErrSubtasksOpen)
+		assert.ErrorIs(t, results[1].Err, usecase.ErrTaskHasSubtasks)
+		assert.NoError(t, results[2].Err)
+		stored, err := uc.GetTask(context.Background(), subtasks[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, &model.TaskProgress{Done: 1, Total: 1}, stored.Progress)
+	})
+}
@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/validation"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "todo/internal/usecase"
+
+// TracedTaskUsecase records a span for every call to the wrapped usecase.
+// Validation errors, missing tasks, version conflicts, refused undos and
+// broken subtask rules are answers to the client rather than failures, so they
+// are attached as events without marking the span failed.
+type TracedTaskUsecase struct {
+	next   usecase.TaskUsecase
+	tracer trace.Tracer
+}
+
+func NewTracedTaskUsecase(next usecase.TaskUsecase, tp trace.TracerProvider) *TracedTaskUsecase {
+	return &TracedTaskUsecase{next: next, tracer: tp.Tracer(tracerName)}
+}
+
+func (u *TracedTaskUsecase) CreateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.CreateTask")
+	created, err := u.next.CreateTask(ctx, task)
+	if created != nil {
+		span.SetAttributes(attribute.String("task.id", created.ID))
+	}
+	endSpan(span, err)
+	return created, err
+}
+
+func (u *TracedTaskUsecase) UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.UpdateTask", trace.WithAttributes(attribute.String("task.id", task.ID)))
+	updated, err := u.next.UpdateTask(ctx, task)
+	endSpan(span, err)
+	return updated, err
+}
+
+func (u *TracedTaskUsecase) DeleteTask(ctx context.Context, id string, version int64) error {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.DeleteTask", trace.WithAttributes(
+		attribute.String("task.id", id),
+		attribute.Int64("task.version", version),
+	))
+	err := u.next.DeleteTask(ctx, id, version)
+	endSpan(span, err)
+	return err
+}
+
+func (u *TracedTaskUsecase) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.GetTask", trace.WithAttributes(attribute.String("task.id", id)))
+	task, err := u.next.GetTask(ctx, id)
+	endSpan(span, err)
+	return task, err
+}
+
+func (u *TracedTaskUsecase) ListTasksWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.ListTasksWithFilter", trace.WithAttributes(
+		attribute.Int("page", filter.Page),
+		attribute.Int("page_size", filter.PageSize),
+		attribute.String("sort_by", filter.SortBy),
+	))
+	tasks, total, err := u.next.ListTasksWithFilter(ctx, filter)
+	span.SetAttributes(attribute.Int("task.count", len(tasks)), attribute.Int("task.total", total))
+	endSpan(span, err)
+	return tasks, total, err
+}
+
+func (u *TracedTaskUsecase) ListTasksByCursor(ctx context.Context, filter *model.TaskFilter, cursor string) ([]*model.Task, string, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.ListTasksByCursor", trace.WithAttributes(
+		attribute.Int("page_size", filter.PageSize),
+		attribute.String("sort_by", filter.SortBy),
+		attribute.Bool("first_page", cursor == ""),
+	))
+	tasks, next, err := u.next.ListTasksByCursor(ctx, filter, cursor)
+	span.SetAttributes(attribute.Int("task.count", len(tasks)))
+	endSpan(span, err)
+	return tasks, next, err
+}
+
+func (u *TracedTaskUsecase) SetTaskCompletion(ctx context.Context, task *model.Task) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.SetTaskCompletion", trace.WithAttributes(
+		attribute.String("task.id", task.ID),
+		attribute.Bool("task.completed", task.IsCompleted),
+	))
+	updated, err := u.next.SetTaskCompletion(ctx, task)
+	endSpan(span, err)
+	return updated, err
+}
+
+func (u *TracedTaskUsecase) ApplyBatch(ctx context.Context, ops []model.TaskOperation, atomic bool) ([]model.TaskOperationResult, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.ApplyBatch", trace.WithAttributes(
+		attribute.Int("batch.operations", len(ops)),
+		attribute.Bool("batch.atomic", atomic),
+	))
+	results, err := u.next.ApplyBatch(ctx, ops, atomic)
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	span.SetAttributes(attribute.Int("batch.failed", failed))
+	endSpan(span, err)
+	return results, err
+}
+
+func (u *TracedTaskUsecase) UndoTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.UndoTask", trace.WithAttributes(
+		attribute.String("task.id", id),
+		attribute.Int64("task.version", version),
+	))
+	task, err := u.next.UndoTask(ctx, id, version)
+	endSpan(span, err)
+	return task, err
+}
+
+func (u *TracedTaskUsecase) CreateSubtask(ctx context.Context, parentID string, task *model.Task) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.CreateSubtask", trace.WithAttributes(attribute.String("task.parent_id", parentID)))
+	created, err := u.next.CreateSubtask(ctx, parentID, task)
+	if created != nil {
+		span.SetAttributes(attribute.String("task.id", created.ID))
+	}
+	endSpan(span, err)
+	return created, err
+}
+
+func (u *TracedTaskUsecase) ListSubtasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.ListSubtasks", trace.WithAttributes(attribute.String("task.parent_id", parentID)))
+	tasks, err := u.next.ListSubtasks(ctx, parentID)
+	span.SetAttributes(attribute.Int("task.count", len(tasks)))
+	endSpan(span, err)
+	return tasks, err
+}
+
+func (u *TracedTaskUsecase) GetTaskHistory(ctx context.Context, id string) ([]*model.TaskEvent, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.GetTaskHistory", trace.WithAttributes(attribute.String("task.id", id)))
+	events, err := u.next.GetTaskHistory(ctx, id)
+	span.SetAttributes(attribute.Int("task.event_count", len(events)))
+	endSpan(span, err)
+	return events, err
+}
+
+func (u *TracedTaskUsecase) GetDeletedTask(ctx context.Context, id string) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.GetDeletedTask", trace.WithAttributes(attribute.String("task.id", id)))
+	task, err := u.next.GetDeletedTask(ctx, id)
+	endSpan(span, err)
+	return task, err
+}
+
+func (u *TracedTaskUsecase) ListDeletedTasks(ctx context.Context, page, pageSize int) ([]*model.Task, int, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.ListDeletedTasks", trace.WithAttributes(
+		attribute.Int("page", page),
+		attribute.Int("page_size", pageSize),
+	))
+	tasks, total, err := u.next.ListDeletedTasks(ctx, page, pageSize)
+	span.SetAttributes(attribute.Int("task.count", len(tasks)), attribute.Int("task.total", total))
+	endSpan(span, err)
+	return tasks, total, err
+}
+
+func (u *TracedTaskUsecase) RestoreTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.RestoreTask", trace.WithAttributes(
+		attribute.String("task.id", id),
+		attribute.Int64("task.version", version),
+	))
+	restored, err := u.next.RestoreTask(ctx, id, version)
+	endSpan(span, err)
+	return restored, err
+}
+
+func (u *TracedTaskUsecase) PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.PurgeDeletedTasks", trace.WithAttributes(
+		attribute.String("trash.retention", retention.String()),
+	))
+	purged, err := u.next.PurgeDeletedTasks(ctx, retention)
+	span.SetAttributes(attribute.Int64("task.purged", purged))
+	endSpan(span, err)
+	return purged, err
+}
+
+func (u *TracedTaskUsecase) UpdateOverdueTasks(ctx context.Context) (int, error) {
+	ctx, span := u.tracer.Start(ctx, "TaskUsecase.UpdateOverdueTasks")
+	transitioned, err := u.next.UpdateOverdueTasks(ctx)
+	span.SetAttributes(attribute.Int("task.transitioned", transitioned))
+	endSpan(span, err)
+	return transitioned, err
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		var validationErr *validation.ValidationError
+		expected := errors.Is(err, repository.ErrTaskNotFound) ||
+			errors.Is(err, repository.ErrTaskExists) ||
+			errors.Is(err, repository.ErrVersionConflict) ||
+			errors.Is(err, usecase.ErrNothingToUndo) ||
+			errors.Is(err, usecase.ErrUndoExpired) ||
+			errors.Is(err, usecase.ErrSubtaskDepthExceeded) ||
+			errors.Is(err, usecase.ErrSubtasksOpen) ||
+			errors.Is(err, usecase.ErrTaskHasSubtasks) ||
+			errors.As(err, &validationErr)
+		if !expected {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
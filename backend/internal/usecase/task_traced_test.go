@@ -0,0 +1,69 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"todo/internal/domain/model"
+	"todo/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// TestTracedTaskUsecase_NestsRepositorySpans checks that repository spans are children of the usecase span
+func TestTracedTaskUsecase_NestsRepositorySpans(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	mock := newMockTaskRepo()
+	repo := repository.NewTracedTaskRepository(mock, tp, "")
+	uc := NewTracedTaskUsecase(NewTaskUsecase(repo, mock.events, repository.NewTracedUnitOfWork(mock, tp, ""), testSettings, discardLogger), tp)
+
+	// Act
+	_, err := uc.CreateTask(context.Background(), &model.Task{Title: "Traced"})
+
+	// Assert
+	require.NoError(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	assert.Equal(t, "TaskRepository.Create", spans[0].Name())
+	assert.Equal(t, "TaskEventRepository.Append", spans[1].Name())
+	assert.Equal(t, "UnitOfWork.WithTx", spans[2].Name())
+	assert.Equal(t, "TaskUsecase.CreateTask", spans[3].Name())
+	for _, span := range spans[:3] {
+		assert.Equal(t, spans[3].SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
+
+// TestTracedTaskUsecase_ErrorStatus checks that only unexpected errors mark the span as failed
+func TestTracedTaskUsecase_ErrorStatus(t *testing.T) {
+	// Arrange
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	repo := newMockTaskRepo()
+	uc := NewTracedTaskUsecase(NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger), tp)
+	ctx := context.Background()
+
+	// Act
+	_, notFoundErr := uc.GetTask(ctx, "missing")
+	_, _, validationErr := uc.ListTasksWithFilter(ctx, &model.TaskFilter{Page: 0, PageSize: 10})
+	repo.FindByIDFunc = func(id string) (*model.Task, error) { return nil, errors.New("connection reset") }
+	_, storageErr := uc.GetTask(ctx, "1")
+
+	// Assert
+	require.Error(t, notFoundErr)
+	require.Error(t, validationErr)
+	require.Error(t, storageErr)
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	assert.Equal(t, "connection reset", spans[2].Status().Description)
+}
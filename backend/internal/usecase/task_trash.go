@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/i18n"
+	"todo/internal/validation"
+)
+
+func (u *taskUsecase) GetDeletedTask(ctx context.Context, id string) (*model.Task, error) {
+	return u.repo.FindDeletedByID(ctx, id)
+}
+
+func (u *taskUsecase) ListDeletedTasks(ctx context.Context, page, pageSize int) ([]*model.Task, int, error) {
+	if page <= 0 {
+		return nil, 0, validation.NewFieldError("page", validation.CodeMin, i18n.PageTooSmall)
+	}
+	if pageSize <= 0 {
+		return nil, 0, validation.NewFieldError("page_size", validation.CodeMin, i18n.PageSizeTooSmall)
+	}
+	return u.repo.FindDeleted(ctx, (page-1)*pageSize, pageSize)
+}
+
+// RestoreTask brings the task back with the status it would have now: an open
+// task whose deadline passed while it was in the trash comes back overdue.
+// Completed tasks keep their status, which was decided when they were
+// completed.
+func (u *taskUsecase) RestoreTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	var restored *model.Task
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		task, err := repos.Tasks.FindDeletedByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != repository.AnyVersion && task.Version != version {
+			return repository.ErrVersionConflict
+		}
+		before := *task
+		now := time.Now().UTC()
+		task.UpdatedAt = &now
+		// A subtask whose parent is gone comes back as a top-level task.
+		if task.ParentID != nil {
+			if _, err := repos.Tasks.FindByID(ctx, *task.ParentID); errors.Is(err, repository.ErrTaskNotFound) {
+				task.ParentID = nil
+			} else if err != nil {
+				return err
+			}
+		}
+		if !task.IsCompleted {
+			task.Status = model.StatusActive
+			if task.Deadline != nil && now.After(*task.Deadline) {
+				task.Status = model.StatusOverdue
+			}
+		}
+		if err := repos.Tasks.Restore(ctx, task); err != nil {
+			return err
+		}
+		restored = task
+		return repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventRestored, model.OriginAPI, task, diffTask(&before, task), now))
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.logger.InfoContext(ctx, "task restored", slog.String("task_id", id), slog.String("status", string(restored.Status)))
+	return restored, nil
+}
+
+// PurgeDeletedTasks removes the history of the purged tasks with them.
+func (u *taskUsecase) PurgeDeletedTasks(ctx context.Context, retention time.Duration) (int64, error) {
+	before := time.Now().UTC().Add(-retention)
+	var purged []string
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		var err error
+		if purged, err = repos.Tasks.PurgeDeleted(ctx, before); err != nil {
+			return err
+		}
+		return repos.Events.DeleteByTaskIDs(ctx, purged)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(purged)), nil
+}
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+	"todo/internal/validation"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDeleteTask_MovesToTrash checks that a deleted task can still be found in the trash
+func TestDeleteTask_MovesToTrash(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "gone")
+
+	// Act
+	err := uc.DeleteTask(context.Background(), "gone", domainrepository.AnyVersion)
+
+	// Assert
+	require.NoError(t, err)
+	_, err = uc.GetTask(context.Background(), "gone")
+	assert.ErrorIs(t, err, domainrepository.ErrTaskNotFound)
+	deleted, err := uc.GetDeletedTask(context.Background(), "gone")
+	require.NoError(t, err)
+	assert.NotNil(t, deleted.DeletedAt)
+}
+
+// TestRestoreTask_RecalculatesStatus checks that an open task whose deadline passed in the trash
+// comes back overdue while a completed one keeps its status
+func TestRestoreTask_RecalculatesStatus(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	past := time.Now().UTC().Add(-time.Hour)
+	open := &model.Task{ID: "open", Title: "Open", Status: model.StatusActive, Priority: model.PriorityMedium, Deadline: &past}
+	done := &model.Task{ID: "done", Title: "Done", Status: model.StatusCompleted, Priority: model.PriorityMedium, Deadline: &past, IsCompleted: true}
+	for _, task := range []*model.Task{open, done} {
+		require.NoError(t, repo.Create(context.Background(), task))
+		require.NoError(t, repo.Delete(context.Background(), task.ID, domainrepository.AnyVersion))
+	}
+
+	// Act
+	restoredOpen, errOpen := uc.RestoreTask(context.Background(), "open", domainrepository.AnyVersion)
+	restoredDone, errDone := uc.RestoreTask(context.Background(), "done", 2)
+
+	// Assert
+	require.NoError(t, errOpen)
+	require.NoError(t, errDone)
+	assert.Equal(t, model.StatusOverdue, restoredOpen.Status)
+	assert.Equal(t, model.StatusCompleted, restoredDone.Status)
+	assert.Equal(t, int64(3), restoredOpen.Version)
+	stored, err := uc.GetTask(context.Background(), "open")
+	require.NoError(t, err)
+	assert.Equal(t, model.StatusOverdue, stored.Status)
+	assert.Nil(t, stored.DeletedAt)
+}
+
+// TestRestoreTask_StaleVersion checks that a restore based on an old version leaves the task in the trash
+func TestRestoreTask_StaleVersion(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "gone")
+	require.NoError(t, uc.DeleteTask(context.Background(), "gone", domainrepository.AnyVersion))
+
+	// Act
+	_, err := uc.RestoreTask(context.Background(), "gone", 1)
+
+	// Assert
+	assert.ErrorIs(t, err, domainrepository.ErrVersionConflict)
+	_, err = uc.GetDeletedTask(context.Background(), "gone")
+	assert.NoError(t, err)
+}
+
+// TestRestoreTask_NotInTrash checks that only deleted tasks can be restored
+func TestRestoreTask_NotInTrash(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "live")
+
+	// Act
+	_, err := uc.RestoreTask(context.Background(), "live", domainrepository.AnyVersion)
+
+	// Assert
+	assert.ErrorIs(t, err, domainrepository.ErrTaskNotFound)
+}
+
+// TestListDeletedTasks_Pagination checks that page and page size select a slice of the trash
+// and that invalid values are rejected
+func TestListDeletedTasks_Pagination(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "a", "b", "c")
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, uc.DeleteTask(context.Background(), id, domainrepository.AnyVersion))
+	}
+
+	// Act
+	tasks, total, err := uc.ListDeletedTasks(context.Background(), 2, 2)
+	_, _, pageErr := uc.ListDeletedTasks(context.Background(), 0, 2)
+	_, _, sizeErr := uc.ListDeletedTasks(context.Background(), 1, 0)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	assert.Len(t, tasks, 1)
+	var validationErr *validation.ValidationError
+	assert.ErrorAs(t, pageErr, &validationErr)
+	assert.ErrorAs(t, sizeErr, &validationErr)
+}
+
+// TestPurgeDeletedTasks_KeepsRecentlyDeleted checks that only tasks older than the retention are purged
+func TestPurgeDeletedTasks_KeepsRecentlyDeleted(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	seedTasks(t, repo, "gone")
+	require.NoError(t, uc.DeleteTask(context.Background(), "gone", domainrepository.AnyVersion))
+
+	// Act
+	kept, errKept := uc.PurgeDeletedTasks(context.Background(), time.Hour)
+	purged, errPurged := uc.PurgeDeletedTasks(context.Background(), -time.Hour)
+
+	// Assert
+	require.NoError(t, errKept)
+	require.NoError(t, errPurged)
+	assert.Zero(t, kept)
+	assert.Equal(t, int64(1), purged)
+}
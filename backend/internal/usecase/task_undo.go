@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"todo/internal/domain/model"
+	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+)
+
+func (u *taskUsecase) UndoTask(ctx context.Context, id string, version int64) (*model.Task, error) {
+	var task *model.Task
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		existing, err := repos.Tasks.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if version != repository.AnyVersion && existing.Version != version {
+			return repository.ErrVersionConflict
+		}
+		events, err := repos.Events.FindByTaskID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now().UTC()
+		last, err := lastUndoableEvent(events, existing, now, u.settings.UndoWindow)
+		if err != nil {
+			return err
+		}
+
+		task = new(model.Task)
+		*task = *existing
+		if err := revertChanges(task, last.Changes); err != nil {
+			return err
+		}
+		if err := u.checkCompletion(ctx, repos.Tasks, existing, task); err != nil {
+			return err
+		}
+		applyCompletion(task, now)
+		if err := repos.Tasks.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventUndone, model.OriginAPI, task, diffTask(existing, task), now)); err != nil {
+			return err
+		}
+		return attachProgress(ctx, repos.Tasks, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	u.logger.InfoContext(ctx, "task change undone", slog.String("task_id", task.ID), slog.String("status", string(task.Status)))
+	return task, nil
+}
+
+// lastUndoableEvent returns the last change a client made to task, provided
+// it is an update or a completion change made within window and nothing has
+// changed the task since.
+func lastUndoableEvent(events []*model.TaskEvent, task *model.Task, now time.Time, window time.Duration) (*model.TaskEvent, error) {
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Origin == model.OriginCron {
+			continue
+		}
+		// A scheduled change after it, or a write without a history entry,
+		// would be lost by reverting.
+		if i != len(events)-1 || event.Version != task.Version {
+			return nil, repository.ErrVersionConflict
+		}
+		if event.Type != model.TaskEventUpdated && event.Type != model.TaskEventStatusChanged {
+			return nil, usecase.ErrNothingToUndo
+		}
+		if now.Sub(event.CreatedAt) > window {
+			return nil, usecase.ErrUndoExpired
+		}
+		return event, nil
+	}
+	return nil, usecase.ErrNothingToUndo
+}
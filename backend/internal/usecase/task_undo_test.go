@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createAndEdit creates a task through uc and updates it once with edit.
+func createAndEdit(t *testing.T, uc *taskUsecase, edit func(task *model.Task)) *model.Task {
+	t.Helper()
+	created, err := uc.CreateTask(context.Background(), &model.Task{Title: "Original title"})
+	require.NoError(t, err)
+	edited := *created
+	edit(&edited)
+	updated, err := uc.UpdateTask(context.Background(), &edited)
+	require.NoError(t, err)
+	return updated
+}
+
+// TestUndoTask_RevertsUpdate checks that the fields of the last update get their old values back
+// and that the undo is recorded
+func TestUndoTask_RevertsUpdate(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	updated := createAndEdit(t, uc, func(task *model.Task) {
+		description := "Added later"
+		task.Title = "Edited title"
+		task.Description = &description
+		task.Priority = model.PriorityHigh
+	})
+
+	// Act
+	undone, err := uc.UndoTask(context.Background(), updated.ID, 2)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Original title", undone.Title)
+	assert.Nil(t, undone.Description)
+	assert.Equal(t, model.PriorityMedium, undone.Priority)
+	assert.Equal(t, int64(3), undone.Version)
+	stored, err := uc.GetTask(context.Background(), updated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Original title", stored.Title)
+	events, err := uc.GetTaskHistory(context.Background(), updated.ID)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, model.TaskEventUndone, events[2].Type)
+	assert.Equal(t, []string{"title", "description", "priority"}, changedFields(events[2].Changes))
+}
+
+// TestUndoTask_RecalculatesStatus checks that undoing a completion recalculates the status instead
+// of restoring the old one
+func TestUndoTask_RecalculatesStatus(t *testing.T) {
+	// Arrange
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	past := time.Now().UTC().Add(-time.Hour)
+	task := &model.Task{ID: "late", Title: "Late task", Deadline: &past, Status: model.StatusActive, Priority: model.PriorityMedium}
+	require.NoError(t, repo.Create(context.Background(), task))
+	task.IsCompleted = true
+	completed, err := uc.SetTaskCompletion(context.Background(), task)
+	require.NoError(t, err)
+	require.Equal(t, model.StatusLate, completed.Status)
+
+	// Act
+	undone, err := uc.UndoTask(context.Background(), "late", domainrepository.AnyVersion)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, undone.IsCompleted)
+	assert.Equal(t, model.StatusOverdue, undone.Status)
+}
+
+// TestUndoTask_Refused checks the reasons an undo is refused
+func TestUndoTask_Refused(t *testing.T) {
+	t.Run("only once", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		updated := createAndEdit(t, uc, func(task *model.Task) { task.Title = "Edited title" })
+		_, err := uc.UndoTask(context.Background(), updated.ID, domainrepository.AnyVersion)
+		require.NoError(t, err)
+
+		_, err = uc.UndoTask(context.Background(), updated.ID, domainrepository.AnyVersion)
+
+		assert.ErrorIs(t, err, usecase.ErrNothingToUndo)
+	})
+
+	t.Run("nothing but the creation", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		created, err := uc.CreateTask(context.Background(), &model.Task{Title: "New task"})
+		require.NoError(t, err)
+
+		_, err = uc.UndoTask(context.Background(), created.ID, domainrepository.AnyVersion)
+
+		assert.ErrorIs(t, err, usecase.ErrNothingToUndo)
+	})
+
+	t.Run("outside the window", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		settings := testSettings
+		settings.UndoWindow = time.Nanosecond
+		uc := NewTaskUsecase(repo, repo.events, repo, settings, discardLogger)
+		updated := createAndEdit(t, uc, func(task *model.Task) { task.Title = "Edited title" })
+		time.Sleep(time.Millisecond)
+
+		_, err := uc.UndoTask(context.Background(), updated.ID, domainrepository.AnyVersion)
+
+		assert.ErrorIs(t, err, usecase.ErrUndoExpired)
+	})
+
+	t.Run("stale version", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		updated := createAndEdit(t, uc, func(task *model.Task) { task.Title = "Edited title" })
+
+		_, err := uc.UndoTask(context.Background(), updated.ID, 1)
+
+		assert.ErrorIs(t, err, domainrepository.ErrVersionConflict)
+	})
+
+	t.Run("overdue job ran since", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+		soon := time.Now().UTC().Add(50 * time.Millisecond)
+		updated := createAndEdit(t, uc, func(task *model.Task) { task.Deadline = &soon })
+		time.Sleep(100 * time.Millisecond)
+		transitioned, err := uc.UpdateOverdueTasks(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, 1, transitioned)
+
+		_, err = uc.UndoTask(context.Background(), updated.ID, domainrepository.AnyVersion)
+
+		assert.ErrorIs(t, err, domainrepository.ErrVersionConflict)
+	})
+
+	t.Run("unknown task", func(t *testing.T) {
+		repo := newMockTaskRepo()
+		uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+		_, err := uc.UndoTask(context.Background(), "missing", domainrepository.AnyVersion)
+
+		assert.ErrorIs(t, err, domainrepository.ErrTaskNotFound)
+	})
+}
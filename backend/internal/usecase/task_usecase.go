@@ -1,40 +1,80 @@
 package usecase
 
 import (
-	"log"
-	"sort"
+	"context"
+	"errors"
+	"log/slog"
 	"strings"
 	"time"
 
 	"todo/internal/domain/model"
 	"todo/internal/domain/repository"
+	"todo/internal/domain/usecase"
+	"todo/internal/i18n"
 	"todo/internal/validation"
 
 	"github.com/google/uuid"
 )
 
+// TaskSettings are the configurable rules of the usecase.
+type TaskSettings struct {
+	// UndoWindow is how long after a change the client may still undo it.
+	UndoWindow time.Duration
+	// MaxSubtaskDepth is how many levels of subtasks a top-level task may
+	// have.
+	MaxSubtaskDepth int
+	// CompletionRule decides whether a task may be completed while some of
+	// its subtasks are open.
+	CompletionRule model.CompletionRule
+}
+
 type taskUsecase struct {
-	repo repository.TaskRepository
+	repo     repository.TaskRepository
+	events   repository.TaskEventRepository
+	uow      repository.UnitOfWork
+	settings TaskSettings
+	logger   *slog.Logger
 }
 
-func NewTaskUsecase(repo repository.TaskRepository) *taskUsecase {
-	return &taskUsecase{repo: repo}
+// NewTaskUsecase builds the usecase. Reads go to repo and events; every write
+// runs as a transaction of uow that also appends the change to the task's
+// history.
+func NewTaskUsecase(repo repository.TaskRepository, events repository.TaskEventRepository, uow repository.UnitOfWork, settings TaskSettings, logger *slog.Logger) *taskUsecase {
+	return &taskUsecase{repo: repo, events: events, uow: uow, settings: settings, logger: logger}
 }
 
-func (u *taskUsecase) CreateTask(task *model.Task) (*model.Task, error) {
-	now := time.Now().UTC()
-	task.ID = uuid.New().String()
+func (u *taskUsecase) CreateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	origin, err := prepareNewTask(task, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
 
-	// --- Macro parsing ---
-	macros := validation.ParseTaskMacros(task.Title)
-	task.Title = macros.Title
-	if task.Priority == "" && macros.Priority != nil {
-		task.Priority = *macros.Priority
+	err = u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		if err := repos.Tasks.Create(ctx, task); err != nil {
+			return err
+		}
+		return repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventCreated, origin, task, diffTask(nil, task), task.CreatedAt))
+	})
+	if err != nil {
+		return nil, err
 	}
-	if task.Deadline == nil && macros.Deadline != nil {
-		task.Deadline = macros.Deadline
+	u.logger.InfoContext(ctx, "task created", slog.String("task_id", task.ID))
+
+	return task, nil
+}
+
+// prepareNewTask fills in what a new task derives from the client's input:
+// its ID, the title macros and the default status and priority. It returns
+// the origin of the task's values and the validation error, if any.
+func prepareNewTask(task *model.Task, now time.Time) (model.TaskEventOrigin, error) {
+	// Clients that create tasks offline choose the ID themselves.
+	if task.ID == "" {
+		task.ID = uuid.New().String()
+	} else if err := validation.ValidateTaskID(task.ID); err != nil {
+		return "", err
 	}
-	// --- Macro parsing ---
+
+	origin := applyTitleMacros(task)
 
 	if task.Status == "" {
 		task.Status = model.StatusActive
@@ -44,42 +84,86 @@ func (u *taskUsecase) CreateTask(task *model.Task) (*model.Task, error) {
 	}
 	task.CreatedAt = now
 
-	if err := validation.ValidateTask(task); err != nil {
-		return nil, err
-	}
-
-	if err := u.repo.Create(task); err != nil {
-		return nil, err
-	}
-
-	return task, nil
+	return origin, validation.ValidateTask(task)
 }
 
-func (u *taskUsecase) UpdateTask(task *model.Task) (*model.Task, error) {
-	existing, err := u.repo.FindByID(task.ID)
-	if err != nil {
-		return nil, err
-	}
-	if existing == nil {
-		return nil, repository.ErrTaskNotFound
-	}
-
-	// --- Macro parsing ---
+// applyTitleMacros strips the macros from the title and uses them for the
+// priority and deadline the client left empty. It returns OriginMacro if a
+// macro set a field.
+func applyTitleMacros(task *model.Task) model.TaskEventOrigin {
+	origin := model.OriginAPI
 	macros := validation.ParseTaskMacros(task.Title)
 	task.Title = macros.Title
 	if task.Priority == "" && macros.Priority != nil {
 		task.Priority = *macros.Priority
+		origin = model.OriginMacro
 	}
 	if task.Deadline == nil && macros.Deadline != nil {
 		task.Deadline = macros.Deadline
+		origin = model.OriginMacro
 	}
-	// --- Macro parsing ---
+	return origin
+}
 
-	if err := validation.ValidateTask(task); err != nil {
+func (u *taskUsecase) UpdateTask(ctx context.Context, task *model.Task) (*model.Task, error) {
+	input := *task
+	var invalid error
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		// A retried transaction starts over from what the client sent.
+		*task = input
+		existing, err := repos.Tasks.FindByID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return repository.ErrTaskNotFound
+		}
+		if existing.Version != task.Version {
+			return repository.ErrVersionConflict
+		}
+		// A task stays where it was created.
+		task.ParentID = existing.ParentID
+
+		// An invalid task is the client's error, not a failed transaction.
+		now := time.Now().UTC()
+		origin, err := prepareTaskUpdate(task, now)
+		if err != nil {
+			invalid = err
+			return repository.ErrRollback
+		}
+		if err := u.checkCompletion(ctx, repos.Tasks, existing, task); err != nil {
+			invalid = err
+			return repository.ErrRollback
+		}
+
+		if err := repos.Tasks.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventUpdated, origin, task, diffTask(existing, task), now)); err != nil {
+			return err
+		}
+		return attachProgress(ctx, repos.Tasks, task)
+	})
+	if err == nil {
+		err = invalid
+	}
+	if err != nil {
 		return nil, err
 	}
+	u.logger.InfoContext(ctx, "task updated", slog.String("task_id", task.ID), slog.String("status", string(task.Status)))
+
+	return task, nil
+}
+
+// prepareTaskUpdate parses the title macros, validates the edited task and
+// recalculates its status. It returns the origin of the new values.
+func prepareTaskUpdate(task *model.Task, now time.Time) (model.TaskEventOrigin, error) {
+	origin := applyTitleMacros(task)
+
+	if err := validation.ValidateTask(task); err != nil {
+		return "", err
+	}
 
-	now := time.Now().UTC()
 	task.UpdatedAt = &now
 
 	if task.Priority == "" {
@@ -102,60 +186,119 @@ func (u *taskUsecase) UpdateTask(task *model.Task) (*model.Task, error) {
 	}
 	// --- Status calculating ---
 
-	if err := u.repo.Update(task); err != nil {
-		return nil, err
-	}
-
-	return task, nil
+	return origin, nil
 }
 
-func (u *taskUsecase) DeleteTask(id string) error {
-	existing, err := u.repo.FindByID(id)
+func (u *taskUsecase) DeleteTask(ctx context.Context, id string, version int64) error {
+	var refused error
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		existing, err := repos.Tasks.FindByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if existing == nil {
+			return repository.ErrTaskNotFound
+		}
+		// A subtask in the trash could outlive its parent, so the subtasks
+		// have to go first.
+		progress, err := repos.Tasks.CountSubtasks(ctx, []string{id})
+		if err != nil {
+			return err
+		}
+		if progress[id].Total > 0 {
+			refused = usecase.ErrTaskHasSubtasks
+			return repository.ErrRollback
+		}
+		if err := repos.Tasks.Delete(ctx, id, version); err != nil {
+			return err
+		}
+		// Moving a task to the trash bumps its version.
+		existing.Version++
+		return repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventDeleted, model.OriginAPI, existing, nil, time.Now().UTC()))
+	})
+	if err == nil {
+		err = refused
+	}
 	if err != nil {
 		return err
 	}
-	if existing == nil {
-		return repository.ErrTaskNotFound
-	}
-
-	return u.repo.Delete(id)
+	u.logger.InfoContext(ctx, "task moved to trash", slog.String("task_id", id))
+	return nil
 }
 
-func (u *taskUsecase) GetTask(id string) (*model.Task, error) {
-	task, err := u.repo.FindByID(id)
+func (u *taskUsecase) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	task, err := u.repo.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if task == nil {
 		return nil, repository.ErrTaskNotFound
 	}
+	if err := attachProgress(ctx, u.repo, task); err != nil {
+		return nil, err
+	}
 	return task, nil
 }
 
-func (u *taskUsecase) ListTasksWithFilter(filter *model.TaskFilter) ([]*model.Task, int, error) {
+func (u *taskUsecase) ListTasksWithFilter(ctx context.Context, filter *model.TaskFilter) ([]*model.Task, int, error) {
 	if filter.Page <= 0 {
-		return nil, 0, validation.NewValidationError("page must be greater than 0")
+		return nil, 0, validation.NewFieldError("page", validation.CodeMin, i18n.PageTooSmall)
 	}
 	if filter.PageSize <= 0 {
-		return nil, 0, validation.NewValidationError("page_size must be greater than 0")
+		return nil, 0, validation.NewFieldError("page_size", validation.CodeMin, i18n.PageSizeTooSmall)
+	}
+
+	if err := normalizeTaskSort(filter); err != nil {
+		return nil, 0, err
 	}
 
-	tasks, err := u.repo.FindAll()
+	tasks, total, err := u.repo.FindWithFilter(ctx, filter)
 	if err != nil {
 		return nil, 0, err
 	}
+	if err := attachProgress(ctx, u.repo, tasks...); err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (u *taskUsecase) ListTasksByCursor(ctx context.Context, filter *model.TaskFilter, cursor string) ([]*model.Task, string, error) {
+	if filter.PageSize <= 0 {
+		return nil, "", validation.NewFieldError("page_size", validation.CodeMin, i18n.PageSizeTooSmall)
+	}
+	if err := normalizeTaskSort(filter); err != nil {
+		return nil, "", err
+	}
 
-	filtered := make([]*model.Task, 0)
-	for _, t := range tasks {
-		if filter.Status != "" && string(t.Status) != filter.Status {
-			continue
+	var after *model.TaskCursor
+	if cursor != "" {
+		decoded, err := decodeTaskCursor(filter, cursor)
+		if errors.Is(err, errCursorSortMismatch) {
+			return nil, "", validation.NewFieldError("cursor", validation.CodeInvalid, i18n.CursorSortMismatch)
 		}
-		if filter.Priority != "" && string(t.Priority) != filter.Priority {
-			continue
+		if err != nil {
+			return nil, "", validation.NewFieldError("cursor", validation.CodeInvalid, i18n.CursorInvalid)
 		}
-		filtered = append(filtered, t)
+		after = decoded
+	}
+
+	// One extra row tells whether another page exists without a COUNT query.
+	tasks, err := u.repo.FindAfter(ctx, filter, after, filter.PageSize+1)
+	if err != nil {
+		return nil, "", err
 	}
+	next := ""
+	if len(tasks) > filter.PageSize {
+		tasks = tasks[:filter.PageSize]
+		next = encodeTaskCursor(filter, tasks[len(tasks)-1])
+	}
+	if err := attachProgress(ctx, u.repo, tasks...); err != nil {
+		return nil, "", err
+	}
+	return tasks, next, nil
+}
 
+func normalizeTaskSort(filter *model.TaskFilter) error {
 	allowedSortFields := map[string]bool{
 		"deadline":   true,
 		"created_at": true,
@@ -167,65 +310,53 @@ func (u *taskUsecase) ListTasksWithFilter(filter *model.TaskFilter) ([]*model.Ta
 		"":     true,
 	}
 
-	var priorityOrder = map[model.TaskPriority]int{
-		model.PriorityCritical: 4,
-		model.PriorityHigh:     3,
-		model.PriorityMedium:   2,
-		model.PriorityLow:      1,
+	if filter.SortBy != "" && !allowedSortFields[filter.SortBy] {
+		return validation.NewFieldError("sort_by", validation.CodeOneOf, i18n.SortByInvalid)
 	}
-
-	sortBy := filter.SortBy
 	sortOrder := strings.ToLower(filter.SortOrder)
+	if !allowedSortOrders[sortOrder] {
+		return validation.NewFieldError("sort_order", validation.CodeOneOf, i18n.SortOrderInvalid)
+	}
+	filter.SortOrder = sortOrder
+	return nil
+}
 
-	if sortBy != "" && !allowedSortFields[sortBy] {
-		return nil, 0, validation.NewValidationError("invalid sort_by field")
+func (u *taskUsecase) SetTaskCompletion(ctx context.Context, task *model.Task) (*model.Task, error) {
+	input := *task
+	var refused error
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		*task = input
+		existing, err := repos.Tasks.FindByID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		task.ParentID = existing.ParentID
+		if err := u.checkCompletion(ctx, repos.Tasks, existing, task); err != nil {
+			refused = err
+			return repository.ErrRollback
+		}
+		now := time.Now().UTC()
+		applyCompletion(task, now)
+		if err := repos.Tasks.Update(ctx, task); err != nil {
+			return err
+		}
+		if err := repos.Events.Append(ctx, newTaskEvent(ctx, model.TaskEventStatusChanged, model.OriginAPI, task, diffTask(existing, task), now)); err != nil {
+			return err
+		}
+		return attachProgress(ctx, repos.Tasks, task)
+	})
+	if err == nil {
+		err = refused
 	}
-	if !allowedSortOrders[sortOrder] {
-		return nil, 0, validation.NewValidationError("invalid sort_order value")
-	}
-
-	if allowedSortFields[sortBy] {
-		desc := sortOrder == "desc"
-		sort.Slice(filtered, func(i, j int) bool {
-			var less bool
-			switch sortBy {
-			case "deadline":
-				if filtered[i].Deadline == nil || filtered[j].Deadline == nil {
-					less = filtered[i].Deadline != nil
-				} else {
-					less = filtered[i].Deadline.Before(*filtered[j].Deadline)
-				}
-			case "created_at":
-				less = filtered[i].CreatedAt.Before(filtered[j].CreatedAt)
-			case "priority":
-				pi := priorityOrder[filtered[i].Priority]
-				pj := priorityOrder[filtered[j].Priority]
-				less = pi < pj
-			}
-			if desc {
-				return !less
-			}
-			return less
-		})
-	}
-
-	total := len(filtered)
-
-	start := (filter.Page - 1) * filter.PageSize
-	if start > total {
-		start = total
-	}
-	end := start + filter.PageSize
-	if filter.PageSize <= 0 || end > total {
-		end = total
-	}
-	paged := filtered[start:end]
-
-	return paged, total, nil
+	if err != nil {
+		return nil, err
+	}
+	u.logger.InfoContext(ctx, "task completion changed", slog.String("task_id", task.ID), slog.String("status", string(task.Status)))
+	return task, nil
 }
 
-func (u *taskUsecase) SetTaskCompletion(task *model.Task) (*model.Task, error) {
-	now := time.Now().UTC()
+// applyCompletion stamps a change of IsCompleted and recalculates the status.
+func applyCompletion(task *model.Task, now time.Time) {
 	task.UpdatedAt = &now
 
 	if task.IsCompleted {
@@ -241,29 +372,32 @@ func (u *taskUsecase) SetTaskCompletion(task *model.Task) (*model.Task, error) {
 			task.Status = model.StatusActive
 		}
 	}
-
-	if err := u.repo.Update(task); err != nil {
-		return nil, err
-	}
-	return task, nil
 }
 
-func (u *taskUsecase) UpdateOverdueTasks() error {
-	tasks, err := u.repo.FindAll()
-	if err != nil {
-		return err
-	}
+// UpdateOverdueTasks moves the tasks with one set-based update and records
+// the transitions as made by the scheduler, in the same transaction.
+func (u *taskUsecase) UpdateOverdueTasks(ctx context.Context) (int, error) {
 	now := time.Now().UTC()
-	for _, task := range tasks {
-		if !task.IsCompleted && task.Deadline != nil && now.After(*task.Deadline) && task.Status == model.StatusActive {
-			task.Status = model.StatusOverdue
-			task.UpdatedAt = &now
-			if err := u.repo.Update(task); err != nil {
-				log.Printf("[CRON] Failed to update task %s to Overdue: %v", task.ID, err)
-			} else {
-				log.Printf("[CRON] Task %s marked as Overdue", task.ID)
-			}
+	var overdue []*model.Task
+	err := u.uow.WithTx(ctx, func(repos repository.Repositories) error {
+		var err error
+		if overdue, err = repos.Tasks.MarkOverdue(ctx, now); err != nil {
+			return err
 		}
+		events := make([]*model.TaskEvent, len(overdue))
+		for i, task := range overdue {
+			before := *task
+			before.Status = model.StatusActive
+			events[i] = newTaskEvent(ctx, model.TaskEventStatusChanged, model.OriginCron, task, diffTask(&before, task), now)
+		}
+		return repos.Events.Append(ctx, events...)
+	})
+	if err != nil {
+		return 0, err
 	}
-	return nil
+
+	for _, task := range overdue {
+		u.logger.InfoContext(ctx, "task marked overdue", slog.String("task_id", task.ID))
+	}
+	return len(overdue), nil
 }
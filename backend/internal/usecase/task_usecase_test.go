@@ -1,68 +1,62 @@
 package usecase
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/stretchr/testify/assert"
+	"log/slog"
 	"strings"
 	"testing"
 	"time"
 	"todo/internal/domain/model"
+	domainrepository "todo/internal/domain/repository"
+	"todo/internal/repository"
 )
 
+var discardLogger = slog.New(slog.DiscardHandler)
+
+// testSettings are the rules of usecases under test.
+var testSettings = TaskSettings{
+	UndoWindow:      time.Minute,
+	MaxSubtaskDepth: 2,
+	CompletionRule:  model.CompletionIndependent,
+}
+
 // --- Mock Repo ---
 
+// mockTaskRepo is the in-memory repository with an optional FindByID override
+// for injecting storage errors. It is its own unit of work and keeps task
+// histories in events.
 type mockTaskRepo struct {
-	tasks map[string]*model.Task
+	*repository.TaskMemoryRepository
 
+	events       *repository.TaskEventMemoryRepository
 	FindByIDFunc func(id string) (*model.Task, error)
 }
 
 func newMockTaskRepo() *mockTaskRepo {
-	return &mockTaskRepo{tasks: make(map[string]*model.Task)}
-}
-
-func (m *mockTaskRepo) Create(task *model.Task) error {
-	if _, exists := m.tasks[task.ID]; exists {
-		return errors.New("already exists")
-	}
-	m.tasks[task.ID] = task
-	return nil
-}
-
-func (m *mockTaskRepo) Update(task *model.Task) error {
-	if _, exists := m.tasks[task.ID]; !exists {
-		return errors.New("not found")
+	return &mockTaskRepo{
+		TaskMemoryRepository: repository.NewTaskMemoryRepository(),
+		events:               repository.NewTaskEventMemoryRepository(),
 	}
-	m.tasks[task.ID] = task
-	return nil
 }
 
-func (m *mockTaskRepo) Delete(id string) error {
-	if _, exists := m.tasks[id]; !exists {
-		return errors.New("not found")
-	}
-	delete(m.tasks, id)
-	return nil
-}
-
-func (m *mockTaskRepo) FindByID(id string) (*model.Task, error) {
+func (m *mockTaskRepo) FindByID(ctx context.Context, id string) (*model.Task, error) {
 	if m.FindByIDFunc != nil {
 		return m.FindByIDFunc(id)
 	}
-	task, exists := m.tasks[id]
-	if !exists {
-		return nil, nil
-	}
-	return task, nil
+	return m.TaskMemoryRepository.FindByID(ctx, id)
 }
 
-func (m *mockTaskRepo) FindAll() ([]*model.Task, error) {
-	var result []*model.Task
-	for _, t := range m.tasks {
-		result = append(result, t)
-	}
-	return result, nil
+// WithTx runs fn in a transaction of the memory repositories and keeps the
+// FindByID override inside it.
+func (m *mockTaskRepo) WithTx(ctx context.Context, fn func(repos domainrepository.Repositories) error) error {
+	return repository.NewMemoryUnitOfWork(m.TaskMemoryRepository, m.events).WithTx(ctx, func(repos domainrepository.Repositories) error {
+		tasks := repos.Tasks.(*repository.TaskMemoryRepository)
+		repos.Tasks = &mockTaskRepo{TaskMemoryRepository: tasks, events: m.events, FindByIDFunc: m.FindByIDFunc}
+		return fn(repos)
+	})
 }
 
 // --- Tests ---
@@ -71,7 +65,7 @@ func (m *mockTaskRepo) FindAll() ([]*model.Task, error) {
 // macros are parsed, fields are filled, status and priority are set as expected.
 func TestCreateTask_SetsFieldsAndSaves(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a task with macros in the title
 	task := &model.Task{
@@ -79,7 +73,7 @@ func TestCreateTask_SetsFieldsAndSaves(t *testing.T) {
 	}
 
 	// Act: call CreateTask
-	created, err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
@@ -92,11 +86,28 @@ func TestCreateTask_SetsFieldsAndSaves(t *testing.T) {
 	assert.WithinDuration(t, time.Now().UTC(), created.CreatedAt, time.Second*2)
 }
 
+// TestCreateTask_ClientID checks that a client-chosen UUID v4 or v7 is kept and a taken one
+// is reported as ErrTaskExists
+func TestCreateTask_ClientID(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	const id = "0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60"
+
+	// Act
+	created, err := uc.CreateTask(context.Background(), &model.Task{ID: id, Title: "Offline task"})
+	_, dupErr := uc.CreateTask(context.Background(), &model.Task{ID: id, Title: "Another task"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, id, created.ID)
+	assert.ErrorIs(t, dupErr, domainrepository.ErrTaskExists)
+}
+
 // TestUpdateTask_ChangesDeadlineAndRecalculatesStatus checks that when the deadline is changed,
 // the task status is recalculated accordingly.
 func TestUpdateTask_ChangesDeadlineAndRecalculatesStatus(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a task with a past deadline directly in the repo
 	past := time.Now().Add(-24 * time.Hour)
@@ -108,14 +119,14 @@ func TestUpdateTask_ChangesDeadlineAndRecalculatesStatus(t *testing.T) {
 		Priority:  model.PriorityMedium,
 		CreatedAt: time.Now().Add(-48 * time.Hour),
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act: change the deadline to the future
 	future := time.Now().Add(24 * time.Hour)
 	task.Deadline = &future
 	task.IsCompleted = false
 
-	updated, err := uc.UpdateTask(task)
+	updated, err := uc.UpdateTask(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
@@ -126,7 +137,7 @@ func TestUpdateTask_ChangesDeadlineAndRecalculatesStatus(t *testing.T) {
 // TestSetTaskCompletion_CompletedBeforeDeadline checks that a task becomes COMPLETED if finished before the deadline.
 func TestSetTaskCompletion_CompletedBeforeDeadline(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with a future deadline
 	future := time.Now().Add(24 * time.Hour)
@@ -139,11 +150,11 @@ func TestSetTaskCompletion_CompletedBeforeDeadline(t *testing.T) {
 		CreatedAt:   time.Now(),
 		IsCompleted: false,
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act: mark the task as completed
 	task.IsCompleted = true
-	updated, err := uc.SetTaskCompletion(task)
+	updated, err := uc.SetTaskCompletion(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
@@ -153,7 +164,7 @@ func TestSetTaskCompletion_CompletedBeforeDeadline(t *testing.T) {
 // TestSetTaskCompletion_CompletedAfterDeadline checks that a task becomes LATE if finished after the deadline.
 func TestSetTaskCompletion_CompletedAfterDeadline(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with a past deadline
 	past := time.Now().Add(-24 * time.Hour)
@@ -166,11 +177,11 @@ func TestSetTaskCompletion_CompletedAfterDeadline(t *testing.T) {
 		CreatedAt:   time.Now(),
 		IsCompleted: false,
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act: mark the task as completed
 	task.IsCompleted = true
-	updated, err := uc.SetTaskCompletion(task)
+	updated, err := uc.SetTaskCompletion(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
@@ -180,7 +191,7 @@ func TestSetTaskCompletion_CompletedAfterDeadline(t *testing.T) {
 // TestListTasksWithFilter_PaginationAndSorting checks filtering, sorting, and pagination logic.
 func TestListTasksWithFilter_PaginationAndSorting(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create 5 tasks with different creation times
 	now := time.Now()
@@ -192,7 +203,7 @@ func TestListTasksWithFilter_PaginationAndSorting(t *testing.T) {
 			Priority:  model.TaskPriority("MEDIUM"),
 			CreatedAt: now.Add(time.Duration(i) * time.Minute),
 		}
-		_ = repo.Create(task)
+		_ = repo.Create(context.Background(), task)
 	}
 
 	// Act: request the first page with 2 tasks, sorted by created_at descending
@@ -202,7 +213,7 @@ func TestListTasksWithFilter_PaginationAndSorting(t *testing.T) {
 		SortBy:    "created_at",
 		SortOrder: "desc",
 	}
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.NoError(t, err)
@@ -214,7 +225,7 @@ func TestListTasksWithFilter_PaginationAndSorting(t *testing.T) {
 // TestUpdateTask_RepoError checks that an error from the repository update is returned.
 func TestUpdateTask_RepoError(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a valid task
 	task := &model.Task{
@@ -224,12 +235,12 @@ func TestUpdateTask_RepoError(t *testing.T) {
 		Priority:  model.PriorityMedium,
 		CreatedAt: time.Now(),
 	}
-	_ = repo.Create(task)
-	_ = repo.Delete("update") // Simulate repo error by deleting the task before update
+	_ = repo.Create(context.Background(), task)
+	_ = repo.Delete(context.Background(), "update", domainrepository.AnyVersion) // Simulate repo error by deleting the task before update
 
 	// Act
 	task.Title = "Updated title"
-	updated, err := uc.UpdateTask(task)
+	updated, err := uc.UpdateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, updated)
@@ -239,29 +250,74 @@ func TestUpdateTask_RepoError(t *testing.T) {
 // TestDeleteTask_Success checks that deleting an existing task works.
 func TestDeleteTask_Success(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a task to delete
 	task := &model.Task{
 		ID:    "del",
 		Title: "To delete",
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act
-	err := uc.DeleteTask("del")
+	err := uc.DeleteTask(context.Background(), "del", domainrepository.AnyVersion)
 
 	// Assert
 	assert.NoError(t, err)
 }
 
+// TestUpdateTask_StaleVersion checks that an update based on an old version is rejected
+// without touching the stored task.
+func TestUpdateTask_StaleVersion(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange: two readers see version 1, the first one writes
+	_ = repo.Create(context.Background(), &model.Task{ID: "stale", Title: "Original", Status: model.StatusActive, Priority: model.PriorityMedium})
+	first, _ := uc.GetTask(context.Background(), "stale")
+	second, _ := uc.GetTask(context.Background(), "stale")
+	first.Title = "First writer"
+	_, err := uc.UpdateTask(context.Background(), first)
+	assert.NoError(t, err)
+
+	// Act
+	second.Title = "Second writer"
+	updated, err := uc.UpdateTask(context.Background(), second)
+
+	// Assert
+	assert.Nil(t, updated)
+	assert.ErrorIs(t, err, domainrepository.ErrVersionConflict)
+	stored, _ := uc.GetTask(context.Background(), "stale")
+	assert.Equal(t, "First writer", stored.Title)
+	assert.Equal(t, int64(2), stored.Version)
+}
+
+// TestDeleteTask_StaleVersion checks that a delete based on an old version keeps the task.
+func TestDeleteTask_StaleVersion(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange
+	task := &model.Task{ID: "kept", Title: "Keep me", Status: model.StatusActive, Priority: model.PriorityMedium}
+	_ = repo.Create(context.Background(), task)
+	_ = repo.Update(context.Background(), task)
+
+	// Act
+	err := uc.DeleteTask(context.Background(), "kept", 1)
+
+	// Assert
+	assert.ErrorIs(t, err, domainrepository.ErrVersionConflict)
+	_, findErr := uc.GetTask(context.Background(), "kept")
+	assert.NoError(t, findErr)
+}
+
 // TestDeleteTask_RepoError checks that an error from the repository delete is returned.
 func TestDeleteTask_RepoError(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Act: try to delete a non-existent task
-	err := uc.DeleteTask("not-exist")
+	err := uc.DeleteTask(context.Background(), "not-exist", domainrepository.AnyVersion)
 
 	// Assert
 	assert.Error(t, err)
@@ -270,17 +326,17 @@ func TestDeleteTask_RepoError(t *testing.T) {
 // TestGetTask_Success checks that getting an existing task works.
 func TestGetTask_Success(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a task to get
 	task := &model.Task{
 		ID:    "get",
 		Title: "To get",
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act
-	got, err := uc.GetTask("get")
+	got, err := uc.GetTask(context.Background(), "get")
 
 	// Assert
 	assert.NoError(t, err)
@@ -291,10 +347,10 @@ func TestGetTask_Success(t *testing.T) {
 // TestGetTask_NotFound checks that getting a non-existent task returns ErrTaskNotFound.
 func TestGetTask_NotFound(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Act: try to get a non-existent task
-	task, err := uc.GetTask("not-exist")
+	task, err := uc.GetTask(context.Background(), "not-exist")
 
 	// Assert
 	assert.Nil(t, task)
@@ -304,14 +360,14 @@ func TestGetTask_NotFound(t *testing.T) {
 // TestListTasksWithFilter_EmptyList checks that filtering on an empty repo returns an empty list.
 func TestListTasksWithFilter_EmptyList(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Act: filter on an empty repo
 	filter := &model.TaskFilter{
 		Page:     1,
 		PageSize: 10,
 	}
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.NoError(t, err)
@@ -322,21 +378,21 @@ func TestListTasksWithFilter_EmptyList(t *testing.T) {
 // TestListTasksWithFilter_PaginationEdgeCase checks pagination when offset is out of range.
 func TestListTasksWithFilter_PaginationEdgeCase(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: add one task
 	task := &model.Task{
 		ID:    "t1",
 		Title: "Task 1",
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act: request page 2 with page size 10 (should be empty)
 	filter := &model.TaskFilter{
 		Page:     2,
 		PageSize: 10,
 	}
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.NoError(t, err)
@@ -347,7 +403,7 @@ func TestListTasksWithFilter_PaginationEdgeCase(t *testing.T) {
 // TestSetTaskCompletion_RepoError checks that an error from the repository update is returned.
 func TestSetTaskCompletion_RepoError(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task not added to repo, so update will fail
 	task := &model.Task{
@@ -357,7 +413,7 @@ func TestSetTaskCompletion_RepoError(t *testing.T) {
 	task.IsCompleted = true
 
 	// Act
-	updated, err := uc.SetTaskCompletion(task)
+	updated, err := uc.SetTaskCompletion(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, updated)
@@ -367,7 +423,7 @@ func TestSetTaskCompletion_RepoError(t *testing.T) {
 // TestCreateTask_ValidationError checks that creating a task with invalid data returns a validation error.
 func TestCreateTask_ValidationError(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with too short title
 	task := &model.Task{
@@ -375,7 +431,7 @@ func TestCreateTask_ValidationError(t *testing.T) {
 	}
 
 	// Act
-	created, err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, created)
@@ -386,7 +442,7 @@ func TestCreateTask_ValidationError(t *testing.T) {
 // TestCreateTask_InvalidStatus checks that creating a task with invalid status returns a validation error.
 func TestCreateTask_InvalidStatus(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with invalid status
 	task := &model.Task{
@@ -395,7 +451,7 @@ func TestCreateTask_InvalidStatus(t *testing.T) {
 	}
 
 	// Act
-	created, err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, created)
@@ -406,7 +462,7 @@ func TestCreateTask_InvalidStatus(t *testing.T) {
 // TestCreateTask_InvalidPriority checks that creating a task with invalid priority returns a validation error.
 func TestCreateTask_InvalidPriority(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with invalid priority
 	task := &model.Task{
@@ -415,7 +471,7 @@ func TestCreateTask_InvalidPriority(t *testing.T) {
 	}
 
 	// Act
-	created, err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, created)
@@ -426,7 +482,7 @@ func TestCreateTask_InvalidPriority(t *testing.T) {
 // TestUpdateTask_ValidationError checks that updating a task with invalid data returns a validation error.
 func TestUpdateTask_ValidationError(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: create a valid task
 	task := &model.Task{
@@ -436,11 +492,11 @@ func TestUpdateTask_ValidationError(t *testing.T) {
 		Priority:  model.PriorityMedium,
 		CreatedAt: time.Now(),
 	}
-	_ = repo.Create(task)
+	_ = repo.Create(context.Background(), task)
 
 	// Act: try to update with invalid title
 	task.Title = "a"
-	updated, err := uc.UpdateTask(task)
+	updated, err := uc.UpdateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, updated)
@@ -454,7 +510,7 @@ func TestUpdateTask_FindByIDError(t *testing.T) {
 	repo.FindByIDFunc = func(id string) (*model.Task, error) {
 		return nil, errors.New("db error")
 	}
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task to update
 	task := &model.Task{
@@ -463,7 +519,7 @@ func TestUpdateTask_FindByIDError(t *testing.T) {
 	}
 
 	// Act
-	updated, err := uc.UpdateTask(task)
+	updated, err := uc.UpdateTask(context.Background(), task)
 
 	// Assert
 	assert.Nil(t, updated)
@@ -477,10 +533,10 @@ func TestDeleteTask_FindByIDError(t *testing.T) {
 	repo.FindByIDFunc = func(id string) (*model.Task, error) {
 		return nil, errors.New("db error")
 	}
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Act
-	err := uc.DeleteTask("any")
+	err := uc.DeleteTask(context.Background(), "any", domainrepository.AnyVersion)
 
 	// Assert
 	assert.Error(t, err)
@@ -493,10 +549,10 @@ func TestGetTask_FindByIDError(t *testing.T) {
 	repo.FindByIDFunc = func(id string) (*model.Task, error) {
 		return nil, errors.New("db error")
 	}
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Act
-	task, err := uc.GetTask("any")
+	task, err := uc.GetTask(context.Background(), "any")
 
 	// Assert
 	assert.Nil(t, task)
@@ -507,7 +563,7 @@ func TestGetTask_FindByIDError(t *testing.T) {
 // TestListTasksWithFilter_InvalidSortBy checks that invalid sort_by returns a validation error.
 func TestListTasksWithFilter_InvalidSortBy(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: filter with invalid sort_by
 	filter := &model.TaskFilter{
@@ -517,7 +573,7 @@ func TestListTasksWithFilter_InvalidSortBy(t *testing.T) {
 	}
 
 	// Act
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.Nil(t, tasks)
@@ -529,7 +585,7 @@ func TestListTasksWithFilter_InvalidSortBy(t *testing.T) {
 // TestListTasksWithFilter_InvalidSortOrder checks that invalid sort_order returns a validation error.
 func TestListTasksWithFilter_InvalidSortOrder(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: filter with invalid sort_order
 	filter := &model.TaskFilter{
@@ -539,7 +595,7 @@ func TestListTasksWithFilter_InvalidSortOrder(t *testing.T) {
 	}
 
 	// Act
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.Nil(t, tasks)
@@ -551,7 +607,7 @@ func TestListTasksWithFilter_InvalidSortOrder(t *testing.T) {
 // TestListTasksWithFilter_InvalidPage checks that invalid page returns a validation error.
 func TestListTasksWithFilter_InvalidPage(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: filter with invalid page
 	filter := &model.TaskFilter{
@@ -560,7 +616,7 @@ func TestListTasksWithFilter_InvalidPage(t *testing.T) {
 	}
 
 	// Act
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.Nil(t, tasks)
@@ -572,7 +628,7 @@ func TestListTasksWithFilter_InvalidPage(t *testing.T) {
 // TestListTasksWithFilter_InvalidPageSize checks that invalid page_size returns a validation error.
 func TestListTasksWithFilter_InvalidPageSize(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: filter with invalid page_size
 	filter := &model.TaskFilter{
@@ -581,7 +637,7 @@ func TestListTasksWithFilter_InvalidPageSize(t *testing.T) {
 	}
 
 	// Act
-	tasks, total, err := uc.ListTasksWithFilter(filter)
+	tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 	// Assert
 	assert.Nil(t, tasks)
@@ -593,7 +649,7 @@ func TestListTasksWithFilter_InvalidPageSize(t *testing.T) {
 // TestCreateTask_DefaultStatusAndPriority checks that default status and priority are set if not provided.
 func TestCreateTask_DefaultStatusAndPriority(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	// Arrange: task with no status and no priority
 	task := &model.Task{
@@ -601,7 +657,7 @@ func TestCreateTask_DefaultStatusAndPriority(t *testing.T) {
 	}
 
 	// Act
-	created, err := uc.CreateTask(task)
+	created, err := uc.CreateTask(context.Background(), task)
 
 	// Assert
 	assert.NoError(t, err)
@@ -613,7 +669,7 @@ func TestCreateTask_DefaultStatusAndPriority(t *testing.T) {
 // TestCreateTask_TitleEquivalencePartitioning tests various task title scenarios
 func TestCreateTask_TitleEquivalencePartitioning(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	tests := []struct {
 		name        string
@@ -656,7 +712,7 @@ func TestCreateTask_TitleEquivalencePartitioning(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			task := &model.Task{Title: tt.title}
-			created, err := uc.CreateTask(task)
+			created, err := uc.CreateTask(context.Background(), task)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -673,7 +729,7 @@ func TestCreateTask_TitleEquivalencePartitioning(t *testing.T) {
 // TestCreateTask_MacroBoundaryValues tests boundary values for date macros
 func TestCreateTask_MacroBoundaryValues(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	now := time.Now()
 	tests := []struct {
@@ -711,7 +767,7 @@ func TestCreateTask_MacroBoundaryValues(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			task := &model.Task{Title: tt.title}
-			created, err := uc.CreateTask(task)
+			created, err := uc.CreateTask(context.Background(), task)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -728,7 +784,7 @@ func TestCreateTask_MacroBoundaryValues(t *testing.T) {
 // TestListTasksWithFilter_PaginationBoundaryValues tests boundary values for pagination
 func TestListTasksWithFilter_PaginationBoundaryValues(t *testing.T) {
 	repo := newMockTaskRepo()
-	uc := NewTaskUsecase(repo)
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
 
 	for i := 1; i <= 15; i++ {
 		task := &model.Task{
@@ -738,7 +794,7 @@ func TestListTasksWithFilter_PaginationBoundaryValues(t *testing.T) {
 			Priority:  model.PriorityMedium,
 			CreatedAt: time.Now().Add(time.Duration(i) * time.Minute),
 		}
-		_ = repo.Create(task)
+		_ = repo.Create(context.Background(), task)
 	}
 
 	tests := []struct {
@@ -808,7 +864,7 @@ func TestListTasksWithFilter_PaginationBoundaryValues(t *testing.T) {
 				Page:     tt.page,
 				PageSize: tt.pageSize,
 			}
-			tasks, total, err := uc.ListTasksWithFilter(filter)
+			tasks, total, err := uc.ListTasksWithFilter(context.Background(), filter)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -824,3 +880,199 @@ func TestListTasksWithFilter_PaginationBoundaryValues(t *testing.T) {
 		})
 	}
 }
+
+// TestListTasksWithFilter_NormalizesSortOrder checks that sort_order is lower-cased before it reaches the repository.
+func TestListTasksWithFilter_NormalizesSortOrder(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange: filter with upper-case sort order
+	filter := &model.TaskFilter{
+		Page:      1,
+		PageSize:  10,
+		SortBy:    "created_at",
+		SortOrder: "DESC",
+	}
+
+	// Act
+	_, _, err := uc.ListTasksWithFilter(context.Background(), filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "desc", filter.SortOrder)
+}
+
+// TestListTasksByCursor_WalksAllPages checks that following next_cursor visits every task exactly once.
+func TestListTasksByCursor_WalksAllPages(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange: 5 tasks, two of them sharing created_at to exercise the id tie-breaker
+	now := time.Now()
+	for i := 1; i <= 5; i++ {
+		_ = repo.Create(context.Background(), &model.Task{
+			ID:        fmt.Sprintf("task-%d", i),
+			Title:     "Task",
+			CreatedAt: now.Add(time.Duration(i/2) * time.Minute),
+		})
+	}
+
+	// Act: walk pages of 2
+	seen := make([]string, 0)
+	cursor := ""
+	pages := 0
+	for {
+		tasks, next, err := uc.ListTasksByCursor(context.Background(), &model.TaskFilter{PageSize: 2}, cursor)
+		assert.NoError(t, err)
+		for _, task := range tasks {
+			seen = append(seen, task.ID)
+		}
+		pages++
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	// Assert
+	assert.Equal(t, 3, pages)
+	assert.ElementsMatch(t, []string{"task-1", "task-2", "task-3", "task-4", "task-5"}, seen)
+	assert.Equal(t, "task-5", seen[0])
+}
+
+// TestListTasksByCursor_InvalidCursor checks that a malformed cursor returns a validation error.
+func TestListTasksByCursor_InvalidCursor(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Act
+	tasks, next, err := uc.ListTasksByCursor(context.Background(), &model.TaskFilter{PageSize: 10}, "not-a-cursor")
+
+	// Assert
+	assert.Nil(t, tasks)
+	assert.Empty(t, next)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cursor")
+}
+
+// TestListTasksByCursor_SortMismatch checks that a cursor issued for one ordering is rejected for another.
+func TestListTasksByCursor_SortMismatch(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange: a cursor issued for the default ordering
+	cursor := encodeTaskCursor(&model.TaskFilter{}, &model.Task{ID: "1", CreatedAt: time.Now()})
+
+	// Act: replay it with a different sort
+	_, _, err := uc.ListTasksByCursor(context.Background(), &model.TaskFilter{PageSize: 10, SortBy: "priority"}, cursor)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cursor does not match sort parameters")
+}
+
+// TestListTasksByCursor_UnknownPriority checks that a priority cursor must carry a known priority.
+func TestListTasksByCursor_UnknownPriority(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+	filter := &model.TaskFilter{PageSize: 10, SortBy: "priority"}
+
+	// Arrange: a well-formed cursor naming a priority that does not exist
+	cursor := encodeTaskCursor(filter, &model.Task{ID: "1", Priority: "URGENT"})
+
+	// Act
+	_, _, err := uc.ListTasksByCursor(context.Background(), filter, cursor)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid cursor")
+}
+
+// TestListTasksByCursor_InvalidPageSize checks that page_size is validated in cursor mode.
+func TestListTasksByCursor_InvalidPageSize(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Act
+	_, _, err := uc.ListTasksByCursor(context.Background(), &model.TaskFilter{PageSize: 0}, "")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "page_size must be greater than 0")
+}
+
+// TestDecodeTaskCursor_RoundTrip checks that the deadline sort key, including a missing deadline, survives encoding.
+func TestDecodeTaskCursor_RoundTrip(t *testing.T) {
+	deadline := time.Date(2100, 1, 1, 12, 0, 0, 0, time.UTC)
+	filter := &model.TaskFilter{SortBy: "deadline", SortOrder: "asc"}
+
+	withDeadline, err := decodeTaskCursor(filter, encodeTaskCursor(filter, &model.Task{ID: "a", Deadline: &deadline}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a", withDeadline.ID)
+	assert.True(t, deadline.Equal(*withDeadline.Deadline))
+
+	withoutDeadline, err := decodeTaskCursor(filter, encodeTaskCursor(filter, &model.Task{ID: "b"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "b", withoutDeadline.ID)
+	assert.Nil(t, withoutDeadline.Deadline)
+}
+
+// TestUpdateOverdueTasks_StopsOnCanceledContext checks that the overdue job stops once its context is cancelled.
+func TestUpdateOverdueTasks_StopsOnCanceledContext(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange: an active task whose deadline has passed
+	past := time.Now().Add(-time.Hour)
+	task := &model.Task{
+		ID:       "overdue",
+		Title:    "Overdue task",
+		Deadline: &past,
+		Status:   model.StatusActive,
+		Priority: model.PriorityMedium,
+	}
+	_ = repo.Create(context.Background(), task)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	transitioned, err := uc.UpdateOverdueTasks(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, transitioned)
+	stored, _ := repo.FindByID(context.Background(), "overdue")
+	assert.Equal(t, model.StatusActive, stored.Status)
+}
+
+// TestUpdateOverdueTasks_MarksOverdue checks that active tasks past their deadline become OVERDUE.
+func TestUpdateOverdueTasks_MarksOverdue(t *testing.T) {
+	repo := newMockTaskRepo()
+	uc := NewTaskUsecase(repo, repo.events, repo, testSettings, discardLogger)
+
+	// Arrange
+	past := time.Now().Add(-time.Hour)
+	_ = repo.Create(context.Background(), &model.Task{
+		ID:       "overdue",
+		Title:    "Overdue task",
+		Deadline: &past,
+		Status:   model.StatusActive,
+		Priority: model.PriorityMedium,
+	})
+	_ = repo.Create(context.Background(), &model.Task{
+		ID:       "done",
+		Title:    "Completed task",
+		Deadline: &past,
+		Status:   model.StatusCompleted,
+		Priority: model.PriorityMedium,
+	})
+
+	// Act
+	transitioned, err := uc.UpdateOverdueTasks(context.Background())
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, transitioned)
+	stored, _ := repo.FindByID(context.Background(), "overdue")
+	assert.Equal(t, model.StatusOverdue, stored.Status)
+}
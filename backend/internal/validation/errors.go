@@ -1,7 +1,51 @@
 package validation
 
+import (
+	"strings"
+	"todo/internal/i18n"
+)
+
+// Codes reported in FieldError.Code. Where a rule also exists as a binding
+// tag the code matches the tag, so clients see one code whichever layer
+// rejected the value.
+const (
+	CodeRequired  = "required"
+	CodeMin       = "min"
+	CodeMax       = "max"
+	CodeOneOf     = "oneof"
+	CodeInPast    = "in_past"
+	CodeInvalid   = "invalid"
+	CodeNotNull   = "not_null"
+	CodeUnknown   = "unknown"
+	CodeReadOnly  = "read_only"
+	CodeDuplicate = "duplicate"
+)
+
+// FieldError describes one rejected field. Field is the name used in the
+// request (JSON key or query parameter) and Code is stable and
+// machine-readable. MessageID and Args select the human-readable text in the
+// i18n catalogs; Message is that text in the default locale.
+type FieldError struct {
+	Field     string
+	Code      string
+	MessageID string
+	Args      []any
+	Message   string
+}
+
+// Localize renders the field's message in locale.
+func (f FieldError) Localize(locale i18n.Locale) string {
+	if f.MessageID == "" {
+		return f.Message
+	}
+	return i18n.Translate(locale, f.MessageID, f.Args...)
+}
+
 type ValidationError struct {
 	Msg string
+	// Fields lists the rejected fields; it is empty when the error is not
+	// about a particular field.
+	Fields []FieldError
 }
 
 func (e *ValidationError) Error() string {
@@ -11,3 +55,34 @@ func (e *ValidationError) Error() string {
 func NewValidationError(msg string) error {
 	return &ValidationError{Msg: msg}
 }
+
+// NewFieldError returns a ValidationError about a single field whose message
+// is the catalog entry messageID.
+func NewFieldError(field, code, messageID string, args ...any) error {
+	return NewFieldsError([]FieldError{Field(field, code, messageID, args...)})
+}
+
+// NewFieldsError returns a ValidationError listing every rejected field, or nil
+// when fields is empty.
+func NewFieldsError(fields []FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	messages := make([]string, len(fields))
+	for i, field := range fields {
+		messages[i] = field.Message
+	}
+	return &ValidationError{Msg: strings.Join(messages, "; "), Fields: fields}
+}
+
+// Field builds one FieldError whose message is the catalog entry messageID,
+// for callers that collect several before calling NewFieldsError.
+func Field(field, code, messageID string, args ...any) FieldError {
+	return FieldError{
+		Field:     field,
+		Code:      code,
+		MessageID: messageID,
+		Args:      args,
+		Message:   i18n.Translate(i18n.Default, messageID, args...),
+	}
+}
@@ -4,8 +4,13 @@ import (
 	"strings"
 	"time"
 	"todo/internal/domain/model"
+	"todo/internal/i18n"
+
+	"github.com/google/uuid"
 )
 
+const minTitleLength = 4
+
 func isValidStatus(status model.TaskStatus) bool {
 	switch status {
 	case model.StatusActive, model.StatusCompleted, model.StatusOverdue, model.StatusLate:
@@ -15,33 +20,37 @@ func isValidStatus(status model.TaskStatus) bool {
 	}
 }
 
-func isValidPriority(priority model.TaskPriority) bool {
-	switch priority {
-	case model.PriorityLow, model.PriorityMedium, model.PriorityHigh, model.PriorityCritical:
-		return true
-	default:
-		return false
+// ValidateTaskID accepts the IDs a client may choose for a new task: UUID
+// version 4 or 7 in the lowercase hyphenated form the server itself produces,
+// so that one task cannot be reached under two spellings.
+func ValidateTaskID(id string) error {
+	parsed, err := uuid.Parse(id)
+	if err != nil || parsed.String() != id || (parsed.Version() != 4 && parsed.Version() != 7) || parsed.Variant() != uuid.RFC4122 {
+		return NewFieldsError([]FieldError{Field("id", CodeInvalid, i18n.FieldUUID, "id")})
 	}
+	return nil
 }
 
+// ValidateTask reports every invalid field of t at once.
 func ValidateTask(t *model.Task) error {
-	if len(strings.TrimSpace(t.Title)) < 4 {
-		return NewValidationError("title must be at least 4 characters")
+	var fields []FieldError
+	if len(strings.TrimSpace(t.Title)) < minTitleLength {
+		fields = append(fields, Field("title", CodeMin, i18n.TitleTooShort, minTitleLength))
 	}
 
 	if t.Deadline != nil {
 		if t.Deadline.Before(time.Now()) {
-			return NewValidationError("deadline cannot be in the past")
+			fields = append(fields, Field("deadline", CodeInPast, i18n.DeadlineInPast))
 		}
 	}
 
 	if !isValidStatus(t.Status) {
-		return NewValidationError("invalid task status")
+		fields = append(fields, Field("status", CodeOneOf, i18n.StatusInvalid))
 	}
 
-	if !isValidPriority(t.Priority) {
-		return NewValidationError("invalid task priority")
+	if !t.Priority.IsValid() {
+		fields = append(fields, Field("priority", CodeOneOf, i18n.PriorityInvalid))
 	}
 
-	return nil
+	return NewFieldsError(fields)
 }
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 	"todo/internal/domain/model"
+	"todo/internal/i18n"
 )
 
 // TestValidateTask_TitleTooShort checks that validation fails when task title
@@ -99,3 +100,65 @@ func TestValidateTask_ValidTask(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 }
+
+// TestValidateTask_ReportsEveryField checks that all invalid fields are listed
+// with their codes, not just the first one
+func TestValidateTask_ReportsEveryField(t *testing.T) {
+	// Arrange
+	past := time.Now().Add(-time.Hour)
+	task := &model.Task{
+		Title:    "abc",
+		Deadline: &past,
+		Status:   model.StatusActive,
+		Priority: "URGENT",
+	}
+
+	// Act
+	err := ValidateTask(task)
+
+	// Assert
+	var vErr *ValidationError
+	assert.ErrorAs(t, err, &vErr)
+	var fields, codes, messages []string
+	for _, field := range vErr.Fields {
+		fields = append(fields, field.Field)
+		codes = append(codes, field.Code)
+		messages = append(messages, field.Localize(i18n.Russian))
+	}
+	assert.Equal(t, []string{"title", "deadline", "priority"}, fields)
+	assert.Equal(t, []string{CodeMin, CodeInPast, CodeOneOf}, codes)
+	assert.Equal(t, []string{
+		"название должно содержать не менее 4 символов",
+		"срок не может быть в прошлом",
+		"недопустимый приоритет задачи",
+	}, messages)
+	assert.Equal(t, "title must be at least 4 characters; deadline cannot be in the past; invalid task priority", err.Error())
+}
+
+// TestValidateTaskID checks that only lowercase hyphenated UUID v4 and v7 are accepted
+func TestValidateTaskID(t *testing.T) {
+	tests := []struct {
+		id    string
+		valid bool
+	}{
+		{"6f1c2a9e-3b4d-4e5f-8a6b-7c8d9e0f1a2b", true},
+		{"0190b7e4-5c1a-7d3e-9f2a-1b2c3d4e5f60", true},
+		{"6F1C2A9E-3B4D-4E5F-8A6B-7C8D9E0F1A2B", false},
+		{"6f1c2a9e3b4d4e5f8a6b7c8d9e0f1a2b", false},
+		{"urn:uuid:6f1c2a9e-3b4d-4e5f-8a6b-7c8d9e0f1a2b", false},
+		{"6f1c2a9e-3b4d-11ef-8a6b-7c8d9e0f1a2b", false},
+		{"6f1c2a9e-3b4d-4e5f-ca6b-7c8d9e0f1a2b", false},
+		{"task-1", false},
+	}
+	for _, tt := range tests {
+		// Act
+		err := ValidateTaskID(tt.id)
+
+		// Assert
+		if tt.valid {
+			assert.NoError(t, err, tt.id)
+		} else {
+			assert.ErrorContains(t, err, "id must be a UUID v4 or v7", tt.id)
+		}
+	}
+}
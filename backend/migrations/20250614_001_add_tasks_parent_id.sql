@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN parent_id VARCHAR REFERENCES tasks (id) ON DELETE SET NULL;
+CREATE INDEX idx_tasks_parent_id ON tasks (parent_id) WHERE parent_id IS NOT NULL;
+
+-- +goose Down
+DROP INDEX idx_tasks_parent_id;
+ALTER TABLE tasks DROP COLUMN parent_id;
@@ -0,0 +1,9 @@
+// Package migrations embeds the goose SQL migrations so the server binary can
+// apply them without the goose CLI. PostgreSQL migrations live at the root,
+// SQLite ones under sqlite/.
+package migrations
+
+import "embed"
+
+//go:embed *.sql sqlite/*.sql
+var FS embed.FS
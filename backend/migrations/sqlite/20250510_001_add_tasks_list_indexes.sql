@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE INDEX idx_tasks_status_priority ON tasks (status, priority);
+CREATE INDEX idx_tasks_created_at ON tasks (created_at DESC, id DESC);
+CREATE INDEX idx_tasks_deadline ON tasks (deadline, id);
+CREATE INDEX idx_tasks_priority_rank ON tasks ((CASE priority
+    WHEN 'CRITICAL' THEN 4
+    WHEN 'HIGH' THEN 3
+    WHEN 'MEDIUM' THEN 2
+    WHEN 'LOW' THEN 1
+    ELSE 0 END), id);
+
+-- +goose Down
+DROP INDEX idx_tasks_priority_rank;
+DROP INDEX idx_tasks_deadline;
+DROP INDEX idx_tasks_created_at;
+DROP INDEX idx_tasks_status_priority;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE idempotency_keys
+(
+    key             VARCHAR PRIMARY KEY,
+    fingerprint     VARCHAR   NOT NULL,
+    status_code     INTEGER   NOT NULL,
+    response_header TEXT,
+    response_body   BLOB,
+    created_at      TIMESTAMP NOT NULL,
+    expires_at      TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+-- +goose Down
+DROP TABLE idempotency_keys;
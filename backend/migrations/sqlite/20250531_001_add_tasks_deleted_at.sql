@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMP;
+CREATE INDEX idx_tasks_deleted_at ON tasks (deleted_at DESC, id DESC) WHERE deleted_at IS NOT NULL;
+
+-- Lists only ever read live tasks, so the list indexes leave the trash out.
+DROP INDEX idx_tasks_priority_rank;
+DROP INDEX idx_tasks_deadline;
+DROP INDEX idx_tasks_created_at;
+DROP INDEX idx_tasks_status_priority;
+CREATE INDEX idx_tasks_status_priority ON tasks (status, priority) WHERE deleted_at IS NULL;
+CREATE INDEX idx_tasks_created_at ON tasks (created_at DESC, id DESC) WHERE deleted_at IS NULL;
+CREATE INDEX idx_tasks_deadline ON tasks (deadline, id) WHERE deleted_at IS NULL;
+CREATE INDEX idx_tasks_priority_rank ON tasks ((CASE priority
+    WHEN 'CRITICAL' THEN 4
+    WHEN 'HIGH' THEN 3
+    WHEN 'MEDIUM' THEN 2
+    WHEN 'LOW' THEN 1
+    ELSE 0 END), id) WHERE deleted_at IS NULL;
+
+-- +goose Down
+DROP INDEX idx_tasks_priority_rank;
+DROP INDEX idx_tasks_deadline;
+DROP INDEX idx_tasks_created_at;
+DROP INDEX idx_tasks_status_priority;
+CREATE INDEX idx_tasks_status_priority ON tasks (status, priority);
+CREATE INDEX idx_tasks_created_at ON tasks (created_at DESC, id DESC);
+CREATE INDEX idx_tasks_deadline ON tasks (deadline, id);
+CREATE INDEX idx_tasks_priority_rank ON tasks ((CASE priority
+    WHEN 'CRITICAL' THEN 4
+    WHEN 'HIGH' THEN 3
+    WHEN 'MEDIUM' THEN 2
+    WHEN 'LOW' THEN 1
+    ELSE 0 END), id);
+
+DROP INDEX idx_tasks_deleted_at;
+ALTER TABLE tasks DROP COLUMN deleted_at;
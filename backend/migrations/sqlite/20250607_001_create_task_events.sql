@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE task_events
+(
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    task_id    VARCHAR   NOT NULL,
+    type       VARCHAR   NOT NULL,
+    origin     VARCHAR   NOT NULL,
+    version    BIGINT    NOT NULL,
+    changes    TEXT      NOT NULL,
+    request_id VARCHAR,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_task_events_task_id ON task_events (task_id, id);
+
+-- +goose Down
+DROP TABLE task_events;